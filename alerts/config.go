@@ -0,0 +1,165 @@
+// Package alerts evaluates user defined rules against the live markets and
+// delivers the alerts they raise to log, file and webhook sinks.
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Rule kinds.
+const (
+	// KindPriceCross fires when the price crosses Level, in Direction up,
+	// down or either when empty.
+	KindPriceCross = "price_cross"
+	// KindSpread fires when the spread is above Threshold bps of the mid.
+	KindSpread = "spread"
+	// KindImbalance fires when the book imbalance over Levels levels is
+	// above Threshold or below -Threshold, see orderbook.Book.Imbalance.
+	KindImbalance = "imbalance"
+	// KindVolumeSpike fires when the volume of the running kline is above
+	// Threshold times the average volume of the Periods klines before it.
+	KindVolumeSpike = "volume_spike"
+	// KindStale fires when Feed has not sent a message for StaleAfter or is
+	// not connected.
+	KindStale = "stale"
+)
+
+// Duration is a time.Duration written like 30s or 5m in JSON.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Rule is one alert rule. Symbol empty applies it to every market. An
+// alert fires when the condition becomes true and not again until it was
+// false once and Cooldown has passed since it fired. Sinks lists the names
+// of the sinks it goes to, all of them when empty.
+type Rule struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	Symbol     string   `json:"symbol"`
+	Level      float64  `json:"level"`
+	Direction  string   `json:"direction"`
+	Threshold  float64  `json:"threshold"`
+	Levels     int      `json:"levels"`
+	Periods    int      `json:"periods"`
+	Feed       string   `json:"feed"`
+	StaleAfter Duration `json:"staleAfter"`
+	Cooldown   Duration `json:"cooldown"`
+	Sinks      []string `json:"sinks"`
+}
+
+// SinkConfig sets up a sink. Type is log, file or webhook; file sinks
+// append JSON lines to Path, webhook sinks POST the alert as JSON to URL.
+type SinkConfig struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Path    string   `json:"path"`
+	URL     string   `json:"url"`
+	Timeout Duration `json:"timeout"`
+}
+
+// Config is the alerts file: how often the rules are evaluated, the sinks
+// and the rules.
+type Config struct {
+	Interval Duration     `json:"interval"`
+	Sinks    []SinkConfig `json:"sinks"`
+	Rules    []Rule       `json:"rules"`
+}
+
+// Load reads and checks the alerts file at path. Interval defaults to 1s
+// and a file without sinks logs the alerts.
+func Load(path string) (Config, error) {
+	var cfg Config
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = Duration(time.Second)
+	}
+	if len(cfg.Sinks) == 0 {
+		cfg.Sinks = []SinkConfig{{Name: "log", Type: "log"}}
+	}
+	return cfg, cfg.check()
+}
+
+func (c Config) check() error {
+	sinks := make(map[string]bool)
+	for _, s := range c.Sinks {
+		if s.Name == "" || sinks[s.Name] {
+			return fmt.Errorf("sink names must be set and unique, got %q", s.Name)
+		}
+		sinks[s.Name] = true
+		switch {
+		case s.Type == "log":
+		case s.Type == "file" && s.Path != "":
+		case s.Type == "webhook" && s.URL != "":
+		default:
+			return fmt.Errorf("sink %s: type %q without its path or url", s.Name, s.Type)
+		}
+	}
+	names := make(map[string]bool)
+	for _, r := range c.Rules {
+		if r.Name == "" || names[r.Name] {
+			return fmt.Errorf("rule names must be set and unique, got %q", r.Name)
+		}
+		names[r.Name] = true
+		if err := r.check(); err != nil {
+			return fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+		for _, s := range r.Sinks {
+			if !sinks[s] {
+				return fmt.Errorf("rule %s: unknown sink %q", r.Name, s)
+			}
+		}
+	}
+	return nil
+}
+
+func (r Rule) check() error {
+	switch r.Kind {
+	case KindPriceCross:
+		if r.Level <= 0 {
+			return fmt.Errorf("level must be positive")
+		}
+		if r.Direction != "" && r.Direction != "up" && r.Direction != "down" {
+			return fmt.Errorf("direction must be up, down or empty")
+		}
+	case KindSpread, KindImbalance:
+		if r.Threshold <= 0 {
+			return fmt.Errorf("threshold must be positive")
+		}
+	case KindVolumeSpike:
+		if r.Threshold <= 0 || r.Periods <= 0 {
+			return fmt.Errorf("threshold and periods must be positive")
+		}
+	case KindStale:
+		if r.Feed == "" || r.StaleAfter <= 0 {
+			return fmt.Errorf("feed and staleAfter must be set")
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", r.Kind)
+	}
+	return nil
+}
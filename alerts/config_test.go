@@ -0,0 +1,66 @@
+package alerts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigCheck(t *testing.T) {
+	fileSink := SinkConfig{Name: "file", Type: "file", Path: "alerts.jsonl"}
+	cross := Rule{Name: "cross", Kind: KindPriceCross, Level: 100}
+	tests := []struct {
+		name  string
+		sinks []SinkConfig
+		rules []Rule
+		ok    bool
+	}{
+		{name: "valid", sinks: []SinkConfig{fileSink}, rules: []Rule{cross, {Name: "stale", Kind: KindStale, Feed: "trades", StaleAfter: Duration(time.Second), Sinks: []string{"file"}}}, ok: true},
+		{name: "sink without a name", sinks: []SinkConfig{{Type: "log"}}},
+		{name: "duplicate sink", sinks: []SinkConfig{fileSink, fileSink}},
+		{name: "file sink without a path", sinks: []SinkConfig{{Name: "file", Type: "file"}}},
+		{name: "webhook sink without a url", sinks: []SinkConfig{{Name: "hook", Type: "webhook"}}},
+		{name: "unknown sink type", sinks: []SinkConfig{{Name: "mail", Type: "mail"}}},
+		{name: "duplicate rule", rules: []Rule{cross, cross}},
+		{name: "unknown kind", rules: []Rule{{Name: "r", Kind: "moon"}}},
+		{name: "price cross without a level", rules: []Rule{{Name: "r", Kind: KindPriceCross}}},
+		{name: "price cross direction", rules: []Rule{{Name: "r", Kind: KindPriceCross, Level: 1, Direction: "sideways"}}},
+		{name: "spread without a threshold", rules: []Rule{{Name: "r", Kind: KindSpread}}},
+		{name: "volume spike without periods", rules: []Rule{{Name: "r", Kind: KindVolumeSpike, Threshold: 2}}},
+		{name: "stale without staleAfter", rules: []Rule{{Name: "r", Kind: KindStale, Feed: "trades"}}},
+		{name: "unknown sink of a rule", sinks: []SinkConfig{fileSink}, rules: []Rule{{Name: "r", Kind: KindPriceCross, Level: 1, Sinks: []string{"hook"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Config{Sinks: tt.sinks, Rules: tt.rules}.check()
+			if (err == nil) != tt.ok {
+				t.Errorf("check: %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	if err := os.WriteFile(path, []byte(`{"rules":[{"name":"stale","kind":"stale","feed":"book","staleAfter":"30s","cooldown":"5m"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Interval != Duration(time.Second) || len(cfg.Sinks) != 1 || cfg.Sinks[0].Type != "log" {
+		t.Errorf("config %+v, want a 1s interval and a log sink", cfg)
+	}
+	if r := cfg.Rules[0]; r.StaleAfter != Duration(30*time.Second) || r.Cooldown != Duration(5*time.Minute) {
+		t.Errorf("rule %+v, want durations parsed", r)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"rules":[{"name":"stale","kind":"stale","feed":"book","staleAfter":"soon"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("loaded a duration that does not parse")
+	}
+}
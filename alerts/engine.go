@@ -0,0 +1,153 @@
+package alerts
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/markets"
+	"test.bhft.com/telemetry"
+)
+
+// Alert is a rule that fired on a symbol. Time is in milliseconds, Value is
+// what the rule measured: a price, bps, an imbalance, a volume ratio or the
+// feed age in seconds.
+type Alert struct {
+	Rule    string  `json:"rule"`
+	Kind    string  `json:"kind"`
+	Symbol  string  `json:"symbol"`
+	Time    int64   `json:"time"`
+	Value   float64 `json:"value"`
+	Message string  `json:"message"`
+}
+
+// state is what a rule saw last on one symbol.
+type state struct {
+	active bool
+	fired  time.Time
+	price  float64
+	priced bool
+}
+
+// Engine evaluates the rules against the markets of a registry every
+// interval. An alert is only delivered when its condition turns true, so a
+// lasting condition fires once, and not within Cooldown of the last alert
+// of the same rule and symbol.
+type Engine struct {
+	cfg      Config
+	registry *markets.Registry
+	sinks    map[string]Sink
+	states   map[string]*state
+	queue    chan Alert
+}
+
+func NewEngine(cfg Config, registry *markets.Registry) (*Engine, error) {
+	e := &Engine{
+		cfg:      cfg,
+		registry: registry,
+		sinks:    make(map[string]Sink),
+		states:   make(map[string]*state),
+		queue:    make(chan Alert, 100),
+	}
+	for _, s := range cfg.Sinks {
+		sink, err := NewSink(s)
+		if err != nil {
+			return nil, err
+		}
+		e.sinks[s.Name] = sink
+	}
+	return e, nil
+}
+
+// Run evaluates the rules until ctx is done. Alerts are delivered from
+// their own goroutine so a slow webhook does not hold up the rules; when
+// delivery falls 100 alerts behind new ones are dropped.
+func (e *Engine) Run(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		defer close(e.queue)
+		ticker := clock.NewTicker(time.Duration(e.cfg.Interval))
+		defer ticker.Stop()
+		started := clock.Now()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.evaluate(clock.Now(), started)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for a := range e.queue {
+			e.deliver(a)
+		}
+	}()
+}
+
+func (e *Engine) evaluate(now, started time.Time) {
+	for _, r := range e.cfg.Rules {
+		for _, symbol := range e.registry.Symbols() {
+			if r.Symbol != "" && !strings.EqualFold(r.Symbol, symbol) {
+				continue
+			}
+			m := e.registry.Get(symbol)
+			key := r.Name + "." + symbol
+			st, ok := e.states[key]
+			if !ok {
+				st = &state{}
+				e.states[key] = st
+			}
+			c := evaluate(r, m, st, now, started)
+			if !c.ok {
+				continue
+			}
+			rising := c.active && !st.active
+			st.active = c.active
+			if !rising || now.Sub(st.fired) < time.Duration(r.Cooldown) {
+				continue
+			}
+			st.fired = now
+			telemetry.AlertsFired.WithLabelValues(r.Name, m.Symbol).Inc()
+			a := Alert{Rule: r.Name, Kind: r.Kind, Symbol: m.Symbol, Time: now.UnixMilli(), Value: c.value, Message: c.message}
+			select {
+			case e.queue <- a:
+			default:
+				slog.Error("alert queue is full, alert dropped", "rule", a.Rule, "symbol", a.Symbol)
+			}
+		}
+	}
+}
+
+// deliver sends a to the sinks of its rule.
+func (e *Engine) deliver(a Alert) {
+	names := e.rule(a.Rule).Sinks
+	if len(names) == 0 {
+		for _, s := range e.cfg.Sinks {
+			names = append(names, s.Name)
+		}
+	}
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := e.sinks[name].Send(ctx, a)
+		cancel()
+		if err != nil {
+			telemetry.AlertDeliveryErrors.WithLabelValues(name).Inc()
+			slog.Error("deliver alert", "sink", name, "rule", a.Rule, "symbol", a.Symbol, "err", err)
+		}
+	}
+}
+
+func (e *Engine) rule(name string) Rule {
+	for _, r := range e.cfg.Rules {
+		if r.Name == name {
+			return r
+		}
+	}
+	return Rule{}
+}
@@ -0,0 +1,123 @@
+package alerts
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/markets"
+	"test.bhft.com/telemetry"
+	"test.bhft.com/trades"
+)
+
+// fired drains the alerts queued by evaluate.
+func fired(e *Engine) []Alert {
+	var res []Alert
+	for {
+		select {
+		case a := <-e.queue:
+			res = append(res, a)
+		default:
+			return res
+		}
+	}
+}
+
+// TestEngineRisingEdgeAndCooldown follows a stale rule through a feed that
+// goes stale, stays stale, recovers and goes stale again within and after
+// the cooldown.
+func TestEngineRisingEdgeAndCooldown(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	sim := clock.NewSimClock(base)
+	clock.Set(sim)
+	telemetry.Health = telemetry.NewTracker()
+
+	registry := markets.NewRegistry()
+	registry.Add(&markets.Market{Symbol: "BTCUSDT", Venue: "binance"})
+	rule := Rule{Name: "trades", Kind: KindStale, Feed: telemetry.StreamTrades, StaleAfter: Duration(10 * time.Second), Cooldown: Duration(time.Minute)}
+	e, err := NewEngine(Config{Rules: []Rule{rule}}, registry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := func(at time.Duration) {
+		sim.Advance(base.Add(at))
+		telemetry.Health.Connected("binance", telemetry.StreamTrades, "BTCUSDT")
+		telemetry.Health.Message("binance", telemetry.StreamTrades, "BTCUSDT")
+	}
+
+	steps := []struct {
+		name    string
+		message time.Duration // a message is received at this time when set
+		at      time.Duration
+		fires   bool
+	}{
+		{name: "no message yet, within staleAfter of the start", at: 5 * time.Second},
+		{name: "no message since the start", at: 11 * time.Second, fires: true},
+		{name: "still stale", at: 12 * time.Second},
+		{name: "fresh again", message: 20 * time.Second, at: 21 * time.Second},
+		{name: "stale within the cooldown", at: 31 * time.Second},
+		{name: "fresh after the cooldown", message: 80 * time.Second, at: 81 * time.Second},
+		{name: "stale after the cooldown", at: 91 * time.Second, fires: true},
+	}
+	for _, s := range steps {
+		if s.message > 0 {
+			message(s.message)
+		}
+		e.evaluate(base.Add(s.at), base)
+		got := fired(e)
+		if (len(got) == 1) != s.fires || len(got) > 1 {
+			t.Errorf("%s: alerts %+v, want fired %v", s.name, got, s.fires)
+		}
+	}
+}
+
+func TestStaleDisconnected(t *testing.T) {
+	telemetry.Health = telemetry.NewTracker()
+	m := &markets.Market{Symbol: "BTCUSDT", Venue: "binance"}
+	rule := Rule{Kind: KindStale, Feed: telemetry.StreamTrades, StaleAfter: Duration(time.Hour)}
+	now := clock.Now()
+
+	if c := stale(rule, m, now, now); !c.ok || c.active {
+		t.Errorf("before the first message %+v, want not active", c)
+	}
+	telemetry.Health.Connected("binance", telemetry.StreamTrades, "BTCUSDT")
+	telemetry.Health.Message("binance", telemetry.StreamTrades, "BTCUSDT")
+	telemetry.Health.Disconnected("binance", telemetry.StreamTrades, "BTCUSDT")
+	if c := stale(rule, m, now, now); !c.active || c.message != "BTCUSDT trades feed is not connected" {
+		t.Errorf("disconnected %+v, want active", c)
+	}
+}
+
+func TestPriceCross(t *testing.T) {
+	tests := []struct {
+		direction string
+		prices    []float64
+		active    []bool
+	}{
+		{direction: "", prices: []float64{99, 101, 99}, active: []bool{false, true, true}},
+		{direction: "up", prices: []float64{99, 101, 99}, active: []bool{false, true, false}},
+		{direction: "down", prices: []float64{99, 101, 99}, active: []bool{false, false, true}},
+		// The first price only sets where the price stands.
+		{direction: "", prices: []float64{101, 102}, active: []bool{false, false}},
+		// Touching the level crosses it, leaving it on the same side does not.
+		{direction: "up", prices: []float64{99, 100, 99.5, 100}, active: []bool{false, true, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q %v", tt.direction, tt.prices), func(t *testing.T) {
+			m := &markets.Market{Symbol: "BTCUSDT", Trades: trades.New("BTCUSDT", nil)}
+			rule := Rule{Kind: KindPriceCross, Level: 100, Direction: tt.direction}
+			var st state
+			if c := priceCross(rule, m, &st); c.ok {
+				t.Errorf("condition %+v without a price", c)
+			}
+			for i, p := range tt.prices {
+				m.Trades.Update(trades.Trade{ID: int64(i), Price: fmt.Sprint(p)})
+				c := priceCross(rule, m, &st)
+				if !c.ok || c.active != tt.active[i] || c.value != p {
+					t.Errorf("price %v: %+v, want active %v", p, c, tt.active[i])
+				}
+			}
+		})
+	}
+}
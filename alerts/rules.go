@@ -0,0 +1,170 @@
+package alerts
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"test.bhft.com/markets"
+	"test.bhft.com/telemetry"
+)
+
+// defaultImbalanceLevels is the depth of imbalance rules without Levels.
+var defaultImbalanceLevels = 10
+
+// condition is the outcome of a rule on a market. ok is false when the
+// market has no data for the rule yet, which leaves its state unchanged.
+type condition struct {
+	active  bool
+	ok      bool
+	value   float64
+	message string
+}
+
+// evaluate checks r against m. st holds what the rule saw last on m.
+func evaluate(r Rule, m *markets.Market, st *state, now time.Time, started time.Time) condition {
+	switch r.Kind {
+	case KindPriceCross:
+		return priceCross(r, m, st)
+	case KindSpread:
+		return spread(r, m)
+	case KindImbalance:
+		return imbalance(r, m)
+	case KindVolumeSpike:
+		return volumeSpike(r, m)
+	case KindStale:
+		return stale(r, m, now, started)
+	}
+	return condition{}
+}
+
+// price is the mid of the best quote, or the last trade price without one.
+func price(m *markets.Market) (float64, bool) {
+	if q, ok := m.BestQuote(); ok {
+		bid, _, ask, _ := q.Floats()
+		if bid > 0 && ask > 0 {
+			return (bid + ask) / 2, true
+		}
+	}
+	if m.Trades == nil {
+		return 0, false
+	}
+	last := m.Trades.Page(0, 1)
+	if len(last) == 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(last[0].Price, 64)
+	return p, err == nil
+}
+
+func priceCross(r Rule, m *markets.Market, st *state) condition {
+	p, ok := price(m)
+	if !ok {
+		return condition{}
+	}
+	prev, seen := st.price, st.priced
+	st.price, st.priced = p, true
+	if !seen {
+		return condition{ok: true, value: p}
+	}
+	up := prev < r.Level && p >= r.Level
+	down := prev > r.Level && p <= r.Level
+	c := condition{ok: true, value: p}
+	switch {
+	case up && r.Direction != "down":
+		c.active = true
+		c.message = fmt.Sprintf("%s price %g crossed above %g", m.Symbol, p, r.Level)
+	case down && r.Direction != "up":
+		c.active = true
+		c.message = fmt.Sprintf("%s price %g crossed below %g", m.Symbol, p, r.Level)
+	}
+	return c
+}
+
+func spread(r Rule, m *markets.Market) condition {
+	q, ok := m.BestQuote()
+	if !ok {
+		return condition{}
+	}
+	bid, _, ask, _ := q.Floats()
+	mid := (bid + ask) / 2
+	if mid <= 0 {
+		return condition{}
+	}
+	bps := (ask - bid) / mid * 10000
+	return condition{
+		ok:      true,
+		active:  bps > r.Threshold,
+		value:   bps,
+		message: fmt.Sprintf("%s spread %.2f bps above %g bps", m.Symbol, bps, r.Threshold),
+	}
+}
+
+func imbalance(r Rule, m *markets.Market) condition {
+	if m.Book == nil || !m.Book.Synced() {
+		return condition{}
+	}
+	levels := r.Levels
+	if levels <= 0 {
+		levels = defaultImbalanceLevels
+	}
+	v := m.Book.Imbalance(levels)
+	return condition{
+		ok:      true,
+		active:  math.Abs(v) > r.Threshold,
+		value:   v,
+		message: fmt.Sprintf("%s book imbalance %.3f over %d levels beyond ±%g", m.Symbol, v, levels, r.Threshold),
+	}
+}
+
+func volumeSpike(r Rule, m *markets.Market) condition {
+	if m.Klines == nil {
+		return condition{}
+	}
+	m.Klines.Lock()
+	list := m.Klines.List
+	if len(list) < r.Periods+1 {
+		m.Klines.Unlock()
+		return condition{}
+	}
+	current, _ := strconv.ParseFloat(list[len(list)-1].Volume, 64)
+	var sum float64
+	for _, k := range list[len(list)-1-r.Periods : len(list)-1] {
+		v, _ := strconv.ParseFloat(k.Volume, 64)
+		sum += v
+	}
+	m.Klines.Unlock()
+	avg := sum / float64(r.Periods)
+	if avg <= 0 {
+		return condition{}
+	}
+	ratio := current / avg
+	return condition{
+		ok:      true,
+		active:  ratio > r.Threshold,
+		value:   ratio,
+		message: fmt.Sprintf("%s kline volume %g is %.2f times the average of the last %d", m.Symbol, current, ratio, r.Periods),
+	}
+}
+
+// stale gives a feed that never sent a message StaleAfter from started
+// before it counts as stale.
+func stale(r Rule, m *markets.Market, now, started time.Time) condition {
+	f := telemetry.Health.Feed(m.Venue, r.Feed, m.Symbol)
+	last := f.LastMessage
+	if last.IsZero() {
+		last = started
+	}
+	age := now.Sub(last)
+	c := condition{ok: true, value: age.Seconds()}
+	switch {
+	case age > time.Duration(r.StaleAfter):
+		c.active = true
+		c.message = fmt.Sprintf("%s %s feed has sent nothing for %s", m.Symbol, r.Feed, age.Round(time.Millisecond))
+	case !f.Connected && !f.LastMessage.IsZero():
+		c.active = true
+		c.message = fmt.Sprintf("%s %s feed is not connected", m.Symbol, r.Feed)
+	}
+	return c
+}
@@ -0,0 +1,97 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultWebhookTimeout bounds a webhook call without Timeout.
+var defaultWebhookTimeout = 5 * time.Second
+
+// Sink delivers alerts somewhere.
+type Sink interface {
+	Send(ctx context.Context, a Alert) error
+}
+
+// NewSink builds the sink of cfg.
+func NewSink(cfg SinkConfig) (Sink, error) {
+	switch cfg.Type {
+	case "log":
+		return LogSink{}, nil
+	case "file":
+		return &FileSink{Path: cfg.Path}, nil
+	case "webhook":
+		timeout := time.Duration(cfg.Timeout)
+		if timeout <= 0 {
+			timeout = defaultWebhookTimeout
+		}
+		return &WebhookSink{URL: cfg.URL, HTTP: &http.Client{Timeout: timeout}}, nil
+	}
+	return nil, fmt.Errorf("unknown sink type %q", cfg.Type)
+}
+
+// LogSink logs alerts at warn level.
+type LogSink struct{}
+
+func (LogSink) Send(_ context.Context, a Alert) error {
+	slog.Warn("alert", "rule", a.Rule, "kind", a.Kind, "symbol", a.Symbol, "value", a.Value, "message", a.Message)
+	return nil
+}
+
+// FileSink appends alerts to the file at Path as JSON lines.
+type FileSink struct {
+	sync.Mutex
+	Path string
+}
+
+func (s *FileSink) Send(_ context.Context, a Alert) error {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	s.Lock()
+	defer s.Unlock()
+	f, err := os.OpenFile(s.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WebhookSink POSTs alerts as JSON to URL.
+type WebhookSink struct {
+	URL  string
+	HTTP *http.Client
+}
+
+func (s *WebhookSink) Send(ctx context.Context, a Alert) error {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: status: %s", resp.Status)
+	}
+	return nil
+}
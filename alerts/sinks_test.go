@@ -0,0 +1,94 @@
+package alerts
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.jsonl")
+	sink, err := NewSink(SinkConfig{Name: "file", Type: "file", Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := []Alert{{Rule: "a", Symbol: "BTCUSDT", Value: 1}, {Rule: "b", Symbol: "ETHUSDT", Value: 2}}
+	for _, a := range sent {
+		if err := sink.Send(context.Background(), a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []Alert
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var a Alert
+		if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, a)
+	}
+	if len(got) != len(sent) || got[0] != sent[0] || got[1] != sent[1] {
+		t.Errorf("file holds %+v, want %+v", got, sent)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	status := http.StatusOK
+	var got Alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("%s with content type %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sink, err := NewSink(SinkConfig{Name: "hook", Type: "webhook", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := Alert{Rule: "cross", Kind: KindPriceCross, Symbol: "BTCUSDT", Value: 100, Message: "crossed"}
+	if err := sink.Send(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+	if got != a {
+		t.Errorf("webhook got %+v, want %+v", got, a)
+	}
+
+	status = http.StatusInternalServerError
+	if err := sink.Send(context.Background(), a); err == nil {
+		t.Error("a 500 answer was not an error")
+	}
+}
+
+func TestWebhookSinkTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	sink, err := NewSink(SinkConfig{Name: "hook", Type: "webhook", URL: srv.URL, Timeout: Duration(50 * time.Millisecond)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(context.Background(), Alert{Rule: "cross"}); err == nil {
+		t.Error("a webhook that does not answer did not time out")
+	}
+}
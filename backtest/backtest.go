@@ -0,0 +1,159 @@
+package backtest
+
+import (
+	"math"
+	"strconv"
+
+	"test.bhft.com/klines"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/trades"
+)
+
+// Strategy trades on the replayed data through the broker. OnCandle gets
+// every closed kline, OnTrade every trade when trades are loaded.
+type Strategy interface {
+	OnCandle(b *Broker, k klines.Kline)
+	OnTrade(b *Broker, t trades.Trade)
+}
+
+// BookStrategy is a Strategy that also gets the stored book snapshots.
+type BookStrategy interface {
+	Strategy
+	OnBook(b *Broker, s orderbook.Snapshot)
+}
+
+// EquityPoint is the account at the close of a kline, or at the last event
+// when trades go on after the last kline.
+type EquityPoint struct {
+	Time     int64   `json:"time"`
+	Price    float64 `json:"price"`
+	Position float64 `json:"position"`
+	Cash     float64 `json:"cash"`
+	Equity   float64 `json:"equity"`
+}
+
+// Result is the outcome of a backtest. Orders still open at the end are
+// left unfilled and the last position is valued at the last price.
+type Result struct {
+	Equity     []EquityPoint `json:"equity"`
+	Fills      []Fill        `json:"fills"`
+	RoundTrips []RoundTrip   `json:"roundTrips"`
+	Metrics    Metrics       `json:"metrics"`
+}
+
+// Run replays data through strategy against a broker set up with cfg. When
+// trades are loaded orders fill on them, otherwise on the klines.
+func Run(data *Data, strategy Strategy, cfg BrokerConfig) Result {
+	b := newBroker(cfg)
+	books, _ := strategy.(BookStrategy)
+	fillOnTrades := len(data.Trades) > 0
+	var res Result
+	for _, e := range data.events() {
+		b.now = e.time
+		switch {
+		case e.trade != nil:
+			b.onTrade(*e.trade)
+			strategy.OnTrade(b, *e.trade)
+		case e.book != nil:
+			if books != nil {
+				books.OnBook(b, *e.book)
+			}
+		case e.kline != nil:
+			if !fillOnTrades {
+				b.onCandle(*e.kline)
+			}
+			if close, err := strconv.ParseFloat(e.kline.Close, 64); err == nil {
+				b.price = close
+			}
+			strategy.OnCandle(b, *e.kline)
+			res.Equity = append(res.Equity, EquityPoint{Time: e.time, Price: b.price, Position: b.position, Cash: b.cash, Equity: b.Equity()})
+		}
+	}
+	if n := len(res.Equity); n > 0 && res.Equity[n-1].Time < b.now {
+		res.Equity = append(res.Equity, EquityPoint{Time: b.now, Price: b.price, Position: b.position, Cash: b.cash, Equity: b.Equity()})
+	}
+	res.Fills = b.fills
+	res.RoundTrips = b.trips
+	res.Metrics = computeMetrics(cfg.InitialCash, res)
+	return res
+}
+
+// Metrics summarize a backtest. Returns and drawdown are fractions, Sharpe
+// is annualized from the returns between equity points without a risk free
+// rate. ProfitFactor is the gains of the winning round trips over the
+// losses of the others, 0 without losses.
+type Metrics struct {
+	InitialEquity float64 `json:"initialEquity"`
+	FinalEquity   float64 `json:"finalEquity"`
+	TotalReturn   float64 `json:"totalReturn"`
+	Sharpe        float64 `json:"sharpe"`
+	MaxDrawdown   float64 `json:"maxDrawdown"`
+	RoundTrips    int     `json:"roundTrips"`
+	WinRate       float64 `json:"winRate"`
+	ProfitFactor  float64 `json:"profitFactor"`
+	Fills         int     `json:"fills"`
+	Fees          float64 `json:"fees"`
+}
+
+// year is the length of a year in milliseconds, markets trade every day.
+const year = 365 * 24 * 60 * 60 * 1000
+
+func computeMetrics(initial float64, res Result) Metrics {
+	m := Metrics{InitialEquity: initial, FinalEquity: initial, RoundTrips: len(res.RoundTrips), Fills: len(res.Fills)}
+	for _, f := range res.Fills {
+		m.Fees += f.Fee
+	}
+	var wins, gains, losses float64
+	for _, t := range res.RoundTrips {
+		if t.PnL > 0 {
+			wins++
+			gains += t.PnL
+		} else {
+			losses -= t.PnL
+		}
+	}
+	if m.RoundTrips > 0 {
+		m.WinRate = wins / float64(m.RoundTrips)
+	}
+	if losses > 0 {
+		m.ProfitFactor = gains / losses
+	}
+	if len(res.Equity) == 0 {
+		return m
+	}
+	m.FinalEquity = res.Equity[len(res.Equity)-1].Equity
+	if initial != 0 {
+		m.TotalReturn = m.FinalEquity/initial - 1
+	}
+
+	peak := initial
+	var returns []float64
+	prev := initial
+	for _, p := range res.Equity {
+		peak = math.Max(peak, p.Equity)
+		if peak > 0 {
+			m.MaxDrawdown = math.Max(m.MaxDrawdown, (peak-p.Equity)/peak)
+		}
+		if prev != 0 {
+			returns = append(returns, p.Equity/prev-1)
+		}
+		prev = p.Equity
+	}
+	if len(returns) < 2 || len(res.Equity) < 2 {
+		return m
+	}
+	var mean, variance float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	sd := math.Sqrt(variance / float64(len(returns)-1))
+	spacing := float64(res.Equity[len(res.Equity)-1].Time-res.Equity[0].Time) / float64(len(res.Equity)-1)
+	if sd > 0 && spacing > 0 {
+		m.Sharpe = mean / sd * math.Sqrt(year/spacing)
+	}
+	return m
+}
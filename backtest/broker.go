@@ -0,0 +1,296 @@
+package backtest
+
+import (
+	"math"
+	"strconv"
+
+	"test.bhft.com/klines"
+	"test.bhft.com/trades"
+)
+
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+
+	OrderMarket = "market"
+	OrderLimit  = "limit"
+)
+
+// BrokerConfig sets up the simulated broker. Fees are in bps of the
+// notional, slippage in bps against the taker. Participation is the share
+// of the volume of each candle or trade an order may take, 0 takes all of
+// it; what is left of an order waits for the next one.
+type BrokerConfig struct {
+	InitialCash   float64
+	MakerFeeBps   float64
+	TakerFeeBps   float64
+	SlippageBps   float64
+	Participation float64
+}
+
+// Order is a simulated order. Price is the limit price of limit orders.
+type Order struct {
+	ID     int64   `json:"id"`
+	Time   int64   `json:"time"`
+	Side   string  `json:"side"`
+	Type   string  `json:"type"`
+	Price  float64 `json:"price"`
+	Qty    float64 `json:"qty"`
+	Filled float64 `json:"filled"`
+}
+
+// Fill is a simulated execution. Fee is in quote, Maker is set on limit
+// order fills.
+type Fill struct {
+	OrderID int64   `json:"orderId"`
+	Time    int64   `json:"time"`
+	Side    string  `json:"side"`
+	Price   float64 `json:"price"`
+	Qty     float64 `json:"qty"`
+	Fee     float64 `json:"fee"`
+	Maker   bool    `json:"maker"`
+}
+
+// RoundTrip is a long or short position from when it opened until it was
+// flat again or flipped. Qty is its largest size, the prices are averages
+// and PnL is net of the fees of its fills.
+type RoundTrip struct {
+	Side       string  `json:"side"`
+	EntryTime  int64   `json:"entryTime"`
+	ExitTime   int64   `json:"exitTime"`
+	Qty        float64 `json:"qty"`
+	EntryPrice float64 `json:"entryPrice"`
+	ExitPrice  float64 `json:"exitPrice"`
+	PnL        float64 `json:"pnl"`
+}
+
+// Broker is the simulated account a strategy trades with. Orders placed
+// while handling an event only fill on the events after it, so a strategy
+// cannot trade on the candle it just saw. Positions may go short.
+type Broker struct {
+	cfg      BrokerConfig
+	now      int64
+	price    float64
+	cash     float64
+	position float64
+	nextID   int64
+	orders   []*Order
+	fills    []Fill
+	trips    []RoundTrip
+	trip     *RoundTrip
+	// tripCost and tripClosed are the notional and quantity that closed
+	// the trip so far, to average its exit.
+	tripCost   float64
+	tripClosed float64
+	tripFees   float64
+	tripPnL    float64
+}
+
+func newBroker(cfg BrokerConfig) *Broker {
+	return &Broker{cfg: cfg, cash: cfg.InitialCash}
+}
+
+// Now is the time of the event being handled, in milliseconds.
+func (b *Broker) Now() int64 { return b.now }
+
+// Price is the last known price: the last trade or kline close.
+func (b *Broker) Price() float64 { return b.price }
+
+func (b *Broker) Cash() float64     { return b.cash }
+func (b *Broker) Position() float64 { return b.position }
+
+// Equity is the cash plus the position at Price.
+func (b *Broker) Equity() float64 { return b.cash + b.position*b.price }
+
+// Orders returns the open orders.
+func (b *Broker) Orders() []Order {
+	res := make([]Order, len(b.orders))
+	for i, o := range b.orders {
+		res[i] = *o
+	}
+	return res
+}
+
+func (b *Broker) place(side, typ string, qty, price float64) int64 {
+	if qty <= 0 || (side != SideBuy && side != SideSell) {
+		return 0
+	}
+	b.nextID++
+	b.orders = append(b.orders, &Order{ID: b.nextID, Time: b.now, Side: side, Type: typ, Price: price, Qty: qty})
+	return b.nextID
+}
+
+// Market places a market order of qty and returns its ID, 0 when qty is
+// not positive.
+func (b *Broker) Market(side string, qty float64) int64 {
+	return b.place(side, OrderMarket, qty, 0)
+}
+
+// Limit places a limit order of qty at price and returns its ID.
+func (b *Broker) Limit(side string, qty, price float64) int64 {
+	if price <= 0 {
+		return 0
+	}
+	return b.place(side, OrderLimit, qty, price)
+}
+
+// Cancel cancels the rest of an open order and reports whether it was
+// open.
+func (b *Broker) Cancel(id int64) bool {
+	for i, o := range b.orders {
+		if o.ID == id {
+			b.orders = append(b.orders[:i], b.orders[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// CancelAll cancels every open order.
+func (b *Broker) CancelAll() {
+	b.orders = nil
+}
+
+// available is what the orders may take of volume.
+func (b *Broker) available(volume float64) float64 {
+	if b.cfg.Participation <= 0 {
+		return math.Inf(1)
+	}
+	return volume * b.cfg.Participation
+}
+
+// match fills the open orders, oldest first, up to the share of volume
+// they may take. fillPrice returns the price an order fills at, or false
+// when it does not fill on this event.
+func (b *Broker) match(volume float64, fillPrice func(o *Order) (float64, bool)) {
+	avail := b.available(volume)
+	open := b.orders[:0]
+	for _, o := range b.orders {
+		price, ok := fillPrice(o)
+		if ok && avail > 0 {
+			qty := math.Min(o.Qty-o.Filled, avail)
+			avail -= qty
+			b.fill(o, price, qty)
+		}
+		if o.Filled < o.Qty {
+			open = append(open, o)
+		}
+	}
+	b.orders = open
+}
+
+// slipped is price moved against the taker by the slippage.
+func (b *Broker) slipped(side string, price float64) float64 {
+	if side == SideBuy {
+		return price * (1 + b.cfg.SlippageBps/10000)
+	}
+	return price * (1 - b.cfg.SlippageBps/10000)
+}
+
+// onTrade fills the orders against a trade. Market orders take its price
+// with slippage. Limit orders rest in the book, they fill at their price
+// when the trade went through it or traded at it against their side.
+func (b *Broker) onTrade(t trades.Trade) {
+	price, err := strconv.ParseFloat(t.Price, 64)
+	if err != nil {
+		return
+	}
+	qty, _ := strconv.ParseFloat(t.Quantity, 64)
+	b.now = t.Time
+	b.match(qty, func(o *Order) (float64, bool) {
+		switch {
+		case o.Type == OrderMarket:
+			return b.slipped(o.Side, price), true
+		case o.Side == SideBuy && (price < o.Price || (price == o.Price && t.IsBuyerMaker)):
+			return o.Price, true
+		case o.Side == SideSell && (price > o.Price || (price == o.Price && !t.IsBuyerMaker)):
+			return o.Price, true
+		}
+		return 0, false
+	})
+	b.price = price
+}
+
+// onCandle fills the orders against a closed kline, used when no trades
+// are loaded. Market orders take its open with slippage. Limit orders fill
+// when its range reached their price, at the open when it gapped through.
+func (b *Broker) onCandle(k klines.Kline) {
+	open, _ := strconv.ParseFloat(k.Open, 64)
+	high, _ := strconv.ParseFloat(k.High, 64)
+	low, _ := strconv.ParseFloat(k.Low, 64)
+	volume, _ := strconv.ParseFloat(k.Volume, 64)
+	b.match(volume, func(o *Order) (float64, bool) {
+		switch {
+		case o.Type == OrderMarket:
+			return b.slipped(o.Side, open), true
+		case o.Side == SideBuy && low <= o.Price:
+			return math.Min(open, o.Price), true
+		case o.Side == SideSell && high >= o.Price:
+			return math.Max(open, o.Price), true
+		}
+		return 0, false
+	})
+}
+
+func (b *Broker) fill(o *Order, price, qty float64) {
+	maker := o.Type == OrderLimit
+	feeBps := b.cfg.TakerFeeBps
+	if maker {
+		feeBps = b.cfg.MakerFeeBps
+	}
+	fee := price * qty * feeBps / 10000
+	o.Filled += qty
+	b.fills = append(b.fills, Fill{OrderID: o.ID, Time: b.now, Side: o.Side, Price: price, Qty: qty, Fee: fee, Maker: maker})
+
+	signed := qty
+	if o.Side == SideSell {
+		signed = -qty
+	}
+	b.cash -= signed*price + fee
+	b.apply(signed, price, fee)
+}
+
+// apply moves the position by a fill of signed qty at price and fee and
+// follows its round trips. A fill that flips the position closes the trip
+// and opens the next one with the rest, the fee is split between them by
+// quantity.
+func (b *Broker) apply(signed, price, fee float64) {
+	if b.position == 0 || (b.position > 0) == (signed > 0) {
+		b.tripFees += fee
+		if b.trip == nil {
+			side := "long"
+			if signed < 0 {
+				side = "short"
+			}
+			b.trip = &RoundTrip{Side: side, EntryTime: b.now}
+		}
+		size := math.Abs(b.position) + math.Abs(signed)
+		b.trip.EntryPrice = (b.trip.EntryPrice*math.Abs(b.position) + price*math.Abs(signed)) / size
+		b.trip.Qty = math.Max(b.trip.Qty, size)
+		b.position += signed
+		return
+	}
+	closing := math.Min(math.Abs(signed), math.Abs(b.position))
+	closingFee := fee * closing / math.Abs(signed)
+	b.tripFees += closingFee
+	dir := 1.0
+	if b.position < 0 {
+		dir = -1
+	}
+	b.tripPnL += (price - b.trip.EntryPrice) * closing * dir
+	b.tripCost += price * closing
+	b.tripClosed += closing
+	b.position -= closing * dir
+	if math.Abs(b.position) > 1e-12 {
+		return
+	}
+	b.position = 0
+	b.trip.ExitTime = b.now
+	b.trip.ExitPrice = b.tripCost / b.tripClosed
+	b.trip.PnL = b.tripPnL - b.tripFees
+	b.trips = append(b.trips, *b.trip)
+	b.trip, b.tripCost, b.tripClosed, b.tripFees, b.tripPnL = nil, 0, 0, 0, 0
+	if rest := math.Abs(signed) - closing; rest > 1e-12 {
+		b.apply(math.Copysign(rest, signed), price, fee-closingFee)
+	}
+}
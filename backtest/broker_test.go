@@ -0,0 +1,115 @@
+package backtest
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"test.bhft.com/trades"
+)
+
+func trade(time int64, price float64, isBuyerMaker bool) trades.Trade {
+	return trades.Trade{Price: strconv.FormatFloat(price, 'f', -1, 64), Quantity: "10", Time: time, IsBuyerMaker: isBuyerMaker}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// TestBrokerRoundTrips places market orders at a 10 bps taker fee and
+// checks the round trips they make, including a fill that flips the
+// position.
+func TestBrokerRoundTrips(t *testing.T) {
+	type step struct {
+		side  string
+		qty   float64
+		price float64
+	}
+	tests := []struct {
+		name  string
+		steps []step
+		want  []RoundTrip
+	}{
+		{
+			name:  "long",
+			steps: []step{{SideBuy, 2, 100}, {SideSell, 2, 110}},
+			// 20 gained less 0.2 and 0.22 of fees.
+			want: []RoundTrip{{Side: "long", EntryTime: 1, ExitTime: 2, Qty: 2, EntryPrice: 100, ExitPrice: 110, PnL: 19.58}},
+		},
+		{
+			name:  "closed in two fills",
+			steps: []step{{SideBuy, 2, 100}, {SideSell, 1, 110}, {SideSell, 1, 120}},
+			want:  []RoundTrip{{Side: "long", EntryTime: 1, ExitTime: 3, Qty: 2, EntryPrice: 100, ExitPrice: 115, PnL: 30 - 0.2 - 0.11 - 0.12}},
+		},
+		{
+			name:  "flip",
+			steps: []step{{SideBuy, 1, 100}, {SideSell, 3, 110}, {SideBuy, 2, 105}},
+			// The 0.33 fee of the flipping sell is split by quantity, 0.11
+			// closes the long and 0.22 opens the short.
+			want: []RoundTrip{
+				{Side: "long", EntryTime: 1, ExitTime: 2, Qty: 1, EntryPrice: 100, ExitPrice: 110, PnL: 10 - 0.1 - 0.11},
+				{Side: "short", EntryTime: 2, ExitTime: 3, Qty: 2, EntryPrice: 110, ExitPrice: 105, PnL: 10 - 0.22 - 0.21},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBroker(BrokerConfig{InitialCash: 1000, TakerFeeBps: 10})
+			for i, s := range tt.steps {
+				b.Market(s.side, s.qty)
+				b.onTrade(trade(int64(i+1), s.price, false))
+			}
+			if b.Position() != 0 || len(b.Orders()) != 0 {
+				t.Fatalf("position %v with %d open orders, want flat", b.Position(), len(b.Orders()))
+			}
+			if len(b.trips) != len(tt.want) {
+				t.Fatalf("round trips %+v, want %+v", b.trips, tt.want)
+			}
+			pnl := 0.0
+			for i, got := range b.trips {
+				w := tt.want[i]
+				if got.Side != w.Side || got.EntryTime != w.EntryTime || got.ExitTime != w.ExitTime || !near(got.Qty, w.Qty) ||
+					!near(got.EntryPrice, w.EntryPrice) || !near(got.ExitPrice, w.ExitPrice) || !near(got.PnL, w.PnL) {
+					t.Errorf("round trip %d: got %+v, want %+v", i, got, w)
+				}
+				pnl += got.PnL
+			}
+			// Flat again, the cash moved by the PnL of the round trips.
+			if !near(b.Cash()-1000, pnl) {
+				t.Errorf("cash %v, want %v", b.Cash(), 1000+pnl)
+			}
+		})
+	}
+}
+
+// TestBrokerLimit checks a resting buy fills at its price as a maker, only
+// on a trade through it or at it against the bids, and takes no more than
+// its participation.
+func TestBrokerLimit(t *testing.T) {
+	b := newBroker(BrokerConfig{InitialCash: 1000, MakerFeeBps: 2, TakerFeeBps: 10, Participation: 0.25})
+	id := b.Limit(SideBuy, 5, 100)
+
+	b.onTrade(trade(1, 101, true))
+	b.onTrade(trade(2, 100, false))
+	if len(b.fills) != 0 {
+		t.Fatalf("fills %+v, want none above the price or at it against the asks", b.fills)
+	}
+	b.onTrade(trade(3, 100, true))
+	b.onTrade(trade(4, 99, false))
+	want := []Fill{
+		{OrderID: id, Time: 3, Side: SideBuy, Price: 100, Qty: 2.5, Fee: 0.05, Maker: true},
+		{OrderID: id, Time: 4, Side: SideBuy, Price: 100, Qty: 2.5, Fee: 0.05, Maker: true},
+	}
+	if len(b.fills) != len(want) {
+		t.Fatalf("fills %+v, want %+v", b.fills, want)
+	}
+	for i, got := range b.fills {
+		w := want[i]
+		if got.OrderID != w.OrderID || got.Time != w.Time || got.Price != w.Price || !near(got.Qty, w.Qty) || !near(got.Fee, w.Fee) || !got.Maker {
+			t.Errorf("fill %d: got %+v, want %+v", i, got, w)
+		}
+	}
+	if b.Position() != 5 || len(b.Orders()) != 0 || !near(b.Cash(), 1000-500-0.1) {
+		t.Errorf("position %v, cash %v, %d open orders", b.Position(), b.Cash(), len(b.Orders()))
+	}
+}
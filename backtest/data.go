@@ -0,0 +1,129 @@
+// Package backtest replays stored klines, and optionally trades and book
+// snapshots, through a Strategy against a simulated broker. It reads the
+// same tables through the same models as the live collector, so research
+// runs on the data production stores.
+package backtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"test.bhft.com/klines"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/storage"
+	"test.bhft.com/trades"
+)
+
+// page is how many rows are read from Postgres at once.
+var page = 10000
+
+// Query selects the stored data of a backtest. Start and End are in
+// milliseconds, inclusive.
+type Query struct {
+	Symbol   string
+	Interval string
+	Start    int64
+	End      int64
+	Trades   bool
+	Books    bool
+}
+
+// Data is the market data a backtest runs on, each kind sorted by time.
+type Data struct {
+	Symbol   string
+	Interval string
+	Klines   []klines.Kline
+	Trades   []trades.Trade
+	Books    []orderbook.Snapshot
+}
+
+// Load reads the data of q. Only klines closed by End are kept, so a
+// backtest never sees a candle that was still running.
+func Load(ctx context.Context, db *sql.DB, q Query) (*Data, error) {
+	d := &Data{Symbol: q.Symbol, Interval: q.Interval}
+	for start := q.Start; ; {
+		list, err := storage.QueryKlines(ctx, db, q.Symbol, q.Interval, start, q.End, page)
+		if err != nil {
+			return nil, fmt.Errorf("klines: %w", err)
+		}
+		for _, k := range list {
+			if k.CloseTime <= q.End {
+				d.Klines = append(d.Klines, k)
+			}
+		}
+		if len(list) < page {
+			break
+		}
+		start = list[len(list)-1].OpenTime + 1
+	}
+	if q.Trades {
+		for fromID := int64(0); ; {
+			list, err := storage.QueryTrades(ctx, db, q.Symbol, fromID, q.Start, q.End, page)
+			if err != nil {
+				return nil, fmt.Errorf("trades: %w", err)
+			}
+			d.Trades = append(d.Trades, list...)
+			if len(list) < page {
+				break
+			}
+			fromID = list[len(list)-1].ID + 1
+		}
+	}
+	if q.Books {
+		for start := q.Start; ; {
+			list, err := storage.QueryOrderBookSnapshots(ctx, db, q.Symbol, start, q.End, page)
+			if err != nil {
+				return nil, fmt.Errorf("book snapshots: %w", err)
+			}
+			d.Books = append(d.Books, list...)
+			if len(list) < page {
+				break
+			}
+			start = list[len(list)-1].Time + 1
+		}
+	}
+	return d, nil
+}
+
+// event is one item of the merged data. A kline happens at its close time.
+type event struct {
+	time  int64
+	kline *klines.Kline
+	trade *trades.Trade
+	book  *orderbook.Snapshot
+}
+
+// rank orders the events of the same millisecond: the trades and the book
+// first, then the kline they belong to.
+func (e event) rank() int {
+	switch {
+	case e.trade != nil:
+		return 0
+	case e.book != nil:
+		return 1
+	}
+	return 2
+}
+
+// events merges the data into one timeline.
+func (d *Data) events() []event {
+	res := make([]event, 0, len(d.Klines)+len(d.Trades)+len(d.Books))
+	for i := range d.Klines {
+		res = append(res, event{time: d.Klines[i].CloseTime, kline: &d.Klines[i]})
+	}
+	for i := range d.Trades {
+		res = append(res, event{time: d.Trades[i].Time, trade: &d.Trades[i]})
+	}
+	for i := range d.Books {
+		res = append(res, event{time: d.Books[i].Time, book: &d.Books[i]})
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].time != res[j].time {
+			return res[i].time < res[j].time
+		}
+		return res[i].rank() < res[j].rank()
+	})
+	return res
+}
@@ -0,0 +1,57 @@
+package backtest
+
+import (
+	"test.bhft.com/indicators"
+	"test.bhft.com/klines"
+	"test.bhft.com/trades"
+)
+
+// SMACross is a trend following example strategy: long Size when the fast
+// SMA of the close crosses above the slow one, flat when it crosses below,
+// or short Size with Short set.
+type SMACross struct {
+	Size       float64
+	Short      bool
+	fast, slow *indicators.SMA
+	above      int // 1 when fast was above slow, -1 below, 0 before both are ready
+}
+
+func NewSMACross(fast, slow int, size float64, short bool) *SMACross {
+	return &SMACross{Size: size, Short: short, fast: indicators.NewSMA(fast), slow: indicators.NewSMA(slow)}
+}
+
+func (s *SMACross) OnCandle(b *Broker, k klines.Kline) {
+	c := indicators.NewCandle(k)
+	fast, slow := s.fast.Update(c), s.slow.Update(c)
+	if fast == nil || slow == nil {
+		return
+	}
+	above := -1
+	if fast["value"] > slow["value"] {
+		above = 1
+	}
+	if above == s.above {
+		return
+	}
+	crossed := s.above != 0
+	s.above = above
+	if !crossed {
+		return
+	}
+	target := s.Size
+	if above < 0 {
+		target = 0
+		if s.Short {
+			target = -s.Size
+		}
+	}
+	b.CancelAll()
+	switch diff := target - b.Position(); {
+	case diff > 0:
+		b.Market(SideBuy, diff)
+	case diff < 0:
+		b.Market(SideSell, -diff)
+	}
+}
+
+func (s *SMACross) OnTrade(*Broker, trades.Trade) {}
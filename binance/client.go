@@ -0,0 +1,174 @@
+// Package binance is the Binance spot adapter: a client for the REST API and
+// market data streams that decodes the exchange messages into the orderbook,
+// trades and klines types. It can run from a capture instead of the network.
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/websocket"
+
+	"test.bhft.com/capture"
+	"test.bhft.com/exchange"
+	"test.bhft.com/telemetry"
+)
+
+const (
+	DefaultBaseURL   = "https://www.binance.com"
+	DefaultStreamURL = "wss://stream.binance.com:9443"
+)
+
+var (
+	pingPath       = "/api/v3/ping"
+	serverTimePath = "/api/v3/time"
+)
+
+// Client talks to Binance over HTTP and websockets. With Replayer set the
+// streams are read from a capture and with Recorder set every frame read is
+// recorded; REST requests go through HTTP, whose transport is expected to
+// do the same. DepthMode selects the depth stream, see ParseDepthMode;
+// empty means 1000ms diffs.
+type Client struct {
+	HTTP      *http.Client
+	BaseURL   string
+	StreamURL string
+	DepthMode string
+	Recorder  *capture.Recorder
+	Replayer  *capture.Replayer
+	// venue names the streams in telemetry.Health when the client serves
+	// another venue, like Futures does.
+	venue string
+}
+
+var (
+	_ exchange.Venue      = (*Client)(nil)
+	_ exchange.QuoteFeed  = (*Client)(nil)
+	_ exchange.TickerFeed = (*Client)(nil)
+)
+
+func NewClient(httpClient *http.Client) *Client {
+	return &Client{
+		HTTP:      httpClient,
+		BaseURL:   DefaultBaseURL,
+		StreamURL: DefaultStreamURL,
+	}
+}
+
+func (c *Client) get(path string, params url.Values, v any) error {
+	u, err := url.ParseRequestURI(c.BaseURL)
+	if err != nil {
+		return err
+	}
+	u.Path = path
+	u.RawQuery = params.Encode()
+	slog.Debug("binance request", "url", u.String())
+	resp, err := c.HTTP.Get(u.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status: %s", path, resp.Status)
+	}
+	if v == nil {
+		_, err := io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Ping checks the connectivity to the REST API.
+func (c *Client) Ping() error {
+	return c.get(pingPath, nil, nil)
+}
+
+// ServerTime returns the exchange time in milliseconds.
+func (c *Client) ServerTime() (int64, error) {
+	return c.serverTime(serverTimePath)
+}
+
+func (c *Client) serverTime(path string) (int64, error) {
+	var body struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	if err := c.get(path, nil, &body); err != nil {
+		return 0, err
+	}
+	return body.ServerTime, nil
+}
+
+// Name is the venue name used in symbols like binance:BTCUSDT.
+func (c *Client) Name() string {
+	return "binance"
+}
+
+// venueName is the venue the streams of c are reported under.
+func (c *Client) venueName() string {
+	if c.venue != "" {
+		return c.venue
+	}
+	return c.Name()
+}
+
+// Dial opens the stream at path, for example /ws/btcusdt@trade. The
+// connection state is reported to telemetry.Health.
+func (c *Client) Dial(path string) (capture.Conn, error) {
+	stream, symbol := streamLabels(path)
+	return c.dial(path, stream, symbol)
+}
+
+// dial opens the stream at path, labelled with stream and symbol. Live
+// streams reconnect when they drop, replayed ones end with the capture.
+func (c *Client) dial(path, stream, symbol string) (capture.Conn, error) {
+	dial := func() (capture.Conn, error) {
+		conn, err := c.open(c.StreamURL+path, stream, symbol)
+		if err != nil {
+			return nil, err
+		}
+		return exchange.TrackConn(conn, c.venueName(), stream, symbol), nil
+	}
+	if c.Replayer != nil {
+		return dial()
+	}
+	return exchange.Redial(dial, stream, symbol)
+}
+
+func (c *Client) open(rawurl, stream, symbol string) (capture.Conn, error) {
+	if c.Replayer != nil {
+		return c.Replayer.Open(capture.StreamKey(rawurl))
+	}
+	telemetry.ObserveDial(stream, symbol)
+	conn, _, err := websocket.DefaultDialer.Dial(rawurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Recorder != nil {
+		return c.Recorder.Conn(conn, rawurl), nil
+	}
+	return conn, nil
+}
+
+// streamLabels turns a stream path like /ws/btcusdt@depth into its stream
+// name and upper case symbol.
+func streamLabels(path string) (string, string) {
+	name := path[strings.LastIndex(path, "/")+1:]
+	symbol, kind, _ := strings.Cut(name, "@")
+	stream := kind
+	switch {
+	case strings.HasPrefix(kind, "depth"):
+		stream = telemetry.StreamBook
+	case kind == "trade":
+		stream = telemetry.StreamTrades
+	case strings.HasPrefix(kind, "kline"):
+		stream = telemetry.StreamKlines
+	case kind == "bookTicker":
+		stream = telemetry.StreamQuotes
+	}
+	return stream, strings.ToUpper(symbol)
+}
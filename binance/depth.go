@@ -0,0 +1,183 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/exchange"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/telemetry"
+)
+
+var (
+	depthPath   = "/api/v3/depth"
+	depthStream = "/ws/%s@%s"
+	// depthSpeeds are the update speeds the spot depth streams take.
+	depthSpeeds = []string{"100ms", "1000ms"}
+)
+
+// ParseDepthMode checks a depth stream mode, the stream name after the
+// symbol: "depth" for diffs, or "depth5", "depth10" and "depth20" for
+// partial books of that many levels per side, each optionally followed by
+// an update speed like "@100ms". It returns the number of partial book
+// levels, 0 for diffs, and the speed, empty for the default one.
+func ParseDepthMode(mode string) (levels int, speed string, err error) {
+	name, speed, _ := strings.Cut(mode, "@")
+	switch name {
+	case "depth":
+	case "depth5", "depth10", "depth20":
+		levels, _ = strconv.Atoi(strings.TrimPrefix(name, "depth"))
+	default:
+		return 0, "", fmt.Errorf("unknown depth mode %q", mode)
+	}
+	return levels, speed, nil
+}
+
+// depthMode parses mode, empty meaning "depth", and checks its speed is one
+// of speeds.
+func depthMode(mode string, speeds []string) (string, int, error) {
+	if mode == "" {
+		mode = "depth"
+	}
+	levels, speed, err := ParseDepthMode(mode)
+	if err != nil {
+		return "", 0, err
+	}
+	if speed != "" && !slices.Contains(speeds, speed) {
+		return "", 0, fmt.Errorf("depth mode %q: speed %s is not one of %s", mode, speed, strings.Join(speeds, ", "))
+	}
+	return mode, levels, nil
+}
+
+// depthEvent is a message of the diff depth stream.
+type depthEvent struct {
+	EventType     string     `json:"e"`
+	EventTime     int64      `json:"E"`
+	Symbol        string     `json:"s"`
+	FirstUpdateID int64      `json:"U"`
+	FinalUpdateID int64      `json:"u"`
+	Bids          [][]string `json:"b"`
+	Asks          [][]string `json:"a"`
+}
+
+func (e depthEvent) update() orderbook.Update {
+	return orderbook.Update{
+		EventTime:     e.EventTime,
+		Symbol:        e.Symbol,
+		FirstUpdateID: e.FirstUpdateID,
+		FinalUpdateID: e.FinalUpdateID,
+		Bids:          e.Bids,
+		Asks:          e.Asks,
+	}
+}
+
+// depthMessage is a message of any spot depth stream: a diff, or the top
+// of the book in partial book mode.
+type depthMessage struct {
+	depthEvent
+	depthSnapshot
+}
+
+type depthSnapshot struct {
+	LastUpdateId int64      `json:"lastUpdateId"`
+	Bids         [][]string `json:"bids"`
+	Asks         [][]string `json:"asks"`
+}
+
+// OrderBook loads a depth snapshot of up to limit levels per side. In a
+// partial book DepthMode the book is empty, the stream sends its top.
+func (c *Client) OrderBook(symbol string, limit int) (*orderbook.Book, error) {
+	if _, levels, err := depthMode(c.DepthMode, depthSpeeds); err == nil && levels > 0 {
+		return orderbook.New(symbol), nil
+	}
+	return c.orderBook(depthPath, symbol, symbol, limit)
+}
+
+// orderBook loads the depth snapshot at path of the book named symbol,
+// which is market on the exchange.
+func (c *Client) orderBook(path, symbol, market string, limit int) (*orderbook.Book, error) {
+	params := url.Values{}
+	params.Add("symbol", market)
+	params.Add("limit", strconv.Itoa(limit))
+	var body depthSnapshot
+	if err := c.get(path, params, &body); err != nil {
+		return nil, err
+	}
+	ordbook := orderbook.New(symbol)
+	ordbook.LastUpdateId = body.LastUpdateId
+	for _, v := range body.Bids {
+		ordbook.Bids[v[0]] = v[1]
+	}
+	for _, v := range body.Asks {
+		ordbook.Asks[v[0]] = v[1]
+	}
+	return ordbook, nil
+}
+
+func (s depthSnapshot) snapshot(symbol string, time int64) orderbook.Snapshot {
+	snapshot := orderbook.Snapshot{Symbol: symbol, Time: time, LastUpdateId: s.LastUpdateId}
+	for _, v := range s.Bids {
+		snapshot.Bids = append(snapshot.Bids, orderbook.Level{Price: v[0], Quantity: v[1]})
+	}
+	for _, v := range s.Asks {
+		snapshot.Asks = append(snapshot.Asks, orderbook.Level{Price: v[0], Quantity: v[1]})
+	}
+	return snapshot
+}
+
+// BookStream reads the depth stream of symbol selected by DepthMode until
+// ctx is done or the connection fails, then closes the returned channel.
+// Diffs continue the book loaded by OrderBook, partial books replace it.
+func (c *Client) BookStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan exchange.BookEvent, error) {
+	mode, levels, err := depthMode(c.DepthMode, depthSpeeds)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan exchange.BookEvent, 10)
+	conn, err := c.Dial(fmt.Sprintf(depthStream, strings.ToLower(symbol), mode))
+	if err != nil {
+		return nil, err
+	}
+
+	logger := slog.With("stream", telemetry.StreamBook, "symbol", symbol)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(ch)
+		defer exchange.CloseOnDone(ctx, conn)()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				exchange.LogReadEnd(ctx, logger, err)
+				return
+			}
+			telemetry.ObserveMessage(c.venueName(), telemetry.StreamBook, symbol)
+			telemetry.LogRawEvent(logger, telemetry.StreamBook, symbol, message)
+			var body depthMessage
+			if err := json.Unmarshal(message, &body); err != nil {
+				telemetry.DecodeErrors.WithLabelValues(telemetry.StreamBook, symbol).Inc()
+				logger.Error("decode depth update", "err", err)
+				return
+			}
+			if levels > 0 && body.LastUpdateId > 0 {
+				// Partial books carry no time, they are stamped when received.
+				snapshot := body.depthSnapshot.snapshot(symbol, clock.Now().UnixMilli())
+				ch <- exchange.BookEvent{Snapshot: &snapshot, Partial: true}
+				telemetry.QueueDepth.WithLabelValues(telemetry.StreamBook, symbol).Set(float64(len(ch)))
+			} else if body.EventType == "depthUpdate" {
+				telemetry.ObserveEvent(telemetry.StreamBook, body.Symbol, body.EventTime)
+				ch <- exchange.BookEvent{Diff: body.update()}
+				telemetry.QueueDepth.WithLabelValues(telemetry.StreamBook, body.Symbol).Set(float64(len(ch)))
+			}
+		}
+	}()
+	return ch, nil
+}
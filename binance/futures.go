@@ -0,0 +1,144 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"test.bhft.com/capture"
+	"test.bhft.com/exchange"
+	"test.bhft.com/telemetry"
+)
+
+const (
+	DefaultFuturesBaseURL   = "https://fapi.binance.com"
+	DefaultFuturesStreamURL = "wss://fstream.binance.com"
+)
+
+var (
+	futuresPingPath       = "/fapi/v1/ping"
+	futuresServerTimePath = "/fapi/v1/time"
+)
+
+// perpSuffix marks the perpetual contracts in symbols, the way COIN-M names
+// them, so BTCUSDT_PERP does not collide with spot BTCUSDT in storage,
+// metrics and the API.
+const perpSuffix = "_PERP"
+
+// Futures is the Binance USDⓈ-M futures adapter. Perpetuals are written like
+// BTCUSDT_PERP, delivery contracts keep their own names like BTCUSDT_250328.
+// It shares the REST, stream and capture handling of Client, only the
+// endpoints and messages differ. DepthMode selects the depth stream like on
+// Client; empty means 250ms diffs.
+type Futures struct {
+	HTTP      *http.Client
+	BaseURL   string
+	StreamURL string
+	DepthMode string
+	Recorder  *capture.Recorder
+	Replayer  *capture.Replayer
+}
+
+var (
+	_ exchange.Venue           = (*Futures)(nil)
+	_ exchange.DerivativesFeed = (*Futures)(nil)
+	_ exchange.QuoteFeed       = (*Futures)(nil)
+	_ exchange.TickerFeed      = (*Futures)(nil)
+)
+
+func NewFutures(httpClient *http.Client) *Futures {
+	return &Futures{
+		HTTP:      httpClient,
+		BaseURL:   DefaultFuturesBaseURL,
+		StreamURL: DefaultFuturesStreamURL,
+	}
+}
+
+func (f *Futures) client() *Client {
+	return &Client{
+		HTTP:      f.HTTP,
+		BaseURL:   f.BaseURL,
+		StreamURL: f.StreamURL,
+		Recorder:  f.Recorder,
+		Replayer:  f.Replayer,
+		venue:     f.Name(),
+	}
+}
+
+// market returns the exchange name of symbol.
+func market(symbol string) string {
+	return strings.TrimSuffix(symbol, perpSuffix)
+}
+
+// Name is the venue name used in symbols like binance-futures:BTCUSDT_PERP.
+func (f *Futures) Name() string {
+	return "binance-futures"
+}
+
+// Ping checks the connectivity to the REST API.
+func (f *Futures) Ping() error {
+	return f.client().get(futuresPingPath, nil, nil)
+}
+
+// ServerTime returns the exchange time in milliseconds.
+func (f *Futures) ServerTime() (int64, error) {
+	return f.client().serverTime(futuresServerTimePath)
+}
+
+// streamName returns the exchange name of symbol as written in stream paths.
+func streamName(symbol string) string {
+	return strings.ToLower(market(symbol))
+}
+
+// eventHeader is the part every futures stream message shares.
+type eventHeader struct {
+	EventType string `json:"e"`
+	EventTime int64  `json:"E"`
+}
+
+// readEvents decodes the messages of conn of type eventType into T, converts
+// them and sends them on ch until ctx is done or the connection fails, then
+// closes ch. Events convert rejects are dropped.
+func readEvents[T, V any](ctx context.Context, wg *sync.WaitGroup, conn capture.Conn, venue, stream, symbol, eventType string, ch chan V, convert func(T) (V, bool)) {
+	logger := slog.With("stream", stream, "symbol", symbol)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(ch)
+		defer exchange.CloseOnDone(ctx, conn)()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				exchange.LogReadEnd(ctx, logger, err)
+				return
+			}
+			telemetry.ObserveMessage(venue, stream, symbol)
+			telemetry.LogRawEvent(logger, stream, symbol, message)
+			var header eventHeader
+			if err := json.Unmarshal(message, &header); err != nil {
+				telemetry.DecodeErrors.WithLabelValues(stream, symbol).Inc()
+				logger.Error("decode event", "err", err)
+				return
+			}
+			if header.EventType != eventType {
+				continue
+			}
+			var body T
+			if err := json.Unmarshal(message, &body); err != nil {
+				telemetry.DecodeErrors.WithLabelValues(stream, symbol).Inc()
+				logger.Error("decode event", "type", eventType, "err", err)
+				return
+			}
+			v, ok := convert(body)
+			if !ok {
+				continue
+			}
+			telemetry.ObserveEvent(stream, symbol, header.EventTime)
+			ch <- v
+			telemetry.QueueDepth.WithLabelValues(stream, symbol).Set(float64(len(ch)))
+		}
+	}()
+}
@@ -0,0 +1,106 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"test.bhft.com/futures"
+	"test.bhft.com/telemetry"
+)
+
+var (
+	openInterestPath  = "/fapi/v1/openInterest"
+	markPriceStream   = "/ws/%s@markPrice@1s"
+	liquidationStream = "/ws/%s@forceOrder"
+)
+
+type markPriceEvent struct {
+	EventTime            int64  `json:"E"`
+	MarkPrice            string `json:"p"`
+	IndexPrice           string `json:"i"`
+	EstimatedSettlePrice string `json:"P"`
+	FundingRate          string `json:"r"`
+	NextFundingTime      int64  `json:"T"`
+}
+
+type forceOrderEvent struct {
+	EventTime int64 `json:"E"`
+	Order     struct {
+		Side           string `json:"S"`
+		OrderType      string `json:"o"`
+		TimeInForce    string `json:"f"`
+		Quantity       string `json:"q"`
+		Price          string `json:"p"`
+		AveragePrice   string `json:"ap"`
+		Status         string `json:"X"`
+		LastFilledQty  string `json:"l"`
+		FilledQuantity string `json:"z"`
+		TradeTime      int64  `json:"T"`
+	} `json:"o"`
+}
+
+// MarkPriceStream reads the mark price and funding rate of symbol every
+// second until ctx is done or the connection fails, then closes the
+// returned channel.
+func (f *Futures) MarkPriceStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan futures.MarkPrice, error) {
+	ch := make(chan futures.MarkPrice, 100)
+	conn, err := f.client().dial(fmt.Sprintf(markPriceStream, streamName(symbol)), telemetry.StreamMarkPrice, symbol)
+	if err != nil {
+		return nil, err
+	}
+	readEvents(ctx, wg, conn, f.Name(), telemetry.StreamMarkPrice, symbol, "markPriceUpdate", ch, func(e markPriceEvent) (futures.MarkPrice, bool) {
+		return futures.MarkPrice{
+			Symbol:               symbol,
+			Time:                 e.EventTime,
+			MarkPrice:            e.MarkPrice,
+			IndexPrice:           e.IndexPrice,
+			EstimatedSettlePrice: e.EstimatedSettlePrice,
+			FundingRate:          e.FundingRate,
+			NextFundingTime:      e.NextFundingTime,
+		}, true
+	})
+	return ch, nil
+}
+
+// OpenInterest loads the current open interest of symbol.
+func (f *Futures) OpenInterest(symbol string) (futures.OpenInterest, error) {
+	params := url.Values{}
+	params.Add("symbol", market(symbol))
+	var body struct {
+		OpenInterest string `json:"openInterest"`
+		Time         int64  `json:"time"`
+	}
+	if err := f.client().get(openInterestPath, params, &body); err != nil {
+		return futures.OpenInterest{}, err
+	}
+	return futures.OpenInterest{Symbol: symbol, Time: body.Time, OpenInterest: body.OpenInterest}, nil
+}
+
+// LiquidationStream reads the liquidation orders of symbol until ctx is done
+// or the connection fails, then closes the returned channel. Binance sends
+// at most the latest liquidation of a symbol per second.
+func (f *Futures) LiquidationStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan futures.Liquidation, error) {
+	ch := make(chan futures.Liquidation, 100)
+	conn, err := f.client().dial(fmt.Sprintf(liquidationStream, streamName(symbol)), telemetry.StreamLiquidations, symbol)
+	if err != nil {
+		return nil, err
+	}
+	readEvents(ctx, wg, conn, f.Name(), telemetry.StreamLiquidations, symbol, "forceOrder", ch, func(e forceOrderEvent) (futures.Liquidation, bool) {
+		return futures.Liquidation{
+			Symbol:         symbol,
+			Time:           e.Order.TradeTime,
+			Side:           e.Order.Side,
+			OrderType:      e.Order.OrderType,
+			TimeInForce:    e.Order.TimeInForce,
+			Quantity:       e.Order.Quantity,
+			Price:          e.Order.Price,
+			AveragePrice:   e.Order.AveragePrice,
+			Status:         e.Order.Status,
+			LastFilledQty:  e.Order.LastFilledQty,
+			FilledQuantity: e.Order.FilledQuantity,
+		}, true
+	})
+	return ch, nil
+}
@@ -0,0 +1,166 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"test.bhft.com/exchange"
+	"test.bhft.com/klines"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/telemetry"
+	"test.bhft.com/trades"
+)
+
+var (
+	futuresDepthPath     = "/fapi/v1/depth"
+	futuresAggTradesPath = "/fapi/v1/aggTrades"
+	futuresKlinesPath    = "/fapi/v1/klines"
+	futuresDepthStream   = "/ws/%s@%s"
+	// futuresDepthSpeeds are the update speeds the futures depth streams
+	// take, 250ms by default.
+	futuresDepthSpeeds  = []string{"100ms", "250ms", "500ms"}
+	aggTradeStream      = "/ws/%s@aggTrade"
+	futuresKlinesStream = "/ws/%s@kline_%s"
+)
+
+// futuresDepthEvent is a message of the futures diff depth stream.
+// PrevFinalUpdateID is the final update ID of the previous message.
+type futuresDepthEvent struct {
+	depthEvent
+	TransactionTime   int64 `json:"T"`
+	PrevFinalUpdateID int64 `json:"pu"`
+}
+
+// update maps the futures sync rule onto orderbook.Book.Update: a diff
+// follows the previous one when pu is its final update ID, so FirstUpdateID
+// is pu+1 rather than U. The first diff after the snapshot then is the one
+// with U <= lastUpdateId <= u, as the futures rule asks.
+func (e futuresDepthEvent) update(symbol string) orderbook.Update {
+	u := e.depthEvent.update()
+	u.Symbol = symbol
+	u.FirstUpdateID = e.PrevFinalUpdateID + 1
+	return u
+}
+
+type aggTradeEvent struct {
+	EventTime    int64  `json:"E"`
+	AggTradeID   int64  `json:"a"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	FirstTradeID int64  `json:"f"`
+	LastTradeID  int64  `json:"l"`
+	TradeTime    int64  `json:"T"`
+	IsBuyerMaker bool   `json:"m"`
+}
+
+// trade converts an aggregate trade, the fills of one taker order at one
+// price. Its ID is the aggregate trade ID.
+func (e aggTradeEvent) trade() trades.Trade {
+	price, _ := strconv.ParseFloat(e.Price, 64)
+	qty, _ := strconv.ParseFloat(e.Quantity, 64)
+	return trades.Trade{
+		ID:            e.AggTradeID,
+		Price:         e.Price,
+		Quantity:      e.Quantity,
+		QuoteQuantity: strconv.FormatFloat(price*qty, 'f', -1, 64),
+		Time:          e.TradeTime,
+		IsBuyerMaker:  e.IsBuyerMaker,
+	}
+}
+
+// OrderBook loads a depth snapshot of up to limit levels per side. In a
+// partial book DepthMode the book is empty, the stream sends its top.
+func (f *Futures) OrderBook(symbol string, limit int) (*orderbook.Book, error) {
+	if _, levels, err := depthMode(f.DepthMode, futuresDepthSpeeds); err == nil && levels > 0 {
+		return orderbook.New(symbol), nil
+	}
+	return f.client().orderBook(futuresDepthPath, symbol, market(symbol), limit)
+}
+
+// BookStream reads the depth stream of symbol selected by DepthMode until
+// ctx is done or the connection fails, then closes the returned channel.
+// Diffs continue the book loaded by OrderBook, partial books replace it.
+func (f *Futures) BookStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan exchange.BookEvent, error) {
+	mode, levels, err := depthMode(f.DepthMode, futuresDepthSpeeds)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan exchange.BookEvent, 10)
+	conn, err := f.client().dial(fmt.Sprintf(futuresDepthStream, streamName(symbol), mode), telemetry.StreamBook, symbol)
+	if err != nil {
+		return nil, err
+	}
+	readEvents(ctx, wg, conn, f.Name(), telemetry.StreamBook, symbol, "depthUpdate", ch, func(e futuresDepthEvent) (exchange.BookEvent, bool) {
+		if levels > 0 {
+			snapshot := depthSnapshot{LastUpdateId: e.FinalUpdateID, Bids: e.Bids, Asks: e.Asks}.snapshot(symbol, e.TransactionTime)
+			return exchange.BookEvent{Snapshot: &snapshot, Partial: true}, true
+		}
+		return exchange.BookEvent{Diff: e.update(symbol)}, true
+	})
+	return ch, nil
+}
+
+// Trades loads the latest limit aggregate trades of symbol.
+func (f *Futures) Trades(symbol string, limit int) (*trades.List, error) {
+	params := url.Values{}
+	params.Add("symbol", market(symbol))
+	params.Add("limit", strconv.Itoa(limit))
+	var body []aggTradeEvent
+	if err := f.client().get(futuresAggTradesPath, params, &body); err != nil {
+		return nil, err
+	}
+	list := make([]trades.Trade, 0, len(body))
+	for _, t := range body {
+		list = append(list, t.trade())
+	}
+	return trades.New(symbol, list), nil
+}
+
+// TradeStream reads the aggregate trade stream of symbol until ctx is done
+// or the connection fails, then closes the returned channel.
+func (f *Futures) TradeStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan trades.Trade, error) {
+	ch := make(chan trades.Trade, 100)
+	conn, err := f.client().dial(fmt.Sprintf(aggTradeStream, streamName(symbol)), telemetry.StreamTrades, symbol)
+	if err != nil {
+		return nil, err
+	}
+	readEvents(ctx, wg, conn, f.Name(), telemetry.StreamTrades, symbol, "aggTrade", ch, func(e aggTradeEvent) (trades.Trade, bool) {
+		return e.trade(), true
+	})
+	return ch, nil
+}
+
+// Klines loads the latest limit candles of symbol and interval.
+func (f *Futures) Klines(symbol, interval string, limit int) (*klines.List, error) {
+	params := url.Values{}
+	params.Add("symbol", market(symbol))
+	params.Add("interval", interval)
+	params.Add("limit", strconv.Itoa(limit))
+
+	list, err := f.client().klines(futuresKlinesPath, params)
+	if err != nil {
+		return nil, err
+	}
+	klineList := klines.New(symbol, interval)
+	klineList.List = list
+	return klineList, nil
+}
+
+// KlineStream reads the kline stream of symbol and interval until ctx is
+// done or the connection fails, then closes the returned channel.
+func (f *Futures) KlineStream(ctx context.Context, wg *sync.WaitGroup, symbol, interval string) (chan klines.Update, error) {
+	ch := make(chan klines.Update, 100)
+	conn, err := f.client().dial(fmt.Sprintf(futuresKlinesStream, streamName(symbol), interval), telemetry.StreamKlines, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("klines: dial: %w", err)
+	}
+	readEvents(ctx, wg, conn, f.Name(), telemetry.StreamKlines, symbol, "kline", ch, func(e klineEvent) (klines.Update, bool) {
+		u := e.update()
+		u.Symbol = symbol
+		return u, u.Interval == interval
+	})
+	return ch, nil
+}
@@ -0,0 +1,112 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// newFakeFutures serves a depth snapshot at lastUpdateId 100 and sends
+// frames on the depth stream of BTCUSDT.
+func newFakeFutures(t *testing.T, frames []string) *Futures {
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(futuresDepthPath, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("symbol"); got != "BTCUSDT" {
+			t.Errorf("depth symbol %q, want BTCUSDT", got)
+		}
+		w.Write([]byte(`{"lastUpdateId":100,"bids":[["100.0","1.0"]],"asks":[["101.0","1.0"]]}`))
+	})
+	mux.HandleFunc("/ws/btcusdt@depth", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for _, f := range frames {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(f)); err != nil {
+				return
+			}
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	f := NewFutures(srv.Client())
+	f.BaseURL = srv.URL
+	f.StreamURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+	return f
+}
+
+// TestFuturesBookSync checks the futures rule: the first diff is the one
+// with U <= lastUpdateId <= u, later diffs follow when pu is the final
+// update ID of the previous one, whatever their U.
+func TestFuturesBookSync(t *testing.T) {
+	f := newFakeFutures(t, []string{
+		// Older than the snapshot, skipped.
+		`{"e":"depthUpdate","E":1,"T":1,"s":"BTCUSDT","U":90,"u":99,"pu":89,"b":[["100.0","9.0"]],"a":[]}`,
+		// Spans the snapshot, syncs the book.
+		`{"e":"depthUpdate","E":2,"T":2,"s":"BTCUSDT","U":95,"u":105,"pu":94,"b":[["100.0","2.0"]],"a":[]}`,
+		// U skips ahead but pu chains on the last diff.
+		`{"e":"depthUpdate","E":3,"T":3,"s":"BTCUSDT","U":110,"u":112,"pu":105,"b":[],"a":[["101.0","3.0"]]}`,
+		// pu does not chain on 112, a gap.
+		`{"e":"depthUpdate","E":4,"T":4,"s":"BTCUSDT","U":120,"u":125,"pu":118,"b":[["100.0","5.0"]],"a":[]}`,
+	})
+	book, err := f.OrderBook("BTCUSDT_PERP", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if book.LastID() != 100 || book.Symbol != "BTCUSDT_PERP" {
+		t.Fatalf("book %s at %d, want BTCUSDT_PERP at 100", book.Symbol, book.LastID())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	ch, err := f.BookStream(ctx, &wg, "BTCUSDT_PERP")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		applied bool
+		synced  bool
+		last    int64
+	}{
+		{false, false, 100},
+		{true, true, 105},
+		{true, true, 112},
+		{false, false, 112},
+	}
+	for i, w := range want {
+		var applied bool
+		select {
+		case e := <-ch:
+			if e.Diff.Symbol != "BTCUSDT_PERP" {
+				t.Fatalf("diff symbol %q", e.Diff.Symbol)
+			}
+			applied = book.Update(&e.Diff)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("diff %d not received", i)
+		}
+		if applied != w.applied || book.Synced() != w.synced || book.LastID() != w.last {
+			t.Errorf("diff %d: applied %v, synced %v at %d, want %v, %v at %d", i, applied, book.Synced(), book.LastID(), w.applied, w.synced, w.last)
+		}
+	}
+	bid, ask, _ := book.TopLevels()
+	if bid.Quantity != 2 || ask.Quantity != 3 {
+		t.Errorf("top %v %v, want bid 2.0 and ask 3.0", bid, ask)
+	}
+}
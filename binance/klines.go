@@ -0,0 +1,160 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"test.bhft.com/exchange"
+	"test.bhft.com/klines"
+	"test.bhft.com/telemetry"
+)
+
+var (
+	klinesPath   = "/api/v3/klines"
+	klinesStream = "/ws/%s@kline_%s"
+)
+
+type klineEvent struct {
+	EventType string `json:"e"`
+	EventTime int64  `json:"E"`
+	Symbol    string `json:"s"`
+	Kline     struct {
+		StartTime        int64  `json:"t"`
+		CloseTime        int64  `json:"T"`
+		Interval         string `json:"i"`
+		FirstTradeID     int64  `json:"f"`
+		LastTradeID      int64  `json:"L"`
+		OpenPrice        string `json:"o"`
+		ClosePrice       string `json:"c"`
+		HighPrice        string `json:"h"`
+		LowPrice         string `json:"l"`
+		BaseAssetVolume  string `json:"v"`
+		NumberOfTrades   int64  `json:"n"`
+		IsClosed         bool   `json:"x"`
+		QuoteAssetVolume string `json:"q"`
+		TakerBuyBaseVol  string `json:"V"`
+		TakerBuyQuoteVol string `json:"Q"`
+	} `json:"k"`
+}
+
+func (ke klineEvent) update() klines.Update {
+	return klines.Update{
+		Symbol:   ke.Symbol,
+		Interval: ke.Kline.Interval,
+		Kline: klines.Kline{
+			OpenTime:                 ke.Kline.StartTime,
+			Open:                     ke.Kline.OpenPrice,
+			High:                     ke.Kline.HighPrice,
+			Low:                      ke.Kline.LowPrice,
+			Close:                    ke.Kline.ClosePrice,
+			Volume:                   ke.Kline.BaseAssetVolume,
+			CloseTime:                ke.Kline.CloseTime,
+			QuoteAssetVolume:         ke.Kline.QuoteAssetVolume,
+			NumberOfTrades:           ke.Kline.NumberOfTrades,
+			TakerBuyBaseAssetVolume:  ke.Kline.TakerBuyBaseVol,
+			TakerBuyQuoteAssetVolume: ke.Kline.TakerBuyQuoteVol,
+		},
+		Closed: ke.Kline.IsClosed,
+	}
+}
+
+// Klines loads the latest limit candles of symbol and interval.
+func (c *Client) Klines(symbol, interval string, limit int) (*klines.List, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("interval", interval)
+	params.Add("limit", strconv.Itoa(limit))
+
+	list, err := c.klines(klinesPath, params)
+	if err != nil {
+		return nil, err
+	}
+	klineList := klines.New(symbol, interval)
+	klineList.List = list
+	return klineList, nil
+}
+
+// KlinesRange loads up to limit candles of symbol and interval opened
+// between start and end inclusive, in milliseconds. Binance caps limit at
+// 1000, so longer ranges are read page by page.
+func (c *Client) KlinesRange(symbol, interval string, start, end int64, limit int) ([]klines.Kline, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("interval", interval)
+	params.Add("startTime", strconv.FormatInt(start, 10))
+	params.Add("endTime", strconv.FormatInt(end, 10))
+	params.Add("limit", strconv.Itoa(limit))
+	return c.klines(klinesPath, params)
+}
+
+func (c *Client) klines(path string, params url.Values) ([]klines.Kline, error) {
+	var rawbody [][]interface{}
+	if err := c.get(path, params, &rawbody); err != nil {
+		return nil, err
+	}
+
+	res := make([]klines.Kline, 0, len(rawbody))
+	for _, k := range rawbody {
+		kline := klines.Kline{
+			OpenTime:                 int64(k[0].(float64)),
+			Open:                     k[1].(string),
+			High:                     k[2].(string),
+			Low:                      k[3].(string),
+			Close:                    k[4].(string),
+			Volume:                   k[5].(string),
+			CloseTime:                int64(k[6].(float64)),
+			QuoteAssetVolume:         k[7].(string),
+			NumberOfTrades:           int64(k[8].(float64)),
+			TakerBuyBaseAssetVolume:  k[9].(string),
+			TakerBuyQuoteAssetVolume: k[10].(string),
+		}
+		res = append(res, kline)
+	}
+	return res, nil
+}
+
+// KlineStream reads the kline stream of symbol and interval until ctx is
+// done or the connection fails, then closes the returned channel.
+func (c *Client) KlineStream(ctx context.Context, wg *sync.WaitGroup, symbol, interval string) (chan klines.Update, error) {
+	ch := make(chan klines.Update, 100)
+	path := fmt.Sprintf(klinesStream, strings.ToLower(symbol), interval)
+	conn, err := c.Dial(path)
+	if err != nil {
+		return nil, fmt.Errorf("klines: dial: %w, url: %s", err, path)
+	}
+
+	logger := slog.With("stream", telemetry.StreamKlines, "symbol", symbol, "interval", interval)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(ch)
+		defer exchange.CloseOnDone(ctx, conn)()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				exchange.LogReadEnd(ctx, logger, err)
+				return
+			}
+			telemetry.ObserveMessage(c.venueName(), telemetry.StreamKlines, symbol)
+			telemetry.LogRawEvent(logger, telemetry.StreamKlines, symbol, message)
+			var body klineEvent
+			if err := json.Unmarshal(message, &body); err != nil {
+				telemetry.DecodeErrors.WithLabelValues(telemetry.StreamKlines, symbol).Inc()
+				logger.Error("decode kline", "err", err)
+				return
+			}
+			if body.EventType == "kline" && body.Symbol == symbol {
+				telemetry.ObserveEvent(telemetry.StreamKlines, symbol, body.EventTime)
+				ch <- body.update()
+				telemetry.QueueDepth.WithLabelValues(telemetry.StreamKlines, symbol).Set(float64(len(ch)))
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,96 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/exchange"
+	"test.bhft.com/quotes"
+	"test.bhft.com/telemetry"
+)
+
+var bookTickerStream = "/ws/%s@bookTicker"
+
+// bookTickerEvent is a message of the book ticker stream. Spot sends neither
+// an event type nor times, futures send both.
+type bookTickerEvent struct {
+	EventType       string `json:"e"`
+	EventTime       int64  `json:"E"`
+	TransactionTime int64  `json:"T"`
+	UpdateID        int64  `json:"u"`
+	Symbol          string `json:"s"`
+	BidPrice        string `json:"b"`
+	BidQty          string `json:"B"`
+	AskPrice        string `json:"a"`
+	AskQty          string `json:"A"`
+}
+
+func (e bookTickerEvent) quote(symbol string, time int64) quotes.Quote {
+	return quotes.Quote{
+		Symbol:   symbol,
+		UpdateID: e.UpdateID,
+		Time:     time,
+		BidPrice: e.BidPrice,
+		BidQty:   e.BidQty,
+		AskPrice: e.AskPrice,
+		AskQty:   e.AskQty,
+	}
+}
+
+// QuoteStream reads the book ticker stream of symbol until ctx is done or
+// the connection fails, then closes the returned channel. Spot quotes carry
+// no time, they are stamped when received.
+func (c *Client) QuoteStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan quotes.Quote, error) {
+	ch := make(chan quotes.Quote, 100)
+	conn, err := c.Dial(fmt.Sprintf(bookTickerStream, strings.ToLower(symbol)))
+	if err != nil {
+		return nil, err
+	}
+
+	logger := slog.With("stream", telemetry.StreamQuotes, "symbol", symbol)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(ch)
+		defer exchange.CloseOnDone(ctx, conn)()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				exchange.LogReadEnd(ctx, logger, err)
+				return
+			}
+			telemetry.ObserveMessage(c.venueName(), telemetry.StreamQuotes, symbol)
+			telemetry.LogRawEvent(logger, telemetry.StreamQuotes, symbol, message)
+			var body bookTickerEvent
+			if err := json.Unmarshal(message, &body); err != nil {
+				telemetry.DecodeErrors.WithLabelValues(telemetry.StreamQuotes, symbol).Inc()
+				logger.Error("decode book ticker", "err", err)
+				return
+			}
+			if body.Symbol == symbol {
+				ch <- body.quote(symbol, clock.Now().UnixMilli())
+				telemetry.QueueDepth.WithLabelValues(telemetry.StreamQuotes, symbol).Set(float64(len(ch)))
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// QuoteStream reads the book ticker stream of symbol until ctx is done or
+// the connection fails, then closes the returned channel.
+func (f *Futures) QuoteStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan quotes.Quote, error) {
+	ch := make(chan quotes.Quote, 100)
+	conn, err := f.client().dial(fmt.Sprintf(bookTickerStream, streamName(symbol)), telemetry.StreamQuotes, symbol)
+	if err != nil {
+		return nil, err
+	}
+	readEvents(ctx, wg, conn, f.Name(), telemetry.StreamQuotes, symbol, "bookTicker", ch, func(e bookTickerEvent) (quotes.Quote, bool) {
+		return e.quote(symbol, e.TransactionTime), true
+	})
+	return ch, nil
+}
@@ -0,0 +1,128 @@
+package binance
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"test.bhft.com/ticker"
+)
+
+var tickerStream = "/ws/%s@%s"
+
+// tickerKinds are the ticker streams of the spot API by name, with the event
+// type of their messages and the window they cover.
+var tickerKinds = map[string]struct{ eventType, window string }{
+	"ticker":     {"24hrTicker", "1d"},
+	"miniTicker": {"24hrMiniTicker", "1d"},
+	"ticker_1h":  {"1hTicker", "1h"},
+	"ticker_4h":  {"4hTicker", "4h"},
+	"ticker_1d":  {"1dTicker", "1d"},
+}
+
+// futuresTickerKinds are the ticker streams futures have, without the
+// rolling windows of spot.
+var futuresTickerKinds = []string{"ticker", "miniTicker"}
+
+// tickerEvent is a message of any ticker stream. The mini ticker only sends
+// the prices and volumes, the fields it lacks stay empty.
+type tickerEvent struct {
+	EventType          string `json:"e"`
+	EventTime          int64  `json:"E"`
+	Symbol             string `json:"s"`
+	PriceChange        string `json:"p"`
+	PriceChangePercent string `json:"P"`
+	VWAP               string `json:"w"`
+	Open               string `json:"o"`
+	High               string `json:"h"`
+	Low                string `json:"l"`
+	Last               string `json:"c"`
+	Volume             string `json:"v"`
+	QuoteVolume        string `json:"q"`
+	OpenTime           int64  `json:"O"`
+	CloseTime          int64  `json:"C"`
+	FirstID            int64  `json:"F"`
+	LastID             int64  `json:"L"`
+	Count              int64  `json:"n"`
+}
+
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+func (e tickerEvent) stats(symbol, kind, window string) ticker.Stats {
+	s := ticker.Stats{
+		Symbol:             symbol,
+		Source:             kind,
+		Window:             window,
+		OpenTime:           e.OpenTime,
+		CloseTime:          e.CloseTime,
+		Open:               parseFloat(e.Open),
+		High:               parseFloat(e.High),
+		Low:                parseFloat(e.Low),
+		Last:               parseFloat(e.Last),
+		PriceChange:        parseFloat(e.PriceChange),
+		PriceChangePercent: parseFloat(e.PriceChangePercent),
+		Volume:             parseFloat(e.Volume),
+		QuoteVolume:        parseFloat(e.QuoteVolume),
+		VWAP:               parseFloat(e.VWAP),
+		Count:              e.Count,
+		FirstID:            e.FirstID,
+		LastID:             e.LastID,
+	}
+	if e.EventType == tickerKinds["miniTicker"].eventType {
+		// The mini ticker has no times nor change, they follow from the rest.
+		s.CloseTime = e.EventTime
+		s.OpenTime = e.EventTime - 24*60*60*1000
+		s.PriceChange = s.Last - s.Open
+		if s.Open != 0 {
+			s.PriceChangePercent = s.PriceChange / s.Open * 100
+		}
+		if s.Volume != 0 {
+			s.VWAP = s.QuoteVolume / s.Volume
+		}
+	}
+	return s
+}
+
+// TickerStream reads the ticker stream kind of symbol, one of ticker,
+// miniTicker, ticker_1h, ticker_4h or ticker_1d, until ctx is done or the
+// connection fails, then closes the returned channel.
+func (c *Client) TickerStream(ctx context.Context, wg *sync.WaitGroup, symbol, kind string) (chan ticker.Stats, error) {
+	k, ok := tickerKinds[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown ticker stream %q", kind)
+	}
+	ch := make(chan ticker.Stats, 100)
+	conn, err := c.Dial(fmt.Sprintf(tickerStream, strings.ToLower(symbol), kind))
+	if err != nil {
+		return nil, err
+	}
+	readEvents(ctx, wg, conn, c.Name(), kind, symbol, k.eventType, ch, func(e tickerEvent) (ticker.Stats, bool) {
+		return e.stats(symbol, kind, k.window), e.Symbol == symbol
+	})
+	return ch, nil
+}
+
+// TickerStream reads the ticker or miniTicker stream of symbol until ctx is
+// done or the connection fails, then closes the returned channel. Futures
+// have no rolling window tickers.
+func (f *Futures) TickerStream(ctx context.Context, wg *sync.WaitGroup, symbol, kind string) (chan ticker.Stats, error) {
+	k, ok := tickerKinds[kind]
+	if !ok || !slices.Contains(futuresTickerKinds, kind) {
+		return nil, fmt.Errorf("unknown futures ticker stream %q", kind)
+	}
+	ch := make(chan ticker.Stats, 100)
+	conn, err := f.client().dial(fmt.Sprintf(tickerStream, streamName(symbol), kind), kind, symbol)
+	if err != nil {
+		return nil, err
+	}
+	readEvents(ctx, wg, conn, f.Name(), kind, symbol, k.eventType, ch, func(e tickerEvent) (ticker.Stats, bool) {
+		return e.stats(symbol, kind, k.window), e.Symbol == market(symbol)
+	})
+	return ch, nil
+}
@@ -0,0 +1,137 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"test.bhft.com/exchange"
+	"test.bhft.com/telemetry"
+	"test.bhft.com/trades"
+)
+
+var (
+	tradesPath           = "/api/v3/trades"
+	historicalTradesPath = "/api/v3/historicalTrades"
+	aggTradesPath        = "/api/v3/aggTrades"
+	tradesStream         = "/ws/%s@trade"
+)
+
+type tradeEvent struct {
+	EventType    string `json:"e"`
+	EventTime    int64  `json:"E"`
+	Symbol       string `json:"s"`
+	TradeID      int64  `json:"t"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	TradeTime    int64  `json:"T"`
+	IsBuyerMaker bool   `json:"m"`
+	Ignore       bool   `json:"M"`
+}
+
+// trade converts a stream trade, which carries no quote quantity, so it is
+// price times quantity as in the REST trades.
+func (e tradeEvent) trade() trades.Trade {
+	price, _ := strconv.ParseFloat(e.Price, 64)
+	qty, _ := strconv.ParseFloat(e.Quantity, 64)
+	return trades.Trade{
+		ID:            e.TradeID,
+		Price:         e.Price,
+		Quantity:      e.Quantity,
+		QuoteQuantity: strconv.FormatFloat(price*qty, 'f', -1, 64),
+		Time:          e.TradeTime,
+		IsBuyerMaker:  e.IsBuyerMaker,
+	}
+}
+
+// Trades loads the latest limit trades of symbol.
+func (c *Client) Trades(symbol string, limit int) (*trades.List, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("limit", strconv.Itoa(limit))
+	var body []trades.Trade
+	if err := c.get(tradesPath, params, &body); err != nil {
+		return nil, err
+	}
+	return trades.New(symbol, body), nil
+}
+
+// HistoricalTrades loads up to limit trades of symbol from the trade with ID
+// fromID on.
+func (c *Client) HistoricalTrades(symbol string, fromID int64, limit int) ([]trades.Trade, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("fromId", strconv.FormatInt(fromID, 10))
+	params.Add("limit", strconv.Itoa(limit))
+	var body []trades.Trade
+	if err := c.get(historicalTradesPath, params, &body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// FirstTradeID returns the ID of the first trade of symbol made at or after
+// start, in milliseconds. ok is false when there was no trade in the hour
+// after start, the widest window the aggregate trades endpoint accepts.
+func (c *Client) FirstTradeID(symbol string, start int64) (id int64, ok bool, err error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("startTime", strconv.FormatInt(start, 10))
+	params.Add("endTime", strconv.FormatInt(start+time.Hour.Milliseconds()-1, 10))
+	params.Add("limit", "1")
+	var body []struct {
+		FirstTradeID int64 `json:"f"`
+	}
+	if err := c.get(aggTradesPath, params, &body); err != nil {
+		return 0, false, err
+	}
+	if len(body) == 0 {
+		return 0, false, nil
+	}
+	return body[0].FirstTradeID, true, nil
+}
+
+// TradeStream reads the trade stream of symbol until ctx is done or the
+// connection fails, then closes the returned channel.
+func (c *Client) TradeStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan trades.Trade, error) {
+	ch := make(chan trades.Trade, 100)
+	conn, err := c.Dial(fmt.Sprintf(tradesStream, strings.ToLower(symbol)))
+	if err != nil {
+		return nil, err
+	}
+
+	logger := slog.With("stream", telemetry.StreamTrades, "symbol", symbol)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(ch)
+		defer exchange.CloseOnDone(ctx, conn)()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				exchange.LogReadEnd(ctx, logger, err)
+				return
+			}
+			telemetry.ObserveMessage(c.venueName(), telemetry.StreamTrades, symbol)
+			telemetry.LogRawEvent(logger, telemetry.StreamTrades, symbol, message)
+			var body tradeEvent
+			if err := json.Unmarshal(message, &body); err != nil {
+				telemetry.DecodeErrors.WithLabelValues(telemetry.StreamTrades, symbol).Inc()
+				logger.Error("decode trade", "err", err)
+				return
+			}
+			if body.EventType == "trade" {
+				telemetry.ObserveEvent(telemetry.StreamTrades, body.Symbol, body.EventTime)
+				ch <- body.trade()
+				telemetry.QueueDepth.WithLabelValues(telemetry.StreamTrades, body.Symbol).Set(float64(len(ch)))
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,337 @@
+// Package capture records REST responses and websocket frames to a JSON
+// lines file and replays them later, driving a simulated clock.
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"test.bhft.com/clock"
+)
+
+const (
+	frameRest = "rest"
+	frameWS   = "ws"
+)
+
+// Frame is one line of a capture file: a REST response body or a websocket
+// message together with the time it was received.
+type Frame struct {
+	Time   int64           `json:"t"`
+	Kind   string          `json:"kind"`
+	URL    string          `json:"url"`
+	Status int             `json:"status,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Conn is the part of *websocket.Conn the stream readers use.
+type Conn interface {
+	ReadMessage() (int, []byte, error)
+	Close() error
+}
+
+// StreamKey is the path and query of a URL, the key frames are stored
+// under.
+func StreamKey(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return rawurl
+	}
+	if u.RawQuery != "" {
+		return u.Path + "?" + u.RawQuery
+	}
+	return u.Path
+}
+
+// Recorder appends frames to a capture file in JSON lines format.
+type Recorder struct {
+	sync.Mutex
+	f *os.File
+	w *bufio.Writer
+}
+
+func NewRecorder(path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{f: f, w: bufio.NewWriter(f)}, nil
+}
+
+// Write appends one frame. Data that is not valid JSON is stored as a JSON
+// string.
+func (r *Recorder) Write(kind, url string, status int, data []byte) error {
+	raw := json.RawMessage(data)
+	if !json.Valid(data) {
+		s, err := json.Marshal(string(data))
+		if err != nil {
+			return err
+		}
+		raw = s
+	}
+	line, err := json.Marshal(Frame{
+		Time:   clock.Now().UnixMilli(),
+		Kind:   kind,
+		URL:    url,
+		Status: status,
+		Data:   raw,
+	})
+	if err != nil {
+		return err
+	}
+	r.Lock()
+	defer r.Unlock()
+	if _, err := r.w.Write(line); err != nil {
+		return err
+	}
+	return r.w.WriteByte('\n')
+}
+
+func (r *Recorder) Close() error {
+	r.Lock()
+	defer r.Unlock()
+	if err := r.w.Flush(); err != nil {
+		r.f.Close()
+		return err
+	}
+	return r.f.Close()
+}
+
+// Conn wraps a websocket connection to the stream at rawurl so every
+// message read is recorded.
+func (r *Recorder) Conn(c *websocket.Conn, rawurl string) Conn {
+	return &recordingConn{Conn: c, recorder: r, url: StreamKey(rawurl)}
+}
+
+// Transport returns a RoundTripper storing every REST response body it gets
+// from next.
+func (r *Recorder) Transport(next http.RoundTripper) http.RoundTripper {
+	return &recordingTransport{next: next, recorder: r}
+}
+
+type recordingConn struct {
+	*websocket.Conn
+	recorder *Recorder
+	url      string
+}
+
+func (c *recordingConn) ReadMessage() (int, []byte, error) {
+	mt, message, err := c.Conn.ReadMessage()
+	if err == nil {
+		if err := c.recorder.Write(frameWS, c.url, 0, message); err != nil {
+			slog.Error("record frame", "url", c.url, "err", err)
+		}
+	}
+	return mt, message, err
+}
+
+type recordingTransport struct {
+	next     http.RoundTripper
+	recorder *Recorder
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err := t.recorder.Write(frameRest, StreamKey(req.URL.String()), resp.StatusCode, body); err != nil {
+		slog.Error("record response", "url", req.URL.String(), "err", err)
+	}
+	return resp, nil
+}
+
+// Replayer plays back a capture. REST requests are answered from the recorded
+// responses in order, websocket frames are delivered to the opened streams
+// with their original spacing divided by Speed. Speed 0 replays as fast as
+// the pipelines consume.
+type Replayer struct {
+	sync.Mutex
+	Speed   float64
+	Clock   *clock.SimClock
+	frames  []Frame
+	rest    map[string][]Frame
+	streams map[string]chan []byte
+	done    bool
+}
+
+func NewReplayer(path string, speed float64) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := &Replayer{
+		Speed:   speed,
+		rest:    make(map[string][]Frame),
+		streams: make(map[string]chan []byte),
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var fr Frame
+		if err := json.Unmarshal(line, &fr); err != nil {
+			return nil, fmt.Errorf("replay: %s: %w", path, err)
+		}
+		switch fr.Kind {
+		case frameRest:
+			r.rest[fr.URL] = append(r.rest[fr.URL], fr)
+		case frameWS:
+			r.frames = append(r.frames, fr)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(r.frames, func(i, j int) bool {
+		return r.frames[i].Time < r.frames[j].Time
+	})
+
+	start := time.Now()
+	if len(r.frames) > 0 {
+		start = time.UnixMilli(r.frames[0].Time)
+	}
+	for _, frames := range r.rest {
+		if len(frames) > 0 && frames[0].Time < start.UnixMilli() {
+			start = time.UnixMilli(frames[0].Time)
+		}
+	}
+	r.Clock = clock.NewSimClock(start)
+	return r, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.Lock()
+	defer r.Unlock()
+	key := StreamKey(req.URL.String())
+	frames := r.rest[key]
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("replay: no recorded response for %s", key)
+	}
+	fr := frames[0]
+	r.rest[key] = frames[1:]
+	r.Clock.Advance(time.UnixMilli(fr.Time))
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", fr.Status, http.StatusText(fr.Status)),
+		StatusCode: fr.Status,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(fr.Data)),
+		Request:    req,
+	}, nil
+}
+
+// Open returns the stream recorded under key. It can only be opened once.
+// Streams opened after Run returned are at their end at once.
+func (r *Replayer) Open(key string) (Conn, error) {
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.streams[key]; ok {
+		return nil, fmt.Errorf("replay: stream %s is already open", key)
+	}
+	ch := make(chan []byte)
+	if r.done {
+		close(ch)
+		return &replayConn{ch: ch}, nil
+	}
+	r.streams[key] = ch
+	return &replayConn{ch: ch}, nil
+}
+
+// Run delivers the recorded frames and closes every stream when the capture
+// is exhausted or ctx is done. Frames of streams nobody opened are skipped.
+// The clock moves to the time of a frame once its reader took it, so the
+// tickers due at that time fire after the frame and not before.
+func (r *Replayer) Run(ctx context.Context) {
+	defer func() {
+		r.Lock()
+		defer r.Unlock()
+		r.done = true
+		for key, ch := range r.streams {
+			close(ch)
+			delete(r.streams, key)
+		}
+	}()
+
+	var prev int64
+	for i, fr := range r.frames {
+		if r.Speed > 0 && i > 0 && fr.Time > prev {
+			wait := time.Duration(float64(time.Duration(fr.Time-prev)*time.Millisecond) / r.Speed)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+		prev = fr.Time
+
+		r.Lock()
+		ch, ok := r.streams[fr.URL]
+		r.Unlock()
+		if ok {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- fr.Data:
+			}
+		}
+		r.Clock.Advance(time.UnixMilli(fr.Time))
+	}
+}
+
+type replayConn struct {
+	ch chan []byte
+}
+
+func (c *replayConn) ReadMessage() (int, []byte, error) {
+	message, ok := <-c.ch
+	if !ok {
+		return 0, nil, io.EOF
+	}
+	return websocket.TextMessage, message, nil
+}
+
+func (c *replayConn) Close() error {
+	return nil
+}
+
+// Streams lists the streams present in the capture, handy when checking
+// what a file can drive.
+func (r *Replayer) Streams() string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, fr := range r.frames {
+		if !seen[fr.URL] {
+			seen[fr.URL] = true
+			keys = append(keys, fr.URL)
+		}
+	}
+	return strings.Join(keys, ", ")
+}
@@ -0,0 +1,123 @@
+// Package clock is the source of time of the collector. Live runs use the
+// wall clock, replays swap in a SimClock driven by the recorded frames.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for the pipelines.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) *Ticker
+}
+
+// Ticker delivers ticks on C like time.Ticker, for any Clock.
+type Ticker struct {
+	C    <-chan time.Time
+	stop func()
+}
+
+func (t *Ticker) Stop() {
+	t.stop()
+}
+
+var std Clock = wallClock{}
+
+// Set replaces the clock used by Now and NewTicker. It must be called before
+// the pipelines start.
+func Set(c Clock) {
+	std = c
+}
+
+// Now returns the current time of the clock set with Set, the wall clock by
+// default.
+func Now() time.Time {
+	return std.Now()
+}
+
+// NewTicker returns a ticker of the clock set with Set.
+func NewTicker(d time.Duration) *Ticker {
+	return std.NewTicker(d)
+}
+
+type wallClock struct{}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+func (wallClock) NewTicker(d time.Duration) *Ticker {
+	t := time.NewTicker(d)
+	return &Ticker{C: t.C, stop: t.Stop}
+}
+
+type simTicker struct {
+	ch      chan time.Time
+	period  time.Duration
+	next    time.Time
+	stopped bool
+}
+
+// SimClock is a Clock that only moves when Advance is called. Tickers fire
+// when the simulated time passes their next deadline; as with time.Ticker,
+// ticks are dropped for slow receivers.
+type SimClock struct {
+	sync.Mutex
+	now     time.Time
+	tickers []*simTicker
+}
+
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start}
+}
+
+func (c *SimClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *SimClock) NewTicker(d time.Duration) *Ticker {
+	c.Lock()
+	defer c.Unlock()
+	st := &simTicker{
+		ch:     make(chan time.Time, 1),
+		period: d,
+		next:   c.now.Add(d),
+	}
+	c.tickers = append(c.tickers, st)
+	return &Ticker{C: st.ch, stop: func() {
+		c.Lock()
+		defer c.Unlock()
+		st.stopped = true
+	}}
+}
+
+// Advance moves the clock forward to t and fires the tickers that are due.
+// Times before the current one are ignored.
+func (c *SimClock) Advance(t time.Time) {
+	c.Lock()
+	defer c.Unlock()
+	if !t.After(c.now) {
+		return
+	}
+	c.now = t
+	for _, st := range c.tickers {
+		if st.stopped {
+			continue
+		}
+		fired := false
+		for !st.next.After(t) {
+			if !fired {
+				select {
+				case st.ch <- st.next:
+				default:
+				}
+				fired = true
+			}
+			st.next = st.next.Add(st.period)
+		}
+	}
+}
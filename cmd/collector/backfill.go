@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"test.bhft.com/binance"
+	"test.bhft.com/storage"
+)
+
+// backfillPage is the most klines or trades Binance returns per request.
+const backfillPage = 1000
+
+func runBackfill(args []string) error {
+	fs, cfg := newFlagSet("backfill", "backfill [flags] -start 2024-01-01 [-end 2024-02-01]")
+	symbols := fs.String("symbols", "BTCUSDT", "comma separated symbols to backfill")
+	interval := fs.String("interval", "1d", "kline interval")
+	doKlines := fs.Bool("klines", true, "backfill klines")
+	doTrades := fs.Bool("trades", false, "backfill trades")
+	startFlag := fs.String("start", "", "start of the range: a date, an RFC 3339 time or unix milliseconds")
+	endFlag := fs.String("end", "", "end of the range, now by default")
+	rate := fs.Float64("rate", 5, "REST requests per second")
+	if err := cfg.load(fs, args); err != nil {
+		return err
+	}
+	if *startFlag == "" {
+		fs.Usage()
+		return fmt.Errorf("-start is required")
+	}
+	start, err := parseTime(*startFlag)
+	if err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	end := time.Now()
+	if *endFlag != "" {
+		if end, err = parseTime(*endFlag); err != nil {
+			return fmt.Errorf("end: %w", err)
+		}
+	}
+	if start.After(end) {
+		return fmt.Errorf("start is after end")
+	}
+	if *rate <= 0 {
+		return fmt.Errorf("rate must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := cfg.openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	b := &backfiller{
+		client:  cfg.client(nil),
+		db:      db,
+		limiter: time.NewTicker(time.Duration(float64(time.Second) / *rate)),
+	}
+	defer b.limiter.Stop()
+	for _, s := range splitList(*symbols) {
+		venue, symbol := cfg.symbol(s)
+		if venue != "binance" {
+			return fmt.Errorf("%s: backfill only supports binance", s)
+		}
+		if *doKlines {
+			if err := b.klines(ctx, symbol, *interval, start.UnixMilli(), end.UnixMilli()); err != nil {
+				return fmt.Errorf("%s klines: %w", symbol, err)
+			}
+		}
+		if *doTrades {
+			if err := b.trades(ctx, symbol, start.UnixMilli(), end.UnixMilli()); err != nil {
+				return fmt.Errorf("%s trades: %w", symbol, err)
+			}
+		}
+	}
+	return nil
+}
+
+// backfiller pages through the REST history, one request per tick of
+// limiter, and stores every page before asking for the next one.
+type backfiller struct {
+	client  *binance.Client
+	db      *sql.DB
+	limiter *time.Ticker
+}
+
+func (b *backfiller) wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.limiter.C:
+		return nil
+	}
+}
+
+// klines stores the closed klines opened between start and end. Klines
+// already stored are replaced by storage.InsertKlines, which fixes candles
+// stored while still open.
+func (b *backfiller) klines(ctx context.Context, symbol, interval string, start, end int64) error {
+	logger := slog.With("symbol", symbol, "interval", interval)
+	var total int
+	for from := start; from <= end; {
+		if err := b.wait(ctx); err != nil {
+			return err
+		}
+		page, err := b.client.KlinesRange(symbol, interval, from, end, backfillPage)
+		if err != nil {
+			return err
+		}
+		if len(page) == 0 {
+			break
+		}
+		now := time.Now().UnixMilli()
+		closed := page[:0:0]
+		for _, k := range page {
+			if k.CloseTime < now {
+				closed = append(closed, k)
+			}
+		}
+		if err := storage.InsertKlines(b.db, symbol, interval, closed); err != nil {
+			return err
+		}
+		total += len(closed)
+		logger.Debug("backfilled klines page", "from", time.UnixMilli(from), "klines", len(closed))
+		if len(page) < backfillPage {
+			break
+		}
+		from = page[len(page)-1].OpenTime + 1
+	}
+	logger.Info("backfilled klines", "klines", total)
+	return nil
+}
+
+// trades stores the trades made between start and end. The first trade ID
+// is looked up hour by hour from start, then trades are read by ID.
+func (b *backfiller) trades(ctx context.Context, symbol string, start, end int64) error {
+	logger := slog.With("symbol", symbol)
+	var fromID int64
+	found := false
+	for from := start; from <= end && !found; from += time.Hour.Milliseconds() {
+		if err := b.wait(ctx); err != nil {
+			return err
+		}
+		var err error
+		if fromID, found, err = b.client.FirstTradeID(symbol, from); err != nil {
+			return err
+		}
+	}
+	if !found {
+		logger.Info("no trades to backfill")
+		return nil
+	}
+
+	var total int
+	for {
+		if err := b.wait(ctx); err != nil {
+			return err
+		}
+		page, err := b.client.HistoricalTrades(symbol, fromID, backfillPage)
+		if err != nil {
+			return err
+		}
+		done := len(page) < backfillPage
+		for i, t := range page {
+			if t.Time > end {
+				page = page[:i]
+				done = true
+				break
+			}
+		}
+		if len(page) > 0 {
+			if err := storage.InsertTrades(b.db, symbol, page); err != nil {
+				return err
+			}
+			total += len(page)
+			fromID = page[len(page)-1].ID + 1
+			logger.Debug("backfilled trades page", "lastTradeId", fromID-1, "time", time.UnixMilli(page[len(page)-1].Time))
+		}
+		if done {
+			break
+		}
+	}
+	logger.Info("backfilled trades", "trades", total)
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"test.bhft.com/backtest"
+)
+
+func runBacktest(args []string) error {
+	fs, cfg := newFlagSet("backtest", "backtest [flags]")
+	symbol := fs.String("symbol", "BTCUSDT", "symbol to backtest")
+	interval := fs.String("interval", "1h", "kline interval")
+	startFlag := fs.String("start", "", "start of the range: a date, an RFC 3339 time or unix milliseconds, the beginning by default")
+	endFlag := fs.String("end", "", "end of the range, now by default")
+	withTrades := fs.Bool("trades", false, "replay the stored trades too and fill orders on them instead of on the klines")
+	withBooks := fs.Bool("books", false, "replay the stored order book snapshots too")
+	fast := fs.Int("fast", 10, "fast SMA period of the sma-cross strategy")
+	slow := fs.Int("slow", 30, "slow SMA period of the sma-cross strategy")
+	size := fs.Float64("size", 1, "position size in base asset")
+	short := fs.Bool("short", false, "go short on a downward cross instead of flat")
+	var broker backtest.BrokerConfig
+	fs.Float64Var(&broker.InitialCash, "cash", 100000, "initial cash in quote asset")
+	fs.Float64Var(&broker.MakerFeeBps, "maker-fee-bps", 1, "maker fee in bps of the notional")
+	fs.Float64Var(&broker.TakerFeeBps, "taker-fee-bps", 5, "taker fee in bps of the notional")
+	fs.Float64Var(&broker.SlippageBps, "slippage-bps", 1, "slippage of market orders in bps")
+	fs.Float64Var(&broker.Participation, "participation", 0.1, "share of the volume of each kline or trade an order may take, 0 takes all of it")
+	out := fs.String("out", "", "directory to write equity.csv, fills.csv and roundtrips.csv to, empty writes none")
+	if err := cfg.load(fs, args); err != nil {
+		return err
+	}
+	if *fast <= 0 || *slow <= *fast {
+		return fmt.Errorf("fast must be positive and below slow")
+	}
+	_, *symbol = cfg.symbol(*symbol)
+	var start, end int64 = 0, math.MaxInt64
+	if *startFlag != "" {
+		t, err := parseTime(*startFlag)
+		if err != nil {
+			return fmt.Errorf("start: %w", err)
+		}
+		start = t.UnixMilli()
+	}
+	if *endFlag != "" {
+		t, err := parseTime(*endFlag)
+		if err != nil {
+			return fmt.Errorf("end: %w", err)
+		}
+		end = t.UnixMilli()
+	}
+
+	db, err := cfg.openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	data, err := backtest.Load(context.Background(), db, backtest.Query{
+		Symbol: *symbol, Interval: *interval, Start: start, End: end, Trades: *withTrades, Books: *withBooks,
+	})
+	if err != nil {
+		return err
+	}
+	if len(data.Klines) == 0 {
+		return fmt.Errorf("no stored %s klines of %s in the range", *interval, *symbol)
+	}
+	slog.Info("backtest data loaded", "symbol", *symbol, "klines", len(data.Klines), "trades", len(data.Trades), "books", len(data.Books))
+
+	res := backtest.Run(data, backtest.NewSMACross(*fast, *slow, *size, *short), broker)
+	if *out != "" {
+		if err := writeBacktest(*out, res); err != nil {
+			return err
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res.Metrics)
+}
+
+// writeBacktest writes the equity curve, fills and round trips of res as
+// CSV files into dir.
+func writeBacktest(dir string, res backtest.Result) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	i := func(v int64) string { return strconv.FormatInt(v, 10) }
+
+	equity := [][]string{{"time", "price", "position", "cash", "equity"}}
+	for _, p := range res.Equity {
+		equity = append(equity, []string{i(p.Time), f(p.Price), f(p.Position), f(p.Cash), f(p.Equity)})
+	}
+	fills := [][]string{{"order_id", "time", "side", "price", "qty", "fee", "maker"}}
+	for _, x := range res.Fills {
+		fills = append(fills, []string{i(x.OrderID), i(x.Time), x.Side, f(x.Price), f(x.Qty), f(x.Fee), strconv.FormatBool(x.Maker)})
+	}
+	trips := [][]string{{"side", "entry_time", "exit_time", "qty", "entry_price", "exit_price", "pnl"}}
+	for _, t := range res.RoundTrips {
+		trips = append(trips, []string{t.Side, i(t.EntryTime), i(t.ExitTime), f(t.Qty), f(t.EntryPrice), f(t.ExitPrice), f(t.PnL)})
+	}
+	for name, records := range map[string][][]string{"equity.csv": equity, "fills.csv": fills, "roundtrips.csv": trips} {
+		if err := writeCSV(filepath.Join(dir, name), records); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(path string, records [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(file)
+	if err := w.WriteAll(records); err != nil {
+		file.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return file.Close()
+}
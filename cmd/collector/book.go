@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/collector"
+	"test.bhft.com/orderbook"
+)
+
+func runBook(args []string) error {
+	fs, cfg := newFlagSet("book", "book [flags] [VENUE:]SYMBOL")
+	depth := fs.Int("depth", 10, "levels shown per side")
+	refresh := fs.Duration("refresh", time.Second, "how often the ladder is printed")
+	limit := fs.Int("limit", 1000, "levels per side of the REST snapshot the book starts from")
+	redraw := fs.Bool("redraw", true, "clear the terminal before each ladder instead of appending")
+	if err := cfg.load(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one symbol")
+	}
+	if *depth <= 0 || *refresh <= 0 {
+		return fmt.Errorf("depth and refresh must be positive")
+	}
+	name, symbol := cfg.symbol(fs.Arg(0))
+	venue, ok := cfg.venues(nil, nil, nil)[name]
+	if !ok {
+		return fmt.Errorf("unknown venue %q", name)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Without snapshots or an archive the book pipeline never touches the
+	// database, so none is opened.
+	wg := sync.WaitGroup{}
+	ticker := clock.NewTicker(time.Minute)
+	defer ticker.Stop()
+	orderBook, err := collector.HandleOrderBook(ctx, &wg, ticker, venue, symbol, *limit, nil, collector.BookSnapshotConfig{}, collector.BookArchiveConfig{})
+	if err != nil {
+		return err
+	}
+
+	ladder := clock.NewTicker(*refresh)
+	defer ladder.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil
+		case <-ladder.C:
+			if *redraw {
+				fmt.Print("\033[H\033[2J")
+			}
+			printLadder(os.Stdout, orderBook, *depth)
+		}
+	}
+}
+
+// printLadder prints the best depth levels with the asks on top, best ask
+// last, then the spread and the bids, best bid first.
+func printLadder(w io.Writer, orderBook *orderbook.Book, depth int) {
+	s := orderBook.Snapshot(depth)
+	fmt.Fprintf(w, "%s  lastUpdateId %d  synced %t  %s\n", s.Symbol, s.LastUpdateId, orderBook.Synced(), time.UnixMilli(s.Time).Format(time.RFC3339))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "side\tprice\tqty\t")
+	for i := len(s.Asks) - 1; i >= 0; i-- {
+		fmt.Fprintf(tw, "ask\t%s\t%s\t\n", s.Asks[i].Price, s.Asks[i].Quantity)
+	}
+	if bid, ask, ok := orderBook.Top(); ok {
+		mid := (bid + ask) / 2
+		fmt.Fprintf(tw, "spread\t%g\t%.2f bps\t\n", ask-bid, (ask-bid)/mid*10000)
+	}
+	for _, l := range s.Bids {
+		fmt.Fprintf(tw, "bid\t%s\t%s\t\n", l.Price, l.Quantity)
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,517 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"test.bhft.com/alerts"
+	"test.bhft.com/capture"
+	"test.bhft.com/clock"
+	"test.bhft.com/collector"
+	"test.bhft.com/exchange"
+	"test.bhft.com/features"
+	"test.bhft.com/indicators"
+	"test.bhft.com/markets"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/paper"
+	"test.bhft.com/server"
+	"test.bhft.com/tape"
+	"test.bhft.com/telemetry"
+	"test.bhft.com/ticker"
+)
+
+// collectOptions are the flags of the collect and replay subcommands.
+type collectOptions struct {
+	symbols          string
+	feeds            string
+	interval         string
+	bookCfg          collector.BookSnapshotConfig
+	archiveCfg       collector.BookArchiveConfig
+	metricsCfg       orderbook.MetricsConfig
+	sizes            string
+	bps              string
+	levels           string
+	httpAddr         string
+	grpcAddr         string
+	featuresInterval time.Duration
+	healthCfg        server.HealthConfig
+	readyFeeds       string
+	shutdownTimeout  time.Duration
+	// store is off for replays without -store, which then only keep the
+	// live state.
+	store           bool
+	openInterest    time.Duration
+	quoteCfg        collector.QuoteConfig
+	tickers         string
+	windows         string
+	rollingWindows  []time.Duration
+	rollingInterval time.Duration
+	tape            bool
+	tapeCfg         tape.Config
+	alertsPath      string
+	alertsCfg       alerts.Config
+	indicators      string
+	paper           bool
+	paperCfg        paper.Config
+}
+
+func registerCollectFlags(fs *flag.FlagSet) *collectOptions {
+	o := &collectOptions{store: true}
+	fs.StringVar(&o.symbols, "symbols", "BTCUSDT", "comma separated symbols to collect, prefixed with their venue like okx:BTC-USDT when not on -venue")
+	fs.StringVar(&o.feeds, "feeds", "book,trades,klines", "comma separated feeds to collect: book, trades, klines, quotes and, on futures venues, markPrice, openInterest and liquidations")
+	fs.StringVar(&o.interval, "interval", "1d", "kline interval")
+	fs.DurationVar(&o.bookCfg.Interval, "book-snapshot-interval", time.Minute, "how often the order book is stored in postgres, 0 disables snapshots")
+	fs.IntVar(&o.bookCfg.Depth, "book-snapshot-depth", 20, "levels per side stored in each snapshot, 0 stores the full book")
+	fs.DurationVar(&o.bookCfg.WarmStart, "book-warm-start", 0, "start the book from a full depth snapshot not older than this instead of the REST snapshot")
+	fs.BoolVar(&o.archiveCfg.Enabled, "book-archive", false, "store every applied depth diff for order book reconstruction")
+	fs.DurationVar(&o.archiveCfg.FlushInterval, "book-archive-flush", time.Second*5, "how often archived diffs are written to postgres")
+	fs.DurationVar(&o.archiveCfg.AnchorInterval, "book-archive-anchor", time.Minute*10, "how often a full depth anchor snapshot is stored")
+	fs.DurationVar(&o.metricsCfg.Interval, "book-metrics-interval", 0, "how often order book metrics are emitted, 0 disables them")
+	fs.StringVar(&o.sizes, "book-metrics-sizes", "1,5,10", "market order sizes in base asset to price on both sides")
+	fs.StringVar(&o.bps, "book-metrics-bps", "5,10,25", "distances from mid in bps to sum depth within")
+	fs.StringVar(&o.levels, "book-metrics-levels", "5,10,20", "numbers of levels to compute imbalance over")
+	fs.StringVar(&o.httpAddr, "http", "", "address of the JSON query API, for example :8080, empty disables it")
+	fs.StringVar(&o.grpcAddr, "grpc", "", "address of the gRPC market data service, for example :9090, empty disables it")
+	fs.DurationVar(&o.featuresInterval, "features-interval", 0, "how often microstructure features are computed and stored, 0 disables them")
+	fs.DurationVar(&o.healthCfg.StaleAfter, "stale-after", time.Second*30, "a feed without messages for this long makes /readyz fail")
+	fs.StringVar(&o.readyFeeds, "ready-feeds", "", "feeds that must be connected and fresh for /readyz to pass, all collected feeds by default")
+	fs.DurationVar(&o.quoteCfg.Sample, "quote-sample", time.Second, "store at most one best quote per this period, 0 stores every quote change")
+	fs.StringVar(&o.tickers, "tickers", "", "comma separated venue ticker streams to consume: ticker, miniTicker, ticker_1h, ticker_4h or ticker_1d on Binance spot, ticker or miniTicker on futures")
+	fs.StringVar(&o.windows, "rolling-windows", "", "comma separated windows like 15m,1h,24h to compute ticker stats for from the trades feed")
+	fs.DurationVar(&o.rollingInterval, "rolling-interval", time.Second, "how often the local ticker stats are computed")
+	fs.BoolVar(&o.tape, "tape", false, "run the trade tape analytics (volume delta, volume profile, buy and sell flow, large trades) over the trades feed")
+	fs.StringVar(&o.tapeCfg.Interval, "tape-interval", "1m", "interval of the buy and sell flow buckets, written like a kline interval")
+	fs.IntVar(&o.tapeCfg.FlowHistory, "tape-flow-history", 60, "flow buckets kept in memory")
+	fs.DurationVar(&o.tapeCfg.Session, "tape-session", 24*time.Hour, "length of a volume profile session, aligned on UTC midnight")
+	fs.Float64Var(&o.tapeCfg.Tick, "tape-tick", 0, "price bucket of the volume profile, 0 keeps the trade prices")
+	fs.Float64Var(&o.tapeCfg.Large.MinQty, "large-qty", 0, "quantity in base asset from which a single trade is reported as large, 0 disables it")
+	fs.Float64Var(&o.tapeCfg.Large.MinNotional, "large-notional", 0, "notional in quote asset from which a single trade is reported as large, 0 disables it")
+	fs.DurationVar(&o.tapeCfg.Large.BurstWindow, "burst-window", 0, "window within which trades on one side add up to a burst, 0 disables bursts")
+	fs.Float64Var(&o.tapeCfg.Large.BurstQty, "burst-qty", 0, "quantity in base asset from which a burst is reported, 0 disables it")
+	fs.Float64Var(&o.tapeCfg.Large.BurstNotional, "burst-notional", 0, "notional in quote asset from which a burst is reported, 0 disables it")
+	fs.StringVar(&o.indicators, "indicators", "", "comma separated indicators computed over the klines feed, like sma:20,ema:50,rsi:14,macd:12:26:9,bb:20:2,atr:14,vwap,obv")
+	fs.BoolVar(&o.paper, "paper", false, "run a paper trading simulator per symbol, filling orders submitted on the API against the live book and trades")
+	fs.Float64Var(&o.paperCfg.MakerFeeBps, "paper-maker-fee-bps", 1, "fee of the paper fills that rest in the book, in basis points")
+	fs.Float64Var(&o.paperCfg.TakerFeeBps, "paper-taker-fee-bps", 1, "fee of the paper fills that take liquidity, in basis points")
+	fs.StringVar(&o.alertsPath, "alerts", "", "JSON file of alert rules and sinks evaluated against the live markets, empty disables alerts")
+	fs.DurationVar(&o.openInterest, "open-interest-interval", time.Minute, "how often open interest is polled when the openInterest feed is collected")
+	fs.DurationVar(&o.shutdownTimeout, "shutdown-timeout", time.Second*15, "how long pending data may take to flush on shutdown before the collector exits anyway")
+	return o
+}
+
+// collectFeeds are the names -feeds accepts.
+var collectFeeds = []string{
+	telemetry.StreamBook, telemetry.StreamTrades, telemetry.StreamKlines, telemetry.StreamQuotes,
+	telemetry.StreamMarkPrice, telemetry.StreamOpenInterest, telemetry.StreamLiquidations,
+}
+
+// parse checks the list flags once the flag set is parsed.
+func (o *collectOptions) parse() error {
+	for _, feed := range splitList(o.feeds) {
+		if !slices.Contains(collectFeeds, feed) {
+			return fmt.Errorf("unknown feed %q", feed)
+		}
+	}
+	if len(splitList(o.symbols)) == 0 {
+		return fmt.Errorf("no symbols to collect")
+	}
+	o.healthCfg.Required = splitList(o.feeds)
+	if o.readyFeeds != "" {
+		o.healthCfg.Required = splitList(o.readyFeeds)
+		for _, feed := range o.healthCfg.Required {
+			if !o.has(feed) {
+				return fmt.Errorf("ready-feeds: %q is not a collected feed", feed)
+			}
+		}
+	}
+	if o.has(telemetry.StreamOpenInterest) {
+		if o.openInterest <= 0 {
+			return fmt.Errorf("open-interest-interval must be positive")
+		}
+		// A poll every interval is fresh for two intervals.
+		if stale := 2 * o.openInterest; o.healthCfg.StaleAfter > 0 && stale > o.healthCfg.StaleAfter {
+			o.healthCfg.StreamStaleAfter = map[string]time.Duration{telemetry.StreamOpenInterest: stale}
+		}
+	}
+	var err error
+	if o.metricsCfg.Sizes, err = parseFloats(o.sizes); err != nil {
+		return fmt.Errorf("book-metrics-sizes: %w", err)
+	}
+	if o.metricsCfg.Bps, err = parseFloats(o.bps); err != nil {
+		return fmt.Errorf("book-metrics-bps: %w", err)
+	}
+	if o.metricsCfg.Levels, err = parseInts(o.levels); err != nil {
+		return fmt.Errorf("book-metrics-levels: %w", err)
+	}
+	if o.rollingWindows, err = parseDurations(o.windows); err != nil {
+		return fmt.Errorf("rolling-windows: %w", err)
+	}
+	if len(o.rollingWindows) > 0 && !o.has(telemetry.StreamTrades) {
+		return fmt.Errorf("rolling-windows needs the trades feed")
+	}
+	if o.rollingInterval <= 0 {
+		return fmt.Errorf("rolling-interval must be positive")
+	}
+	if o.tape && !o.has(telemetry.StreamTrades) {
+		return fmt.Errorf("tape needs the trades feed")
+	}
+	if _, err := tape.ParseInterval(o.tapeCfg.Interval); err != nil {
+		return fmt.Errorf("tape-interval: %w", err)
+	}
+	if o.tapeCfg.Session <= 0 {
+		return fmt.Errorf("tape-session must be positive")
+	}
+	if o.paper && (!o.has(telemetry.StreamBook) || !o.has(telemetry.StreamTrades)) {
+		return fmt.Errorf("paper needs the book and trades feeds")
+	}
+	if o.indicators != "" {
+		if !o.has(telemetry.StreamKlines) {
+			return fmt.Errorf("indicators need the klines feed")
+		}
+		if _, err := o.newIndicators(); err != nil {
+			return fmt.Errorf("indicators: %w", err)
+		}
+	}
+	if o.alertsPath != "" {
+		if o.alertsCfg, err = alerts.Load(o.alertsPath); err != nil {
+			return fmt.Errorf("alerts: %w", err)
+		}
+	}
+	return nil
+}
+
+// newIndicators builds a fresh set of the -indicators, each symbol needs
+// its own.
+func (o *collectOptions) newIndicators() ([]indicators.Indicator, error) {
+	var res []indicators.Indicator
+	for _, spec := range splitList(o.indicators) {
+		ind, err := indicators.Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, ind)
+	}
+	return res, nil
+}
+
+func (o *collectOptions) has(feed string) bool {
+	return slices.Contains(splitList(o.feeds), feed)
+}
+
+func runCollect(args []string) error {
+	fs, cfg := newFlagSet("collect", "collect [flags]")
+	opts := registerCollectFlags(fs)
+	recordPath := fs.String("record", "", "write every REST response and stream frame to this capture file")
+	if err := cfg.load(fs, args); err != nil {
+		return err
+	}
+	if err := opts.parse(); err != nil {
+		return err
+	}
+
+	var transport http.RoundTripper
+	var recorder *capture.Recorder
+	if *recordPath != "" {
+		r, err := capture.NewRecorder(*recordPath)
+		if err != nil {
+			return fmt.Errorf("record: %w", err)
+		}
+		defer r.Close()
+		recorder = r
+		transport = r.Transport(http.DefaultTransport)
+	}
+	return collect(cfg, opts, cfg.venues(transport, recorder, nil), nil)
+}
+
+func runReplay(args []string) error {
+	fs, cfg := newFlagSet("replay", "replay [flags] capture.jsonl")
+	opts := registerCollectFlags(fs)
+	speed := fs.Float64("speed", 1, "replay pacing: 1 is real time, 10 is ten times faster, 0 is as fast as possible")
+	fs.BoolVar(&opts.store, "store", false, "write the replayed data to Postgres, otherwise the replay runs without a database")
+	if err := cfg.load(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected one capture file")
+	}
+	if err := opts.parse(); err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	r, err := capture.NewReplayer(path, *speed)
+	if err != nil {
+		return fmt.Errorf("replay: %w", err)
+	}
+	slog.Info("replaying capture", "path", path, "streams", r.Streams())
+	clock.Set(r.Clock)
+	return collect(cfg, opts, cfg.venues(r, nil, r), r)
+}
+
+// collect runs the pipelines of every symbol until SIGINT or SIGTERM, or
+// until replayer, when set, has replayed its capture.
+func collect(cfg *config, opts *collectOptions, venues map[string]exchange.Venue, replayer *capture.Replayer) error {
+	type target struct {
+		venue  exchange.Venue
+		symbol string
+	}
+	var targets []target
+	checked := make(map[string]bool)
+	// Storage, the API and the market registry key everything by symbol,
+	// so a symbol is collected from one venue only.
+	seen := make(map[string]string)
+	for _, s := range splitList(opts.symbols) {
+		name, symbol := cfg.symbol(s)
+		venue, ok := venues[name]
+		if !ok {
+			return fmt.Errorf("%s: unknown venue %q", s, name)
+		}
+		if other, ok := seen[strings.ToUpper(symbol)]; ok {
+			return fmt.Errorf("%s: symbol already collected from %s", s, other)
+		}
+		seen[strings.ToUpper(symbol)] = name
+		targets = append(targets, target{venue, symbol})
+		if checked[name] {
+			continue
+		}
+		checked[name] = true
+		if err := venue.Ping(); err != nil {
+			slog.Warn("test connectivity", "venue", name, "err", err)
+		}
+		servertime, err := venue.ServerTime()
+		if err != nil {
+			return fmt.Errorf("%s server time: %w", name, err)
+		}
+		slog.Info("server time", "venue", name, "local", clock.Now(), "server", time.UnixMilli(servertime))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var db *sql.DB
+	var err error
+	if opts.store {
+		if db, err = cfg.openDB(); err != nil {
+			return err
+		}
+		defer db.Close()
+	} else {
+		slog.Info("storage is disabled, only the live state is kept")
+	}
+
+	wg := sync.WaitGroup{}
+	registry := markets.NewRegistry()
+	// Every pipeline gets its own ticker, a tick only reaches one reader.
+	var tickers []*clock.Ticker
+	defer func() {
+		for _, ticker := range tickers {
+			ticker.Stop()
+		}
+	}()
+	newTicker := func() *clock.Ticker {
+		ticker := clock.NewTicker(time.Second * 5)
+		tickers = append(tickers, ticker)
+		return ticker
+	}
+	for _, t := range targets {
+		venue, symbol := t.venue, t.symbol
+		m := &markets.Market{Symbol: symbol, Venue: venue.Name()}
+		if opts.has(telemetry.StreamBook) {
+			if m.Book, err = collector.HandleOrderBook(ctx, &wg, newTicker(), venue, symbol, 100, db, opts.bookCfg, opts.archiveCfg); err != nil {
+				return fmt.Errorf("%s order book: %w", symbol, err)
+			}
+			m.Feeds = append(m.Feeds, telemetry.StreamBook)
+		}
+		if opts.has(telemetry.StreamTrades) {
+			if m.Trades, err = collector.HandleTrades(ctx, &wg, newTicker(), venue, symbol, 100); err != nil {
+				return fmt.Errorf("%s trades: %w", symbol, err)
+			}
+			m.Feeds = append(m.Feeds, telemetry.StreamTrades)
+		}
+		if opts.has(telemetry.StreamKlines) {
+			if m.Klines, err = collector.HandleKlines(ctx, &wg, newTicker(), venue, symbol, opts.interval, 100, db); err != nil {
+				return fmt.Errorf("%s klines: %w", symbol, err)
+			}
+			m.Feeds = append(m.Feeds, telemetry.StreamKlines)
+		}
+		if opts.has(telemetry.StreamQuotes) {
+			if feed, ok := venue.(exchange.QuoteFeed); ok {
+				if m.Quotes, err = collector.HandleQuotes(ctx, &wg, newTicker(), feed, symbol, db, opts.quoteCfg); err != nil {
+					return fmt.Errorf("%s quotes: %w", symbol, err)
+				}
+				m.Feeds = append(m.Feeds, telemetry.StreamQuotes)
+			} else {
+				slog.Info("venue has no quote feed, skipping it", "venue", venue.Name(), "symbol", symbol)
+			}
+		}
+		if err := collectTickers(ctx, &wg, venue, m, opts); err != nil {
+			return fmt.Errorf("%s: %w", symbol, err)
+		}
+		if opts.indicators != "" {
+			list, err := opts.newIndicators()
+			if err != nil {
+				return err
+			}
+			m.Indicators = indicators.NewSet(symbol, opts.interval, list)
+			collector.RunIndicators(ctx, &wg, newTicker(), m.Klines, m.Indicators, db)
+		}
+		if opts.tape {
+			if m.Tape, err = tape.NewAnalyzer(symbol, opts.tapeCfg); err != nil {
+				return fmt.Errorf("%s tape: %w", symbol, err)
+			}
+			collector.RunTape(ctx, &wg, newTicker(), m.Trades, m.Tape, db)
+		}
+		if opts.paper {
+			m.Paper = paper.NewSimulator(symbol, m.Book, opts.paperCfg)
+			collector.RunPaper(ctx, &wg, m.Book, m.Trades, m.Paper)
+		}
+		if err := collectDerivatives(ctx, &wg, newTicker, venue, m, db, opts); err != nil {
+			return fmt.Errorf("%s: %w", symbol, err)
+		}
+		registry.Add(m)
+
+		if opts.featuresInterval > 0 && m.Book != nil && m.Trades != nil {
+			engine := features.NewEngine(m.Book, m.Trades)
+			collector.RunFeatures(ctx, &wg, engine, db, opts.featuresInterval)
+		}
+		if opts.metricsCfg.Interval > 0 && m.Book != nil {
+			logBookMetrics(orderbook.StreamMetrics(ctx, &wg, m.Book, opts.metricsCfg))
+		}
+	}
+
+	if opts.alertsPath != "" {
+		engine, err := alerts.NewEngine(opts.alertsCfg, registry)
+		if err != nil {
+			return fmt.Errorf("alerts: %w", err)
+		}
+		engine.Run(ctx, &wg)
+		slog.Info("evaluating alert rules", "rules", len(opts.alertsCfg.Rules), "sinks", len(opts.alertsCfg.Sinks))
+	}
+
+	if opts.httpAddr != "" {
+		api := server.NewAPIServer(registry, db)
+		hub := server.NewHub(registry)
+		hub.Run(ctx, &wg)
+		api.Handle("GET /ws", hub)
+		api.Handle("GET /metrics", promhttp.Handler())
+		healthServer := server.NewHealthServer(registry, opts.healthCfg)
+		for _, pattern := range healthServer.Patterns() {
+			api.Handle(pattern, healthServer)
+		}
+		api.Handle("/grafana/", http.StripPrefix("/grafana", server.NewGrafanaDatasource(registry, db)))
+		api.Run(ctx, &wg, opts.httpAddr)
+	}
+
+	if opts.grpcAddr != "" {
+		if err := server.NewGRPCServer(registry, db).Run(ctx, &wg, opts.grpcAddr); err != nil {
+			return fmt.Errorf("grpc server: %w", err)
+		}
+	}
+
+	if replayer != nil {
+		go func() {
+			replayer.Run(ctx)
+			slog.Info("replay is finished")
+			stop()
+		}()
+	}
+
+	<-ctx.Done()
+	slog.Info("shutting down")
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		slog.Info("everything is finished")
+		return nil
+	case <-time.After(opts.shutdownTimeout):
+		return fmt.Errorf("shutdown deadline of %s exceeded, data not flushed yet is lost", opts.shutdownTimeout)
+	}
+}
+
+// collectDerivatives runs the futures only feeds of m and adds them to its
+// feeds. Venues without them skip those feeds, so spot and futures symbols
+// can share -feeds. newTicker makes the ticker of a pipeline.
+func collectDerivatives(ctx context.Context, wg *sync.WaitGroup, newTicker func() *clock.Ticker, venue exchange.Venue, m *markets.Market, db *sql.DB, opts *collectOptions) error {
+	if !opts.has(telemetry.StreamMarkPrice) && !opts.has(telemetry.StreamOpenInterest) && !opts.has(telemetry.StreamLiquidations) {
+		return nil
+	}
+	feed, ok := venue.(exchange.DerivativesFeed)
+	if !ok {
+		slog.Info("venue has no futures feeds, skipping them", "venue", venue.Name(), "symbol", m.Symbol)
+		return nil
+	}
+	if opts.has(telemetry.StreamMarkPrice) {
+		if err := collector.HandleMarkPrice(ctx, wg, newTicker(), feed, m.Symbol, db); err != nil {
+			return err
+		}
+		m.Feeds = append(m.Feeds, telemetry.StreamMarkPrice)
+	}
+	if opts.has(telemetry.StreamOpenInterest) {
+		collector.PollOpenInterest(ctx, wg, feed, venue.Name(), m.Symbol, db, opts.openInterest)
+		m.Feeds = append(m.Feeds, telemetry.StreamOpenInterest)
+	}
+	if opts.has(telemetry.StreamLiquidations) {
+		if err := collector.HandleLiquidations(ctx, wg, feed, m.Symbol, db); err != nil {
+			return err
+		}
+		m.Feeds = append(m.Feeds, telemetry.StreamLiquidations)
+	}
+	return nil
+}
+
+// collectTickers runs the venue ticker streams and the local ticker stats of
+// m. Venues without ticker streams only get the local stats.
+func collectTickers(ctx context.Context, wg *sync.WaitGroup, venue exchange.Venue, m *markets.Market, opts *collectOptions) error {
+	kinds := splitList(opts.tickers)
+	if len(kinds) == 0 && len(opts.rollingWindows) == 0 {
+		return nil
+	}
+	m.Tickers = ticker.NewBoard(m.Symbol)
+	if len(kinds) > 0 {
+		if feed, ok := venue.(exchange.TickerFeed); ok {
+			for _, kind := range kinds {
+				if err := collector.HandleTicker(ctx, wg, feed, m.Symbol, kind, m.Tickers); err != nil {
+					return err
+				}
+			}
+		} else {
+			slog.Info("venue has no ticker streams, skipping them", "venue", venue.Name(), "symbol", m.Symbol)
+		}
+	}
+	if len(opts.rollingWindows) > 0 {
+		collector.RunRollingStats(ctx, wg, m.Trades, m.Tickers, opts.rollingWindows, opts.rollingInterval)
+	}
+	return nil
+}
+
+func logBookMetrics(metrics <-chan orderbook.Metrics) {
+	go func() {
+		for m := range metrics {
+			b, err := json.Marshal(m)
+			if err != nil {
+				slog.Error("book metrics", "err", err)
+				continue
+			}
+			slog.Info("book metrics", "symbol", m.Symbol, "metrics", json.RawMessage(b))
+		}
+	}()
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"test.bhft.com/binance"
+	"test.bhft.com/capture"
+	"test.bhft.com/exchange"
+	"test.bhft.com/okx"
+	"test.bhft.com/storage"
+	"test.bhft.com/telemetry"
+)
+
+// defaultPostgres is used when neither -postgres nor BHFT_POSTGRES is set.
+const defaultPostgres = "host=localhost port=5432 user=postgres password=postgres dbname=bhft_test sslmode=disable"
+
+// config holds the settings every subcommand shares: where the venues and
+// Postgres are and how to log.
+type config struct {
+	Postgres         string
+	Venue            string
+	DepthMode        string
+	BaseURL          string
+	StreamURL        string
+	FuturesBaseURL   string
+	FuturesStreamURL string
+	OKXBaseURL       string
+	OKXPublicURL     string
+	OKXBusinessURL   string
+	Log              telemetry.LogConfig
+}
+
+// newFlagSet returns the flag set of a subcommand with the shared flags
+// already registered into the returned config.
+func newFlagSet(name, usage string) (*flag.FlagSet, *config) {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: collector %s\n\n", usage)
+		fs.PrintDefaults()
+	}
+	cfg := &config{}
+	postgres := os.Getenv("BHFT_POSTGRES")
+	if postgres == "" {
+		postgres = defaultPostgres
+	}
+	fs.StringVar(&cfg.Postgres, "postgres", postgres, "postgres connection string, BHFT_POSTGRES by default")
+	fs.StringVar(&cfg.Venue, "venue", "binance", "venue of the symbols written without one: binance, binance-futures or okx")
+	fs.StringVar(&cfg.DepthMode, "book-mode", "depth", "Binance depth stream: depth for diffs, depth5, depth10 or depth20 for partial books, optionally with a speed like @100ms")
+	fs.StringVar(&cfg.BaseURL, "binance-api", binance.DefaultBaseURL, "Binance REST API base URL")
+	fs.StringVar(&cfg.StreamURL, "binance-ws", binance.DefaultStreamURL, "Binance websocket streams base URL")
+	fs.StringVar(&cfg.FuturesBaseURL, "binance-futures-api", binance.DefaultFuturesBaseURL, "Binance USDⓈ-M futures REST API base URL")
+	fs.StringVar(&cfg.FuturesStreamURL, "binance-futures-ws", binance.DefaultFuturesStreamURL, "Binance USDⓈ-M futures websocket streams base URL")
+	fs.StringVar(&cfg.OKXBaseURL, "okx-api", okx.DefaultBaseURL, "OKX REST API base URL")
+	fs.StringVar(&cfg.OKXPublicURL, "okx-ws", okx.DefaultPublicURL, "OKX public websocket URL")
+	fs.StringVar(&cfg.OKXBusinessURL, "okx-ws-business", okx.DefaultBusinessURL, "OKX business websocket URL, used for candles")
+	fs.StringVar(&cfg.Log.Level, "log-level", "info", "minimum log level: debug, info, warn or error")
+	fs.StringVar(&cfg.Log.Format, "log-format", "text", "log output format: text or json")
+	fs.IntVar(&cfg.Log.SampleEvery, "log-sample", 0, "log every n-th raw stream event at debug level, 0 disables raw event logs")
+	return fs, cfg
+}
+
+// load parses args into fs and sets up logging.
+func (c *config) load(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if _, _, err := binance.ParseDepthMode(c.DepthMode); err != nil {
+		return fmt.Errorf("book-mode: %w", err)
+	}
+	return telemetry.SetupLogging(c.Log)
+}
+
+// client returns a Binance client whose REST requests go through transport,
+// http.DefaultTransport when nil, and count towards the weight metric.
+func (c *config) client(transport http.RoundTripper) *binance.Client {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	client := binance.NewClient(&http.Client{
+		Timeout:   time.Second * 5,
+		Transport: &telemetry.WeightTransport{Next: transport},
+	})
+	client.BaseURL = c.BaseURL
+	client.StreamURL = c.StreamURL
+	client.DepthMode = c.DepthMode
+	return client
+}
+
+// venues returns every venue by name. They share transport, recorder and
+// replayer, so one capture holds all of them.
+func (c *config) venues(transport http.RoundTripper, recorder *capture.Recorder, replayer *capture.Replayer) map[string]exchange.Venue {
+	binanceClient := c.client(transport)
+	binanceClient.Recorder = recorder
+	binanceClient.Replayer = replayer
+
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	futuresClient := binance.NewFutures(&http.Client{
+		Timeout:   time.Second * 5,
+		Transport: &telemetry.WeightTransport{Next: transport},
+	})
+	futuresClient.BaseURL = c.FuturesBaseURL
+	futuresClient.StreamURL = c.FuturesStreamURL
+	futuresClient.DepthMode = c.DepthMode
+	futuresClient.Recorder = recorder
+	futuresClient.Replayer = replayer
+
+	okxClient := okx.NewClient(&http.Client{Timeout: time.Second * 5, Transport: transport})
+	okxClient.BaseURL = c.OKXBaseURL
+	okxClient.PublicURL = c.OKXPublicURL
+	okxClient.BusinessURL = c.OKXBusinessURL
+	okxClient.Recorder = recorder
+	okxClient.Replayer = replayer
+
+	return map[string]exchange.Venue{
+		binanceClient.Name(): binanceClient,
+		futuresClient.Name(): futuresClient,
+		okxClient.Name():     okxClient,
+	}
+}
+
+// symbol splits a symbol written as venue:SYMBOL, like okx:BTC-USDT or
+// binance-futures:BTCUSDT_PERP. Symbols without a venue are on -venue.
+func (c *config) symbol(s string) (venue, symbol string) {
+	venue, symbol, ok := strings.Cut(s, ":")
+	if !ok {
+		venue, symbol = c.Venue, s
+	}
+	return strings.ToLower(venue), strings.ToUpper(symbol)
+}
+
+// openDB connects to Postgres and runs the migration.
+func (c *config) openDB() (*sql.DB, error) {
+	db, err := storage.Open(c.Postgres)
+	if err != nil {
+		return nil, fmt.Errorf("connect postgres: %w", err)
+	}
+	if err := storage.Migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migration: %w", err)
+	}
+	slog.Info("connected to postgres")
+	return db, nil
+}
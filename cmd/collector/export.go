@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"test.bhft.com/export"
+	"test.bhft.com/storage"
+)
+
+// exportPage is how many rows are read from Postgres at once.
+const exportPage = 10000
+
+func runExport(args []string) error {
+	fs, cfg := newFlagSet("export", "export [flags] klines|trades")
+	symbol := fs.String("symbol", "BTCUSDT", "symbol to export")
+	interval := fs.String("interval", "1d", "kline interval")
+	startFlag := fs.String("start", "", "start of the range: a date, an RFC 3339 time or unix milliseconds, the beginning by default")
+	endFlag := fs.String("end", "", "end of the range, now by default")
+	out := fs.String("out", "-", "output file, - writes to stdout")
+	format := fs.String("format", "", "csv or parquet, taken from the extension of -out by default and csv for stdout")
+	if err := cfg.load(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("expected klines or trades")
+	}
+	// Stored rows are keyed by the symbol alone.
+	_, *symbol = cfg.symbol(*symbol)
+	what := fs.Arg(0)
+	if what != "klines" && what != "trades" {
+		return fmt.Errorf("cannot export %q, expected klines or trades", what)
+	}
+	var start, end int64 = 0, math.MaxInt64
+	if *startFlag != "" {
+		t, err := parseTime(*startFlag)
+		if err != nil {
+			return fmt.Errorf("start: %w", err)
+		}
+		start = t.UnixMilli()
+	}
+	if *endFlag != "" {
+		t, err := parseTime(*endFlag)
+		if err != nil {
+			return fmt.Errorf("end: %w", err)
+		}
+		end = t.UnixMilli()
+	}
+	if *format == "" {
+		*format = export.CSV
+		if ext := strings.TrimPrefix(filepath.Ext(*out), "."); *out != "-" && ext == export.Parquet {
+			*format = ext
+		}
+	}
+
+	db, err := cfg.openDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var dst io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		dst = f
+	}
+	bw := bufio.NewWriter(dst)
+	w, err := export.NewWriter(bw, *format)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	var rows int
+	switch what {
+	case "klines":
+		for from := start; ; {
+			page, err := storage.QueryKlines(ctx, db, *symbol, *interval, from, end, exportPage)
+			if err != nil {
+				return err
+			}
+			if err := w.WriteKlines(*symbol, *interval, page); err != nil {
+				return err
+			}
+			rows += len(page)
+			if len(page) < exportPage {
+				break
+			}
+			from = page[len(page)-1].OpenTime + 1
+		}
+	case "trades":
+		for fromID := int64(0); ; {
+			page, err := storage.QueryTrades(ctx, db, *symbol, fromID, start, end, exportPage)
+			if err != nil {
+				return err
+			}
+			if err := w.WriteTrades(*symbol, page); err != nil {
+				return err
+			}
+			rows += len(page)
+			if len(page) < exportPage {
+				break
+			}
+			fromID = page[len(page)-1].ID + 1
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	slog.Info("exported", "what", what, "symbol", *symbol, "rows", rows, "format", *format, "out", *out)
+	return nil
+}
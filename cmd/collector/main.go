@@ -0,0 +1,136 @@
+// Command collector ingests Binance and OKX market data into Postgres and
+// serves it.
+// Run it with a subcommand:
+//
+//	collector collect   live ingestion of the chosen symbols and feeds
+//	collector backfill  historical klines and trades over REST
+//	collector export    stored klines or trades to CSV or Parquet
+//	collector replay    the collect pipelines fed from a capture file
+//	collector book      a live top-N ladder of one symbol
+//	collector backtest  a strategy replayed on stored data
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var commands = []struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}{
+	{"collect", "run the live pipelines and store them", runCollect},
+	{"backfill", "store historical klines and trades", runBackfill},
+	{"export", "write stored klines or trades as CSV or Parquet", runExport},
+	{"replay", "run the pipelines from a capture file", runReplay},
+	{"book", "print a live order book ladder", runBook},
+	{"backtest", "run a strategy on stored klines and trades", runBacktest},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	for _, c := range commands {
+		if c.name == name {
+			if err := c.run(os.Args[2:]); err != nil {
+				fatal(name, err)
+			}
+			return
+		}
+	}
+	usage()
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		return
+	}
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: collector <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run collector <command> -h for the flags of a command.")
+}
+
+// splitList splits a comma separated flag, dropping empty items.
+func splitList(s string) []string {
+	var res []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+func parseFloats(s string) ([]float64, error) {
+	var res []float64
+	for _, f := range splitList(s) {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func parseInts(s string) ([]int, error) {
+	var res []int
+	for _, f := range splitList(s) {
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func parseDurations(s string) ([]time.Duration, error) {
+	var res []time.Duration
+	for _, f := range splitList(s) {
+		d, err := time.ParseDuration(f)
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("%s is not positive", f)
+		}
+		res = append(res, d)
+	}
+	return res, nil
+}
+
+// parseTime accepts a date, an RFC 3339 time or unix milliseconds. Dates
+// are in UTC like the exchange timestamps.
+func parseTime(s string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date, an RFC 3339 time or unix milliseconds", s)
+	}
+	return t, nil
+}
+
+// fatal logs err and exits, it is only meant for main.
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
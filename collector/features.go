@@ -0,0 +1,41 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"test.bhft.com/features"
+	"test.bhft.com/storage"
+	"test.bhft.com/telemetry"
+)
+
+// RunFeatures computes the features of the engine every interval and stores
+// them until ctx is done.
+func RunFeatures(ctx context.Context, wg *sync.WaitGroup, engine *features.Engine, db *sql.DB, interval time.Duration) {
+	ch, unsubscribe := engine.Subscribe(16)
+	engine.Run(ctx, wg, interval)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case f := <-ch:
+				if db == nil {
+					continue
+				}
+				start := time.Now()
+				err := storage.InsertFeatures(db, f)
+				telemetry.ObserveInsert("features", f.Symbol, start, err)
+				if err != nil {
+					slog.Error("insert features", "symbol", f.Symbol, "err", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,137 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/exchange"
+	"test.bhft.com/futures"
+	"test.bhft.com/storage"
+	"test.bhft.com/telemetry"
+)
+
+// HandleMarkPrice streams the mark price and funding rate of symbol until
+// ctx is done and stores the updates received on every tick. Once the
+// reader closes the stream the remaining updates are stored.
+func HandleMarkPrice(ctx context.Context, wg *sync.WaitGroup, ticker *clock.Ticker, feed exchange.DerivativesFeed, symbol string, db *sql.DB) error {
+	ch, err := feed.MarkPriceStream(ctx, wg, symbol)
+	if err != nil {
+		return fmt.Errorf("mark price stream: %w", err)
+	}
+
+	logger := slog.With("stream", telemetry.StreamMarkPrice, "symbol", symbol)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var pending []futures.MarkPrice
+		for {
+			select {
+			case m, ok := <-ch:
+				if !ok {
+					if err := storeMarkPrices(db, pending); err != nil {
+						logger.Error("mark prices lost", "updates", len(pending), "err", err)
+					} else {
+						logger.Info("flushed mark prices", "updates", len(pending))
+					}
+					logger.Info("mark price flow is finished")
+					return
+				}
+				pending = append(pending, m)
+			case <-ticker.C:
+				if err := storeMarkPrices(db, pending); err != nil {
+					logger.Error("insert mark prices", "updates", len(pending), "err", err)
+					continue
+				}
+				pending = pending[:0]
+			}
+		}
+	}()
+	return nil
+}
+
+func storeMarkPrices(db *sql.DB, list []futures.MarkPrice) error {
+	if db == nil || len(list) == 0 {
+		return nil
+	}
+	start := time.Now()
+	err := storage.InsertMarkPrices(db, list)
+	telemetry.ObserveInsert("mark_prices", list[0].Symbol, start, err)
+	return err
+}
+
+// PollOpenInterest stores the open interest of symbol now and every
+// interval until ctx is done. Each poll is reported to the health tracker
+// as a message of the openInterest feed of venue, a failed one as a
+// disconnect.
+func PollOpenInterest(ctx context.Context, wg *sync.WaitGroup, feed exchange.DerivativesFeed, venue, symbol string, db *sql.DB, interval time.Duration) {
+	logger := slog.With("symbol", symbol)
+	ticker := clock.NewTicker(interval)
+	poll := func() {
+		oi, err := feed.OpenInterest(symbol)
+		if err != nil {
+			telemetry.Health.Disconnected(venue, telemetry.StreamOpenInterest, symbol)
+			logger.Error("get open interest", "err", err)
+			return
+		}
+		telemetry.Health.Connected(venue, telemetry.StreamOpenInterest, symbol)
+		telemetry.ObserveMessage(venue, telemetry.StreamOpenInterest, symbol)
+		if db == nil {
+			return
+		}
+		start := time.Now()
+		err = storage.InsertOpenInterest(db, oi)
+		telemetry.ObserveInsert("open_interest", symbol, start, err)
+		if err != nil {
+			logger.Error("insert open interest", "err", err)
+		}
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer ticker.Stop()
+		poll()
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("open interest polling is finished")
+				return
+			case <-ticker.C:
+				poll()
+			}
+		}
+	}()
+}
+
+// HandleLiquidations streams the liquidation orders of symbol until ctx is
+// done and stores each one as it arrives.
+func HandleLiquidations(ctx context.Context, wg *sync.WaitGroup, feed exchange.DerivativesFeed, symbol string, db *sql.DB) error {
+	ch, err := feed.LiquidationStream(ctx, wg, symbol)
+	if err != nil {
+		return fmt.Errorf("liquidation stream: %w", err)
+	}
+
+	logger := slog.With("stream", telemetry.StreamLiquidations, "symbol", symbol)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for l := range ch {
+			logger.Debug("liquidation", "side", l.Side, "qty", l.Quantity, "avgPrice", l.AveragePrice)
+			if db == nil {
+				continue
+			}
+			start := time.Now()
+			err := storage.InsertLiquidation(db, l)
+			telemetry.ObserveInsert("liquidations", symbol, start, err)
+			if err != nil {
+				logger.Error("insert liquidation", "err", err)
+			}
+		}
+		logger.Info("liquidation flow is finished")
+	}()
+	return nil
+}
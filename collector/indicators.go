@@ -0,0 +1,90 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/indicators"
+	"test.bhft.com/klines"
+	"test.bhft.com/storage"
+	"test.bhft.com/telemetry"
+)
+
+// RunIndicators runs set over the klines of list until ctx is done. It
+// starts from the klines already loaded, then follows the kline updates,
+// and stores the closed points on every tick and on shutdown. The update
+// feed drops updates when it falls behind, so when a newer candle opens the
+// candles before it are closed from list, in case their closing update was
+// dropped.
+func RunIndicators(ctx context.Context, wg *sync.WaitGroup, ticker *clock.Ticker, list *klines.List, set *indicators.Set, db *sql.DB) {
+	logger := slog.With("symbol", set.Symbol, "interval", set.Interval)
+
+	// Updates are published under the lock, so subscribing before it is
+	// released neither misses one nor applies one twice.
+	var pending []indicators.Point
+	var open int64
+	list.Lock()
+	now := clock.Now().UnixMilli()
+	for _, k := range list.List {
+		pending = append(pending, set.Update(k, k.CloseTime < now)...)
+		open = k.OpenTime
+	}
+	ch, unsubscribe := list.Subscribe(1000)
+	list.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				if err := storeIndicators(db, set, pending); err != nil {
+					logger.Error("indicator values lost", "points", len(pending), "err", err)
+				}
+				logger.Info("indicator flow is finished")
+				return
+			case u := <-ch:
+				if u.Kline.OpenTime > open {
+					pending = append(pending, closeBefore(list, set, u.Kline.OpenTime)...)
+					open = u.Kline.OpenTime
+				}
+				pending = append(pending, set.Update(u.Kline, u.Closed)...)
+			case <-ticker.C:
+				if err := storeIndicators(db, set, pending); err != nil {
+					logger.Error("insert indicator values", "points", len(pending), "err", err)
+					continue
+				}
+				pending = pending[:0]
+			}
+		}
+	}()
+}
+
+// closeBefore feeds set the candles of list opened before openTime as
+// closed. Set skips the ones it already closed.
+func closeBefore(list *klines.List, set *indicators.Set, openTime int64) []indicators.Point {
+	list.Lock()
+	defer list.Unlock()
+	var points []indicators.Point
+	for _, k := range list.List {
+		if k.OpenTime < openTime {
+			points = append(points, set.Update(k, true)...)
+		}
+	}
+	return points
+}
+
+func storeIndicators(db *sql.DB, set *indicators.Set, points []indicators.Point) error {
+	if db == nil || len(points) == 0 {
+		return nil
+	}
+	start := time.Now()
+	err := storage.UpsertIndicators(db, set.Symbol, set.Interval, points)
+	telemetry.ObserveInsert("indicator_values", set.Symbol, start, err)
+	return err
+}
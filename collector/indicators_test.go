@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/indicators"
+	"test.bhft.com/klines"
+)
+
+// TestIndicatorsCloseMissedCandle checks a candle whose closing update was
+// dropped is closed once the next candle opens.
+func TestIndicatorsCloseMissedCandle(t *testing.T) {
+	list := &klines.List{Symbol: "BTCUSDT", Interval: "1m"}
+	set := indicators.NewSet("BTCUSDT", "1m", []indicators.Indicator{indicators.NewSMA(1)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	ticker := clock.NewTicker(time.Hour)
+	defer ticker.Stop()
+	RunIndicators(ctx, &wg, ticker, list, set, nil)
+
+	kline := func(open int64, close string) klines.Update {
+		return klines.Update{Kline: klines.Kline{OpenTime: open, CloseTime: open + 59_999, Open: "10", High: "12", Low: "9", Close: close, Volume: "1"}}
+	}
+	list.Update(kline(0, "10"))
+	list.Update(kline(0, "11"))
+	// The closing update of the first candle never comes.
+	list.Update(kline(60_000, "12"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var closed, live []indicators.Point
+		for _, p := range set.State() {
+			if p.Closed {
+				closed = append(closed, p)
+			} else {
+				live = append(live, p)
+			}
+		}
+		if len(closed) == 1 && len(live) == 1 {
+			if closed[0].OpenTime != 0 || closed[0].Value["value"] != 11 || live[0].OpenTime != 60_000 {
+				t.Fatalf("closed %+v live %+v, want the candle at 0 closed and the one at 60000 live", closed, live)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("state %+v, want the first candle closed", set.State())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,76 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/exchange"
+	"test.bhft.com/klines"
+	"test.bhft.com/storage"
+	"test.bhft.com/telemetry"
+)
+
+// HandleKlines loads the latest klines of symbol and interval, keeps them up
+// to date from the kline stream of feed until ctx is done and stores new
+// ones on every tick.
+func HandleKlines(ctx context.Context, wg *sync.WaitGroup, ticker *clock.Ticker, feed exchange.KlineFeed, symbol, interval string, limit int, db *sql.DB) (*klines.List, error) {
+	klineList, err := feed.Klines(symbol, interval, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get klines: %w", err)
+	}
+	slog.Info("kline list loaded", "stream", telemetry.StreamKlines, "symbol", klineList.Symbol, "interval", klineList.Interval, "klines", len(klineList.List))
+
+	ch, err := feed.KlineStream(ctx, wg, symbol, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	updateKlines(klineList, ch, wg, ticker, db)
+	return klineList, nil
+}
+
+// updateKlines applies the kline updates of ch and stores new klines on
+// every tick. Once the reader closes ch the remaining klines are stored.
+func updateKlines(klineList *klines.List, ch chan klines.Update, wg *sync.WaitGroup, ticker *clock.Ticker, db *sql.DB) {
+	logger := slog.With("stream", telemetry.StreamKlines, "symbol", klineList.Symbol, "interval", klineList.Interval)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					newklines := klineList.GetToInsert()
+					if err := storeKlines(db, klineList, newklines); err != nil {
+						logger.Error("klines lost", "klines", len(newklines), "err", err)
+					} else {
+						logger.Info("flushed klines", "klines", len(newklines))
+					}
+					logger.Info("kline flow is finished")
+					return
+				}
+				klineList.Update(v)
+			case <-ticker.C:
+				newklines := klineList.GetToInsert()
+				if err := storeKlines(db, klineList, newklines); err != nil {
+					logger.Error("insert klines", "klines", len(newklines), "err", err)
+				}
+			}
+		}
+	}()
+}
+
+func storeKlines(db *sql.DB, klineList *klines.List, list []klines.Kline) error {
+	if db == nil {
+		return nil
+	}
+	start := time.Now()
+	err := storage.InsertKlines(db, klineList.Symbol, klineList.Interval, list)
+	telemetry.ObserveInsert("klines", klineList.Symbol, start, err)
+	return err
+}
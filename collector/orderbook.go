@@ -0,0 +1,299 @@
+// Package collector runs the pipelines that keep the live state of a market
+// from the streams of a venue and persist it to Postgres. With a nil db the
+// pipelines only keep the live state, as replays without storage do.
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/exchange"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/storage"
+	"test.bhft.com/telemetry"
+)
+
+const (
+	// resyncMin and resyncMax bound the wait between two resyncs of a book.
+	resyncMin = time.Second
+	resyncMax = time.Minute
+)
+
+// BookSnapshotConfig controls how the order book is persisted. Interval 0
+// disables snapshots, Depth 0 stores the full book. A positive WarmStart
+// lets the book start from the latest full depth snapshot younger than
+// WarmStart instead of the REST snapshot.
+type BookSnapshotConfig struct {
+	Interval  time.Duration
+	Depth     int
+	WarmStart time.Duration
+}
+
+// BookArchiveConfig controls the L2 diff archive. Every applied depth diff
+// is stored gzipped together with its update IDs, and a full depth anchor
+// snapshot is stored every AnchorInterval so storage.BookAt has a starting
+// point.
+type BookArchiveConfig struct {
+	Enabled        bool
+	FlushInterval  time.Duration
+	AnchorInterval time.Duration
+}
+
+// HandleOrderBook loads the book of symbol, keeps it in sync with the book
+// stream of feed until ctx is done and persists it as configured. When feed
+// is an exchange.BookVerifier the book is checked against every checksum.
+// A book that falls out of sync is loaded again from the REST snapshot, or
+// subscribed again when feed is an exchange.BookResubscriber.
+func HandleOrderBook(ctx context.Context, wg *sync.WaitGroup, ticker *clock.Ticker, feed exchange.BookFeed, symbol string, limit int, db *sql.DB, cfg BookSnapshotConfig, archiveCfg BookArchiveConfig) (*orderbook.Book, error) {
+	logger := slog.With("stream", telemetry.StreamBook, "symbol", symbol)
+	var orderBook *orderbook.Book
+	if cfg.WarmStart > 0 && db != nil {
+		snapshot, err := storage.LoadLatestOrderBookSnapshot(db, symbol, clock.Now().Add(-cfg.WarmStart))
+		if err != nil {
+			logger.Error("warm start order book", "err", err)
+		}
+		if snapshot != nil {
+			logger.Info("order book warm start from snapshot", "lastUpdateId", snapshot.LastUpdateId, "snapshotTime", time.UnixMilli(snapshot.Time))
+			orderBook = orderbook.FromSnapshot(*snapshot)
+		}
+	}
+	if orderBook == nil {
+		var err error
+		orderBook, err = feed.OrderBook(symbol, limit)
+		if err != nil {
+			return nil, fmt.Errorf("get order book: %w", err)
+		}
+	}
+	logger.Info("order book loaded", "lastUpdateId", orderBook.LastUpdateId, "bids", len(orderBook.Bids), "asks", len(orderBook.Asks))
+
+	ch, err := feed.BookStream(ctx, wg, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("order book stream: %w", err)
+	}
+
+	var archive *storage.BookArchive
+	if archiveCfg.Enabled && db != nil {
+		archive = storage.NewBookArchive(db, orderBook.Symbol)
+	}
+	verifier, _ := feed.(exchange.BookVerifier)
+	resync := func() error {
+		if r, ok := feed.(exchange.BookResubscriber); ok {
+			logger.Info("subscribing to the order book again for resync")
+			return r.ResubscribeBook(symbol)
+		}
+		fresh, err := feed.OrderBook(symbol, limit)
+		if err != nil {
+			return err
+		}
+		if fresh.LastUpdateId == 0 {
+			return errors.New("venue has no book snapshot to resync from")
+		}
+		orderBook.Load(fresh.Snapshot(0))
+		logger.Info("order book loaded for resync", "lastUpdateId", fresh.LastUpdateId)
+		return nil
+	}
+	drained := updateAndPrintOrderBook(orderBook, ch, wg, ticker, archive, verifier, resync)
+	if cfg.Interval > 0 && db != nil {
+		snapshotOrderBook(orderBook, wg, db, cfg, drained)
+	}
+	if archive != nil {
+		runBookArchive(archive, orderBook, wg, archiveCfg, drained)
+	}
+	return orderBook, nil
+}
+
+// updateAndPrintOrderBook applies the events of ch until the reader closes
+// it. The returned channel is closed once every event was applied, so the
+// writers can store the final state of the book. On a sequence gap, a diff
+// beyond the loaded snapshot or a checksum mismatch the book is resynced
+// with resync, retried with backoff on the ticker until it succeeds.
+func updateAndPrintOrderBook(orderBook *orderbook.Book, ch chan exchange.BookEvent, wg *sync.WaitGroup, ticker *clock.Ticker, archive *storage.BookArchive, verifier exchange.BookVerifier, resync func() error) <-chan struct{} {
+	logger := slog.With("stream", telemetry.StreamBook, "symbol", orderBook.Symbol)
+	// inSync counts every sync after the first as a resync.
+	everSynced := false
+	inSync := func() {
+		if everSynced {
+			telemetry.BookSyncs.WithLabelValues(orderBook.Symbol).Inc()
+		}
+		everSynced = true
+	}
+	needResync := false
+	var resyncAt time.Time
+	wait := resyncMin
+	tryResync := func() {
+		now := clock.Now()
+		if !needResync || now.Before(resyncAt) {
+			return
+		}
+		if err := resync(); err != nil {
+			logger.Error("resync order book", "err", err, "retryIn", wait)
+			resyncAt = now.Add(wait)
+			wait = min(2*wait, resyncMax)
+			return
+		}
+		needResync = false
+		resyncAt = now.Add(resyncMin)
+		wait = resyncMin
+		if archive != nil {
+			if err := archive.Anchor(orderBook); err != nil {
+				logger.Error("order book anchor", "err", err)
+			}
+		}
+	}
+	drained := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(drained)
+		for {
+			select {
+			case e, ok := <-ch:
+				if !ok {
+					logger.Info("order book updates are finished", "lastUpdateId", orderBook.LastID())
+					return
+				}
+				if e.Snapshot != nil && e.Partial {
+					synced := orderBook.Synced()
+					u, ok := orderBook.Replace(*e.Snapshot)
+					if !ok {
+						continue
+					}
+					if !synced {
+						inSync()
+						logger.Info("order book in sync from partial book", "lastUpdateId", u.FinalUpdateID)
+					}
+					if archive != nil {
+						archive.Add(u)
+					}
+				} else if e.Snapshot != nil {
+					orderBook.Reset(*e.Snapshot)
+					inSync()
+					needResync = false
+					logger.Info("order book reset from stream snapshot", "lastUpdateId", e.Snapshot.LastUpdateId)
+					if archive != nil {
+						if err := archive.Anchor(orderBook); err != nil {
+							logger.Error("order book anchor", "err", err)
+						}
+					}
+				} else {
+					v := e.Diff
+					synced := orderBook.Synced()
+					if !orderBook.Update(&v) {
+						if synced && v.FinalUpdateID > orderBook.LastID() {
+							telemetry.SequenceGaps.WithLabelValues(orderBook.Symbol).Inc()
+							logger.Warn("depth update sequence gap", "firstUpdateId", v.FirstUpdateID, "finalUpdateId", v.FinalUpdateID, "lastUpdateId", orderBook.LastID())
+						}
+						if !orderBook.Synced() && v.FirstUpdateID > orderBook.LastID()+1 {
+							needResync = true
+							tryResync()
+						}
+						continue
+					}
+					if !synced {
+						inSync()
+						logger.Info("order book in sync", "firstUpdateId", v.FirstUpdateID, "finalUpdateId", v.FinalUpdateID)
+					}
+					if archive != nil {
+						archive.Add(v)
+					}
+				}
+				if verifier != nil && orderBook.Synced() {
+					if err := verifier.VerifyBook(orderBook, e.Checksum); err != nil {
+						orderBook.Invalidate()
+						telemetry.ChecksumErrors.WithLabelValues(orderBook.Symbol).Inc()
+						logger.Warn("order book checksum mismatch, book is out of sync", "lastUpdateId", orderBook.LastID(), "err", err)
+						needResync = true
+						tryResync()
+					}
+				}
+			case <-ticker.C:
+				tryResync()
+				if bid, ask, ok := orderBook.Top(); ok {
+					logger.Debug("order book", "lastUpdateId", orderBook.LastID(), "bid", bid, "ask", ask)
+				}
+			}
+		}
+	}()
+	return drained
+}
+
+// snapshotOrderBook stores the book every cfg.Interval and a last time once
+// drained is closed, so the final state of the book survives a shutdown.
+func snapshotOrderBook(orderBook *orderbook.Book, wg *sync.WaitGroup, db *sql.DB, cfg BookSnapshotConfig, drained <-chan struct{}) {
+	logger := slog.With("symbol", orderBook.Symbol)
+	ticker := clock.NewTicker(cfg.Interval)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-drained:
+				if err := storeOrderBookSnapshot(db, orderBook, cfg.Depth); err != nil {
+					logger.Error("final order book snapshot lost", "err", err)
+				} else {
+					logger.Info("flushed final order book snapshot", "lastUpdateId", orderBook.LastID())
+				}
+				logger.Info("order book snapshots are finished")
+				return
+			case <-ticker.C:
+				if err := storeOrderBookSnapshot(db, orderBook, cfg.Depth); err != nil {
+					logger.Error("insert order book snapshot", "err", err)
+				}
+			}
+		}
+	}()
+}
+
+func storeOrderBookSnapshot(db *sql.DB, orderBook *orderbook.Book, depth int) error {
+	snapshot := orderBook.Snapshot(depth)
+	start := time.Now()
+	_, err := storage.InsertOrderBookSnapshot(db, snapshot, depth)
+	telemetry.ObserveInsert("order_book_snapshots", orderBook.Symbol, start, err)
+	return err
+}
+
+// runBookArchive flushes and anchors the archive on its tickers until
+// drained is closed, then flushes the remaining diffs.
+func runBookArchive(archive *storage.BookArchive, orderBook *orderbook.Book, wg *sync.WaitGroup, cfg BookArchiveConfig, drained <-chan struct{}) {
+	logger := slog.With("symbol", orderBook.Symbol)
+	if err := archive.Anchor(orderBook); err != nil {
+		logger.Error("order book anchor", "err", err)
+	}
+
+	flush := clock.NewTicker(cfg.FlushInterval)
+	anchor := clock.NewTicker(cfg.AnchorInterval)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer flush.Stop()
+		defer anchor.Stop()
+		for {
+			select {
+			case <-drained:
+				n := archive.Pending()
+				if err := archive.Flush(); err != nil {
+					logger.Error("order book diffs lost", "diffs", n, "err", err)
+				} else {
+					logger.Info("flushed order book diffs", "diffs", n)
+				}
+				logger.Info("order book archive is finished")
+				return
+			case <-flush.C:
+				if err := archive.Flush(); err != nil {
+					logger.Error("flush order book diffs", "err", err)
+				}
+			case <-anchor.C:
+				if err := archive.Anchor(orderBook); err != nil {
+					logger.Error("order book anchor", "err", err)
+				}
+			}
+		}
+	}()
+}
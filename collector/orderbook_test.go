@@ -0,0 +1,71 @@
+package collector
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/exchange"
+	"test.bhft.com/orderbook"
+)
+
+// bookFeed serves the REST snapshots in order and the events sent on ch.
+type bookFeed struct {
+	snapshots []orderbook.Snapshot
+	calls     int
+	ch        chan exchange.BookEvent
+}
+
+func (f *bookFeed) OrderBook(symbol string, limit int) (*orderbook.Book, error) {
+	s := f.snapshots[min(f.calls, len(f.snapshots)-1)]
+	f.calls++
+	return orderbook.FromSnapshot(s), nil
+}
+
+func (f *bookFeed) BookStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan exchange.BookEvent, error) {
+	return f.ch, nil
+}
+
+func diff(first, final int64, bid string) exchange.BookEvent {
+	return exchange.BookEvent{Diff: orderbook.Update{FirstUpdateID: first, FinalUpdateID: final, Bids: [][]string{{"100", bid}}}}
+}
+
+// TestOrderBookResync checks a sequence gap loads a fresh REST snapshot and
+// the book gets back in sync with the diff spanning it.
+func TestOrderBookResync(t *testing.T) {
+	feed := &bookFeed{
+		snapshots: []orderbook.Snapshot{
+			{Symbol: "BTCUSDT", LastUpdateId: 100, Bids: []orderbook.Level{{Price: "100", Quantity: "1"}}},
+			{Symbol: "BTCUSDT", LastUpdateId: 200, Bids: []orderbook.Level{{Price: "100", Quantity: "4"}}},
+		},
+		ch: make(chan exchange.BookEvent),
+	}
+	ticker := clock.NewTicker(time.Hour)
+	defer ticker.Stop()
+	var wg sync.WaitGroup
+	book, err := HandleOrderBook(context.Background(), &wg, ticker, feed, "BTCUSDT", 100, nil, BookSnapshotConfig{}, BookArchiveConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed.ch <- diff(101, 102, "2")
+	// 103 to 104 are missing.
+	feed.ch <- diff(105, 106, "3")
+	// Older than the resync snapshot, skipped.
+	feed.ch <- diff(150, 160, "9")
+	feed.ch <- diff(195, 201, "5")
+	close(feed.ch)
+	wg.Wait()
+
+	if feed.calls != 2 {
+		t.Errorf("%d REST snapshots loaded, want 2", feed.calls)
+	}
+	if !book.Synced() || book.LastID() != 201 {
+		t.Errorf("book synced %v at %d, want synced at 201", book.Synced(), book.LastID())
+	}
+	if q := book.LevelQty(100, false); q != 5 {
+		t.Errorf("bid 100 quantity %v, want 5", q)
+	}
+}
@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"test.bhft.com/orderbook"
+	"test.bhft.com/paper"
+	"test.bhft.com/trades"
+)
+
+// RunPaper feeds the updates of book and the trades of list to sim until ctx
+// is done.
+func RunPaper(ctx context.Context, wg *sync.WaitGroup, book *orderbook.Book, list *trades.List, sim *paper.Simulator) {
+	updates, unsubscribeBook := book.Subscribe(1000)
+	tradeCh, unsubscribeTrades := list.Subscribe(1000)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer unsubscribeBook()
+		defer unsubscribeTrades()
+		for {
+			select {
+			case <-ctx.Done():
+				a := sim.State().Account
+				slog.Info("paper trading is finished", "symbol", sim.Symbol, "position", a.Position, "netPnl", a.NetPnL, "fees", a.Fees)
+				return
+			case u := <-updates:
+				sim.OnBook(u)
+			case t := <-tradeCh:
+				sim.OnTrade(t)
+			}
+		}
+	}()
+}
@@ -0,0 +1,80 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/exchange"
+	"test.bhft.com/quotes"
+	"test.bhft.com/storage"
+	"test.bhft.com/telemetry"
+)
+
+// QuoteConfig controls how best quotes are stored. Sample 0 stores every
+// quote change, a positive Sample at most one quote per Sample.
+type QuoteConfig struct {
+	Sample time.Duration
+}
+
+// HandleQuotes keeps the best quote of symbol from the quote stream of feed
+// until ctx is done and stores the quotes kept on every tick. Once the
+// reader closes the stream the remaining quotes are stored.
+func HandleQuotes(ctx context.Context, wg *sync.WaitGroup, ticker *clock.Ticker, feed exchange.QuoteFeed, symbol string, db *sql.DB, cfg QuoteConfig) (*quotes.Latest, error) {
+	ch, err := feed.QuoteStream(ctx, wg, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("quote stream: %w", err)
+	}
+
+	latest := quotes.New(symbol)
+	logger := slog.With("stream", telemetry.StreamQuotes, "symbol", symbol)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var pending []quotes.Quote
+		var lastKept int64
+		for {
+			select {
+			case q, ok := <-ch:
+				if !ok {
+					if err := storeQuotes(db, pending); err != nil {
+						logger.Error("quotes lost", "quotes", len(pending), "err", err)
+					} else {
+						logger.Info("flushed quotes", "quotes", len(pending))
+					}
+					logger.Info("quote flow is finished")
+					return
+				}
+				if !latest.Update(q) {
+					continue
+				}
+				if cfg.Sample > 0 && q.Time-lastKept < cfg.Sample.Milliseconds() {
+					continue
+				}
+				lastKept = q.Time
+				pending = append(pending, q)
+			case <-ticker.C:
+				if err := storeQuotes(db, pending); err != nil {
+					logger.Error("insert quotes", "quotes", len(pending), "err", err)
+					continue
+				}
+				pending = pending[:0]
+			}
+		}
+	}()
+	return latest, nil
+}
+
+func storeQuotes(db *sql.DB, list []quotes.Quote) error {
+	if db == nil || len(list) == 0 {
+		return nil
+	}
+	start := time.Now()
+	err := storage.InsertQuotes(db, list)
+	telemetry.ObserveInsert("quotes", list[0].Symbol, start, err)
+	return err
+}
@@ -0,0 +1,115 @@
+package collector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"test.bhft.com/binance"
+	"test.bhft.com/capture"
+	"test.bhft.com/clock"
+)
+
+// TestReplayCapture drives the book, trades and klines pipelines from a
+// capture without a database and checks the state they end in.
+func TestReplayCapture(t *testing.T) {
+	r, err := capture.NewReplayer("testdata/binance_capture.jsonl", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Set(r.Clock)
+
+	client := binance.NewClient(&http.Client{Transport: r})
+	client.Replayer = r
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	newTicker := func() *clock.Ticker {
+		ticker := clock.NewTicker(time.Second)
+		t.Cleanup(ticker.Stop)
+		return ticker
+	}
+	book, err := HandleOrderBook(ctx, &wg, newTicker(), client, "BTCUSDT", 100, nil, BookSnapshotConfig{Interval: time.Minute}, BookArchiveConfig{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tradeList, err := HandleTrades(ctx, &wg, newTicker(), client, "BTCUSDT", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	klineList, err := HandleKlines(ctx, &wg, newTicker(), client, "BTCUSDT", "1m", 100, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.Run(ctx)
+	wg.Wait()
+
+	if !book.Synced() || book.LastID() != 103 {
+		t.Errorf("book synced %v at %d, want synced at 103", book.Synced(), book.LastID())
+	}
+	s := book.Snapshot(0)
+	wantBids := map[string]string{"100.50": "0.7", "100.00": "0.5"}
+	wantAsks := map[string]string{"102.00": "3.0"}
+	if len(s.Bids) != len(wantBids) || len(s.Asks) != len(wantAsks) {
+		t.Fatalf("book %+v", s)
+	}
+	for _, l := range s.Bids {
+		if wantBids[l.Price] != l.Quantity {
+			t.Errorf("bid %s: got %s, want %s", l.Price, l.Quantity, wantBids[l.Price])
+		}
+	}
+	for _, l := range s.Asks {
+		if wantAsks[l.Price] != l.Quantity {
+			t.Errorf("ask %s: got %s, want %s", l.Price, l.Quantity, wantAsks[l.Price])
+		}
+	}
+
+	var ids []int64
+	for _, trade := range tradeList.Page(0, 100) {
+		ids = append(ids, trade.ID)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 3 {
+		t.Errorf("trade IDs %v, want [1 2 3]", ids)
+	}
+
+	list := klineList.Range(0, 1<<62)
+	if len(list) != 2 || list[1].OpenTime != 1700000000000 || list[1].Volume != "0.5" {
+		t.Errorf("klines %+v", list)
+	}
+
+	if got := clock.Now().UnixMilli(); got != 1700000003000 {
+		t.Errorf("clock at %d, want the last frame at 1700000003000", got)
+	}
+}
+
+// TestReplayLateStream checks a stream opened after the capture ended reads
+// EOF instead of blocking.
+func TestReplayLateStream(t *testing.T) {
+	r, err := capture.NewReplayer("testdata/binance_capture.jsonl", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Run(context.Background())
+	conn, err := r.Open("/ws/btcusdt@trade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := conn.ReadMessage()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != io.EOF {
+			t.Errorf("got %v, want EOF", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("late stream blocks")
+	}
+}
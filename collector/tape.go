@@ -0,0 +1,98 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/storage"
+	"test.bhft.com/tape"
+	"test.bhft.com/telemetry"
+	"test.bhft.com/trades"
+)
+
+// RunTape runs the trades of list through analyzer until ctx is done. Large
+// trade events are logged as they are raised. The closed flow buckets and
+// the events are stored on every tick, so a slow database does not hold up
+// the trades, and the running bucket is stored on shutdown. Trades the tape
+// missed because it fell behind are counted in bhft_dropped_total.
+func RunTape(ctx context.Context, wg *sync.WaitGroup, ticker *clock.Ticker, list *trades.List, analyzer *tape.Analyzer, db *sql.DB) {
+	ch, unsubscribe := list.Subscribe(1000)
+	logger := slog.With("symbol", analyzer.Symbol)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer unsubscribe()
+		var flow []tape.Bucket
+		var events []tape.Event
+		var dropped int64
+		flush := func() error {
+			if err := storeTradeFlow(db, analyzer.Symbol, flow); err != nil {
+				return err
+			}
+			flow = flow[:0]
+			for len(events) > 0 {
+				if err := storeLargeTrade(db, events[0]); err != nil {
+					return err
+				}
+				events = events[1:]
+			}
+			return nil
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				if state := analyzer.State().Flow; len(state) > 0 {
+					flow = append(flow, state[len(state)-1])
+				}
+				if err := flush(); err != nil {
+					logger.Error("trade flow lost", "buckets", len(flow), "largeTrades", len(events), "err", err)
+				} else {
+					logger.Info("flushed trade flow")
+				}
+				logger.Info("tape flow is finished")
+				return
+			case t := <-ch:
+				closed, evs := analyzer.Add(t)
+				flow = append(flow, closed...)
+				for _, e := range evs {
+					logger.Info("large trade", "kind", e.Kind, "side", e.Side, "qty", e.Quantity, "notional", e.Notional, "price", e.Price, "trades", e.Trades)
+				}
+				events = append(events, evs...)
+			case <-ticker.C:
+				if n := list.Dropped(ch); n > dropped {
+					logger.Warn("tape missed trades", "trades", n-dropped)
+					telemetry.Dropped.WithLabelValues("tape", analyzer.Symbol).Add(float64(n - dropped))
+					dropped = n
+				}
+				if err := flush(); err != nil {
+					logger.Error("insert trade flow", "buckets", len(flow), "largeTrades", len(events), "err", err)
+				}
+			}
+		}
+	}()
+}
+
+func storeTradeFlow(db *sql.DB, symbol string, list []tape.Bucket) error {
+	if db == nil || len(list) == 0 {
+		return nil
+	}
+	start := time.Now()
+	err := storage.UpsertTradeFlow(db, symbol, list)
+	telemetry.ObserveInsert("trade_flow", symbol, start, err)
+	return err
+}
+
+func storeLargeTrade(db *sql.DB, e tape.Event) error {
+	if db == nil {
+		return nil
+	}
+	start := time.Now()
+	err := storage.InsertLargeTrade(db, e)
+	telemetry.ObserveInsert("large_trades", e.Symbol, start, err)
+	return err
+}
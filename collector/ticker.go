@@ -0,0 +1,121 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/exchange"
+	"test.bhft.com/ticker"
+	"test.bhft.com/trades"
+)
+
+// tickerTolerance is the relative difference between the local and the venue
+// volume or trade count above which the cross-check warns.
+var tickerTolerance = 0.01
+
+// HandleTicker keeps the stats of the ticker stream kind of symbol on board
+// until ctx is done or the stream ends.
+func HandleTicker(ctx context.Context, wg *sync.WaitGroup, feed exchange.TickerFeed, symbol, kind string, board *ticker.Board) error {
+	ch, err := feed.TickerStream(ctx, wg, symbol, kind)
+	if err != nil {
+		return fmt.Errorf("%s stream: %w", kind, err)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for s := range ch {
+			board.Update(s)
+		}
+		slog.Info("ticker flow is finished", "stream", kind, "symbol", symbol)
+	}()
+	return nil
+}
+
+// RunRollingStats computes the stats of every window from the trades of
+// list and puts them on board every interval until ctx is done. Once a
+// window was watched for its whole length its stats are checked against the
+// venue stats of the same window on board.
+func RunRollingStats(ctx context.Context, wg *sync.WaitGroup, list *trades.List, board *ticker.Board, windows []time.Duration, interval time.Duration) {
+	ch, unsubscribe := list.Subscribe(1000)
+	rolling := make([]*ticker.Rolling, len(windows))
+	for i, w := range windows {
+		rolling[i] = ticker.NewRolling(board.Symbol, w)
+	}
+	logger := slog.With("symbol", board.Symbol)
+	mismatched := make(map[string]bool)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer unsubscribe()
+		tick := clock.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t := <-ch:
+				for _, r := range rolling {
+					r.Add(t)
+				}
+			case <-tick.C:
+				now := clock.Now().UnixMilli()
+				for _, r := range rolling {
+					s := r.Stats(now)
+					board.Update(s)
+					if r.Full(now) {
+						crossCheck(logger, board, s, mismatched)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// crossCheck compares local with the venue stats of its window on board.
+// mismatched holds the venue streams that differed at the last check, so a
+// lasting difference is only warned about once and the recovery is logged.
+func crossCheck(logger *slog.Logger, board *ticker.Board, local ticker.Stats, mismatched map[string]bool) {
+	for _, s := range board.All() {
+		if s.Source == ticker.SourceLocal || s.Window != local.Window {
+			continue
+		}
+		volume := relDiff(local.Volume, s.Volume)
+		count := relDiff(float64(local.Count), float64(s.Count))
+		attrs := []any{
+			"window", s.Window, "source", s.Source,
+			"volume", local.Volume, "venueVolume", s.Volume,
+			"count", local.Count, "venueCount", s.Count,
+			"high", local.High, "venueHigh", s.High,
+			"low", local.Low, "venueLow", s.Low,
+		}
+		key := s.Source + "." + s.Window
+		// The mini ticker sends no trade count.
+		differ := volume > tickerTolerance || (s.Count > 0 && count > tickerTolerance)
+		switch {
+		case differ && !mismatched[key]:
+			logger.Warn("local ticker stats differ from the venue", attrs...)
+		case !differ && mismatched[key]:
+			logger.Info("local ticker stats match the venue again", attrs...)
+		default:
+			logger.Debug("local ticker stats checked against the venue", append(attrs, "differ", differ)...)
+		}
+		mismatched[key] = differ
+	}
+}
+
+func relDiff(a, b float64) float64 {
+	if b == 0 {
+		if a == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return math.Abs(a-b) / math.Abs(b)
+}
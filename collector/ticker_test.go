@@ -0,0 +1,30 @@
+package collector
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"test.bhft.com/ticker"
+)
+
+// TestCrossCheckWarnsOnce checks a lasting difference with the venue is
+// warned about once, and again only after the stats matched in between.
+func TestCrossCheckWarnsOnce(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	board := ticker.NewBoard("BTCUSDT")
+	board.Update(ticker.Stats{Source: "ticker_1h", Window: "1h", Volume: 100, Count: 10})
+	mismatched := make(map[string]bool)
+
+	volumes := []float64{100, 50, 50, 50, 100, 100, 50}
+	warned := []bool{false, true, false, false, false, false, true}
+	for i, v := range volumes {
+		buf.Reset()
+		crossCheck(logger, board, ticker.Stats{Source: ticker.SourceLocal, Window: "1h", Volume: v, Count: 10}, mismatched)
+		if got := strings.Contains(buf.String(), "level=WARN"); got != warned[i] {
+			t.Errorf("check %d with volume %v: warned %v, want %v", i, v, got, warned[i])
+		}
+	}
+}
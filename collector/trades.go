@@ -0,0 +1,61 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/exchange"
+	"test.bhft.com/telemetry"
+	"test.bhft.com/trades"
+)
+
+// HandleTrades loads the latest trades of symbol and keeps the list up to
+// date from the trade stream of feed until ctx is done.
+func HandleTrades(ctx context.Context, wg *sync.WaitGroup, ticker *clock.Ticker, feed exchange.TradeFeed, symbol string, limit int) (*trades.List, error) {
+	tradeList, err := feed.Trades(symbol, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get trades: %w", err)
+	}
+
+	slog.Info("trade list loaded", "stream", telemetry.StreamTrades, "symbol", tradeList.Symbol, "trades", tradeList.Len())
+
+	tradech, err := feed.TradeStream(ctx, wg, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("trade stream: %w", err)
+	}
+
+	updateTradeList(tradeList, tradech, wg, ticker)
+	return tradeList, nil
+}
+
+// updateTradeList applies the trades of ch until the reader closes it. Trades
+// are only kept in memory, so nothing is flushed on shutdown.
+func updateTradeList(tradeList *trades.List, ch chan trades.Trade, wg *sync.WaitGroup, ticker *clock.Ticker) {
+	logger := slog.With("stream", telemetry.StreamTrades, "symbol", tradeList.Symbol)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case trade, ok := <-ch:
+				if !ok {
+					logger.Info("trade list updates are finished")
+					return
+				}
+				tradeList.Update(trade)
+			case <-ticker.C:
+				tradeList.Lock()
+				n := tradeList.Len()
+				var lastID int64
+				if n > 0 {
+					lastID = tradeList.Trades[n-1].ID
+				}
+				tradeList.Unlock()
+				logger.Debug("trade list", "trades", n, "lastTradeId", lastID)
+			}
+		}
+	}()
+}
@@ -0,0 +1,2 @@
+DROP TABLE order_book_levels;
+DROP TABLE order_book_snapshots;
\ No newline at end of file
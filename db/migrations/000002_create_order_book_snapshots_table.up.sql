@@ -0,0 +1,19 @@
+CREATE TABLE order_book_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    symbol TEXT NOT NULL,
+    snapshot_time BIGINT NOT NULL,
+    last_update_id BIGINT NOT NULL,
+    depth INT NOT NULL
+);
+
+CREATE INDEX order_book_snapshots_symbol_time_idx ON order_book_snapshots (symbol, snapshot_time);
+
+CREATE TABLE order_book_levels (
+    snapshot_id BIGINT NOT NULL REFERENCES order_book_snapshots (id) ON DELETE CASCADE,
+    side TEXT NOT NULL,
+    level INT NOT NULL,
+    price NUMERIC NOT NULL,
+    quantity NUMERIC NOT NULL
+);
+
+CREATE INDEX order_book_levels_snapshot_idx ON order_book_levels (snapshot_id);
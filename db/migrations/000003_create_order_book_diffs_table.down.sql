@@ -0,0 +1 @@
+DROP TABLE order_book_diffs;
\ No newline at end of file
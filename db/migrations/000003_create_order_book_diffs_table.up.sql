@@ -0,0 +1,10 @@
+CREATE TABLE order_book_diffs (
+    symbol TEXT NOT NULL,
+    event_time BIGINT NOT NULL,
+    first_update_id BIGINT NOT NULL,
+    final_update_id BIGINT NOT NULL,
+    data BYTEA NOT NULL,
+    PRIMARY KEY (symbol, final_update_id)
+);
+
+CREATE INDEX order_book_diffs_symbol_time_idx ON order_book_diffs (symbol, event_time);
@@ -0,0 +1 @@
+DROP TABLE features;
\ No newline at end of file
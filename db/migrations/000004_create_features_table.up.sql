@@ -0,0 +1,14 @@
+CREATE TABLE features (
+    symbol TEXT NOT NULL,
+    time BIGINT NOT NULL,
+    mid DOUBLE PRECISION NOT NULL,
+    microprice DOUBLE PRECISION NOT NULL,
+    quoted_spread_bps DOUBLE PRECISION NOT NULL,
+    effective_spread_bps DOUBLE PRECISION NOT NULL,
+    ofi DOUBLE PRECISION NOT NULL,
+    trade_imbalance DOUBLE PRECISION NOT NULL,
+    realized_vol DOUBLE PRECISION NOT NULL,
+    trade_intensity DOUBLE PRECISION NOT NULL,
+    trades INT NOT NULL,
+    PRIMARY KEY (symbol, time)
+);
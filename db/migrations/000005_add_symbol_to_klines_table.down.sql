@@ -0,0 +1,4 @@
+DROP INDEX klines_symbol_interval_time_idx;
+
+ALTER TABLE klines DROP COLUMN kline_interval;
+ALTER TABLE klines DROP COLUMN symbol;
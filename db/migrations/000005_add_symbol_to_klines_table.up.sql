@@ -0,0 +1,4 @@
+ALTER TABLE klines ADD COLUMN symbol TEXT NOT NULL DEFAULT 'BTCUSDT';
+ALTER TABLE klines ADD COLUMN kline_interval TEXT NOT NULL DEFAULT '1d';
+
+CREATE INDEX klines_symbol_interval_time_idx ON klines (symbol, kline_interval, open_time);
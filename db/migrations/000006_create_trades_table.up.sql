@@ -0,0 +1,12 @@
+CREATE TABLE trades (
+    symbol TEXT NOT NULL,
+    id BIGINT NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    quantity DOUBLE PRECISION NOT NULL,
+    quote_quantity DOUBLE PRECISION NOT NULL,
+    time BIGINT NOT NULL,
+    is_buyer_maker BOOLEAN NOT NULL,
+    PRIMARY KEY (symbol, id)
+);
+
+CREATE INDEX trades_symbol_time_idx ON trades (symbol, time);
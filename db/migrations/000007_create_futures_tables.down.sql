@@ -0,0 +1,3 @@
+DROP TABLE liquidations;
+DROP TABLE open_interest;
+DROP TABLE mark_prices;
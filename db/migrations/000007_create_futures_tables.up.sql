@@ -0,0 +1,32 @@
+CREATE TABLE mark_prices (
+    symbol TEXT NOT NULL,
+    time BIGINT NOT NULL,
+    mark_price NUMERIC NOT NULL,
+    index_price NUMERIC NOT NULL,
+    estimated_settle_price NUMERIC NOT NULL,
+    funding_rate NUMERIC NOT NULL,
+    next_funding_time BIGINT NOT NULL,
+    PRIMARY KEY (symbol, time)
+);
+
+CREATE TABLE open_interest (
+    symbol TEXT NOT NULL,
+    time BIGINT NOT NULL,
+    open_interest NUMERIC NOT NULL,
+    PRIMARY KEY (symbol, time)
+);
+
+CREATE TABLE liquidations (
+    symbol TEXT NOT NULL,
+    time BIGINT NOT NULL,
+    side TEXT NOT NULL,
+    order_type TEXT NOT NULL,
+    time_in_force TEXT NOT NULL,
+    quantity NUMERIC NOT NULL,
+    price NUMERIC NOT NULL,
+    average_price NUMERIC NOT NULL,
+    status TEXT NOT NULL,
+    filled_quantity NUMERIC NOT NULL
+);
+
+CREATE INDEX liquidations_symbol_time_idx ON liquidations (symbol, time);
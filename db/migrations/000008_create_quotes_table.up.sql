@@ -0,0 +1,11 @@
+CREATE TABLE quotes (
+    symbol TEXT NOT NULL,
+    time BIGINT NOT NULL,
+    update_id BIGINT NOT NULL,
+    bid_price NUMERIC NOT NULL,
+    bid_qty NUMERIC NOT NULL,
+    ask_price NUMERIC NOT NULL,
+    ask_qty NUMERIC NOT NULL
+);
+
+CREATE INDEX quotes_symbol_time_idx ON quotes (symbol, time);
@@ -0,0 +1,2 @@
+DROP TABLE large_trades;
+DROP TABLE trade_flow;
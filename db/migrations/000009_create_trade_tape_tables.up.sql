@@ -0,0 +1,27 @@
+CREATE TABLE trade_flow (
+    symbol TEXT NOT NULL,
+    flow_interval TEXT NOT NULL,
+    open_time BIGINT NOT NULL,
+    close_time BIGINT NOT NULL,
+    buy_volume DOUBLE PRECISION NOT NULL,
+    sell_volume DOUBLE PRECISION NOT NULL,
+    buy_quote_volume DOUBLE PRECISION NOT NULL,
+    sell_quote_volume DOUBLE PRECISION NOT NULL,
+    trades BIGINT NOT NULL,
+    PRIMARY KEY (symbol, flow_interval, open_time)
+);
+
+CREATE TABLE large_trades (
+    symbol TEXT NOT NULL,
+    time BIGINT NOT NULL,
+    kind TEXT NOT NULL,
+    side TEXT NOT NULL,
+    first_id BIGINT NOT NULL,
+    last_id BIGINT NOT NULL,
+    trades INT NOT NULL,
+    quantity DOUBLE PRECISION NOT NULL,
+    notional DOUBLE PRECISION NOT NULL,
+    price DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX large_trades_symbol_time_idx ON large_trades (symbol, time);
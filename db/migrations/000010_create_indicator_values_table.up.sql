@@ -0,0 +1,9 @@
+CREATE TABLE indicator_values (
+    symbol TEXT NOT NULL,
+    kline_interval TEXT NOT NULL,
+    open_time BIGINT NOT NULL,
+    indicator TEXT NOT NULL,
+    field TEXT NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (symbol, kline_interval, indicator, field, open_time)
+);
@@ -0,0 +1,3 @@
+CREATE INDEX klines_symbol_interval_time_idx ON klines (symbol, kline_interval, open_time);
+
+DROP INDEX klines_symbol_interval_open_time_key;
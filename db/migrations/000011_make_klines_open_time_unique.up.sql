@@ -0,0 +1,6 @@
+DELETE FROM klines a USING klines b
+    WHERE a.symbol = b.symbol AND a.kline_interval = b.kline_interval AND a.open_time = b.open_time AND a.ctid < b.ctid;
+
+CREATE UNIQUE INDEX klines_symbol_interval_open_time_key ON klines (symbol, kline_interval, open_time);
+
+DROP INDEX klines_symbol_interval_time_idx;
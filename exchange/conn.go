@@ -0,0 +1,57 @@
+package exchange
+
+import (
+	"context"
+	"log/slog"
+
+	"test.bhft.com/capture"
+	"test.bhft.com/telemetry"
+)
+
+// TrackConn reports conn as the connected stream of symbol on venue to
+// telemetry.Health and marks it as disconnected once reading fails or it is
+// closed.
+func TrackConn(conn capture.Conn, venue, stream, symbol string) capture.Conn {
+	telemetry.Health.Connected(venue, stream, symbol)
+	return &trackedConn{Conn: conn, venue: venue, stream: stream, symbol: symbol}
+}
+
+type trackedConn struct {
+	capture.Conn
+	venue, stream, symbol string
+}
+
+func (c *trackedConn) ReadMessage() (int, []byte, error) {
+	mt, message, err := c.Conn.ReadMessage()
+	if err != nil {
+		telemetry.Health.Disconnected(c.venue, c.stream, c.symbol)
+	}
+	return mt, message, err
+}
+
+func (c *trackedConn) Close() error {
+	telemetry.Health.Disconnected(c.venue, c.stream, c.symbol)
+	return c.Conn.Close()
+}
+
+// CloseOnDone closes c once ctx is done so a reader blocked in ReadMessage
+// returns. The returned func releases the watch.
+func CloseOnDone(ctx context.Context, c capture.Conn) func() {
+	stop := context.AfterFunc(ctx, func() {
+		c.Close()
+	})
+	return func() {
+		stop()
+		c.Close()
+	}
+}
+
+// LogReadEnd logs why a stream reader stopped. Reads failing because the
+// connection was closed for shutdown are expected.
+func LogReadEnd(ctx context.Context, logger *slog.Logger, err error) {
+	if ctx.Err() != nil {
+		logger.Info("stream closed for shutdown")
+		return
+	}
+	logger.Error("read stream", "err", err)
+}
@@ -0,0 +1,96 @@
+// Package exchange defines the venue neutral market data feeds the
+// collector runs on. Each venue adapter turns its wire format into the
+// orderbook, trades and klines types.
+package exchange
+
+import (
+	"context"
+	"sync"
+
+	"test.bhft.com/futures"
+	"test.bhft.com/klines"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/quotes"
+	"test.bhft.com/ticker"
+	"test.bhft.com/trades"
+)
+
+// BookEvent is a change of the order book. When Snapshot is set it replaces
+// the whole book, otherwise Diff is applied with the update ID rule of
+// orderbook.Book.Update. Partial marks Snapshot as the top levels sent by a
+// partial book stream on every change, rather than a starting point for
+// diffs. Checksum is the venue checksum of the book once the event is
+// applied, for venues implementing BookVerifier.
+type BookEvent struct {
+	Snapshot *orderbook.Snapshot
+	Partial  bool
+	Diff     orderbook.Update
+	Checksum int32
+}
+
+// BookFeed streams the order book of a symbol. Venues sending the snapshot
+// on the stream return an empty book from OrderBook and start BookStream
+// with a snapshot event; the others return a REST snapshot that the diffs
+// of BookStream continue.
+type BookFeed interface {
+	OrderBook(symbol string, limit int) (*orderbook.Book, error)
+	BookStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan BookEvent, error)
+}
+
+// BookVerifier is implemented by venues that send a checksum of the book.
+// VerifyBook returns an error when the book does not match checksum.
+type BookVerifier interface {
+	VerifyBook(orderBook *orderbook.Book, checksum int32) error
+}
+
+// BookResubscriber is implemented by venues that send the book snapshot on
+// the stream. ResubscribeBook drops the book stream of symbol so it starts
+// again with a fresh snapshot.
+type BookResubscriber interface {
+	ResubscribeBook(symbol string) error
+}
+
+// TradeFeed loads the latest trades of a symbol and streams new ones.
+type TradeFeed interface {
+	Trades(symbol string, limit int) (*trades.List, error)
+	TradeStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan trades.Trade, error)
+}
+
+// KlineFeed loads the latest klines of a symbol and streams their updates.
+// Intervals are written the Binance way, like 1m, 1h or 1d, whatever the
+// venue calls them.
+type KlineFeed interface {
+	Klines(symbol, interval string, limit int) (*klines.List, error)
+	KlineStream(ctx context.Context, wg *sync.WaitGroup, symbol, interval string) (chan klines.Update, error)
+}
+
+// Venue is an exchange the collector can ingest from. Symbols are written
+// the way the venue writes them, like BTCUSDT on Binance and BTC-USDT on
+// OKX.
+type Venue interface {
+	Name() string
+	Ping() error
+	// ServerTime returns the venue time in milliseconds.
+	ServerTime() (int64, error)
+	BookFeed
+	TradeFeed
+	KlineFeed
+}
+
+// QuoteFeed streams the best bid and ask of a symbol.
+type QuoteFeed interface {
+	QuoteStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan quotes.Quote, error)
+}
+
+// DerivativesFeed is implemented by perpetual futures venues.
+type DerivativesFeed interface {
+	MarkPriceStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan futures.MarkPrice, error)
+	OpenInterest(symbol string) (futures.OpenInterest, error)
+	LiquidationStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan futures.Liquidation, error)
+}
+
+// TickerFeed streams the rolling window statistics the venue computes for a
+// symbol. kind names the venue stream, like ticker or ticker_1h on Binance.
+type TickerFeed interface {
+	TickerStream(ctx context.Context, wg *sync.WaitGroup, symbol, kind string) (chan ticker.Stats, error)
+}
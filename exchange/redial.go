@@ -0,0 +1,110 @@
+package exchange
+
+import (
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"test.bhft.com/capture"
+)
+
+const (
+	redialMin = time.Second
+	redialMax = 30 * time.Second
+)
+
+// RedialConn is a stream connection that dials again whenever reading
+// fails, backing off between attempts, until it is closed. Readers only see
+// the messages, a reconnect shows up as a jump in the stream.
+type RedialConn struct {
+	dial           func() (capture.Conn, error)
+	stream, symbol string
+	done           chan struct{}
+
+	mu     sync.Mutex
+	conn   capture.Conn
+	closed bool
+}
+
+// Redial opens a connection with dial and keeps it open. The error of the
+// first dial is returned.
+func Redial(dial func() (capture.Conn, error), stream, symbol string) (*RedialConn, error) {
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	return &RedialConn{dial: dial, stream: stream, symbol: symbol, done: make(chan struct{}), conn: conn}, nil
+}
+
+func (c *RedialConn) ReadMessage() (int, []byte, error) {
+	for {
+		c.mu.Lock()
+		conn, closed := c.conn, c.closed
+		c.mu.Unlock()
+		if closed {
+			return 0, nil, net.ErrClosed
+		}
+		mt, message, err := conn.ReadMessage()
+		if err == nil {
+			return mt, message, nil
+		}
+		c.mu.Lock()
+		closed = c.closed
+		c.mu.Unlock()
+		if closed {
+			return 0, nil, err
+		}
+		slog.Warn("stream disconnected, reconnecting", "stream", c.stream, "symbol", c.symbol, "err", err)
+		conn.Close()
+		if err := c.redial(); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// redial dials until it succeeds or the connection is closed.
+func (c *RedialConn) redial() error {
+	wait := redialMin
+	for {
+		select {
+		case <-c.done:
+			return net.ErrClosed
+		case <-time.After(wait):
+		}
+		conn, err := c.dial()
+		if err != nil {
+			slog.Error("reconnect stream", "stream", c.stream, "symbol", c.symbol, "err", err)
+			wait = min(2*wait, redialMax)
+			continue
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.closed {
+			conn.Close()
+			return net.ErrClosed
+		}
+		c.conn = conn
+		return nil
+	}
+}
+
+// Reset drops the current connection, the reader dials a new one. Venues
+// that send the book as a snapshot on subscribe resync this way.
+func (c *RedialConn) Reset() {
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	conn.Close()
+}
+
+func (c *RedialConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	return c.conn.Close()
+}
@@ -0,0 +1,214 @@
+// Package export writes klines and trades as CSV or Parquet files.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/parquet-go/parquet-go"
+
+	"test.bhft.com/klines"
+	"test.bhft.com/trades"
+)
+
+// Formats a file can be written in.
+const (
+	CSV     = "csv"
+	Parquet = "parquet"
+)
+
+// Writer writes rows of klines or trades to an output file. Close must be
+// called to flush the rows, it does not close the underlying writer.
+type Writer interface {
+	WriteKlines(symbol, interval string, list []klines.Kline) error
+	WriteTrades(symbol string, list []trades.Trade) error
+	Close() error
+}
+
+// NewWriter returns a writer of format to w.
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case CSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case Parquet:
+		return &parquetWriter{out: w}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q, expected %s or %s", format, CSV, Parquet)
+}
+
+var (
+	klineHeader = []string{"symbol", "interval", "open_time", "close_time", "open", "high", "low", "close", "volume", "quote_volume", "trades", "taker_buy_volume", "taker_buy_quote_volume"}
+	tradeHeader = []string{"symbol", "id", "time", "price", "quantity", "quote_quantity", "is_buyer_maker"}
+)
+
+// csvWriter keeps the decimal strings as they came from the exchange.
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvWriter) writeHeader(header []string) error {
+	if c.header {
+		return nil
+	}
+	c.header = true
+	return c.w.Write(header)
+}
+
+func (c *csvWriter) WriteKlines(symbol, interval string, list []klines.Kline) error {
+	if err := c.writeHeader(klineHeader); err != nil {
+		return err
+	}
+	for _, k := range list {
+		err := c.w.Write([]string{
+			symbol,
+			interval,
+			strconv.FormatInt(k.OpenTime, 10),
+			strconv.FormatInt(k.CloseTime, 10),
+			k.Open,
+			k.High,
+			k.Low,
+			k.Close,
+			k.Volume,
+			k.QuoteAssetVolume,
+			strconv.FormatInt(k.NumberOfTrades, 10),
+			k.TakerBuyBaseAssetVolume,
+			k.TakerBuyQuoteAssetVolume,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return c.w.Error()
+}
+
+func (c *csvWriter) WriteTrades(symbol string, list []trades.Trade) error {
+	if err := c.writeHeader(tradeHeader); err != nil {
+		return err
+	}
+	for _, t := range list {
+		err := c.w.Write([]string{
+			symbol,
+			strconv.FormatInt(t.ID, 10),
+			strconv.FormatInt(t.Time, 10),
+			t.Price,
+			t.Quantity,
+			t.QuoteQuantity,
+			strconv.FormatBool(t.IsBuyerMaker),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type klineRow struct {
+	Symbol              string  `parquet:"symbol,dict"`
+	Interval            string  `parquet:"interval,dict"`
+	OpenTime            int64   `parquet:"open_time,timestamp(millisecond)"`
+	CloseTime           int64   `parquet:"close_time,timestamp(millisecond)"`
+	Open                float64 `parquet:"open"`
+	High                float64 `parquet:"high"`
+	Low                 float64 `parquet:"low"`
+	Close               float64 `parquet:"close"`
+	Volume              float64 `parquet:"volume"`
+	QuoteVolume         float64 `parquet:"quote_volume"`
+	Trades              int64   `parquet:"trades"`
+	TakerBuyVolume      float64 `parquet:"taker_buy_volume"`
+	TakerBuyQuoteVolume float64 `parquet:"taker_buy_quote_volume"`
+}
+
+type tradeRow struct {
+	Symbol        string  `parquet:"symbol,dict"`
+	ID            int64   `parquet:"id"`
+	Time          int64   `parquet:"time,timestamp(millisecond)"`
+	Price         float64 `parquet:"price"`
+	Quantity      float64 `parquet:"quantity"`
+	QuoteQuantity float64 `parquet:"quote_quantity"`
+	IsBuyerMaker  bool    `parquet:"is_buyer_maker"`
+}
+
+// parquetWriter stores prices and quantities as doubles. The schema is set
+// by the first write, so a file holds either klines or trades.
+type parquetWriter struct {
+	out    io.Writer
+	klines *parquet.GenericWriter[klineRow]
+	trades *parquet.GenericWriter[tradeRow]
+}
+
+func (p *parquetWriter) WriteKlines(symbol, interval string, list []klines.Kline) error {
+	if p.trades != nil {
+		return fmt.Errorf("parquet: klines written to a trades file")
+	}
+	if p.klines == nil {
+		p.klines = parquet.NewGenericWriter[klineRow](p.out)
+	}
+	rows := make([]klineRow, 0, len(list))
+	for _, k := range list {
+		rows = append(rows, klineRow{
+			Symbol:              symbol,
+			Interval:            interval,
+			OpenTime:            k.OpenTime,
+			CloseTime:           k.CloseTime,
+			Open:                parseFloat(k.Open),
+			High:                parseFloat(k.High),
+			Low:                 parseFloat(k.Low),
+			Close:               parseFloat(k.Close),
+			Volume:              parseFloat(k.Volume),
+			QuoteVolume:         parseFloat(k.QuoteAssetVolume),
+			Trades:              k.NumberOfTrades,
+			TakerBuyVolume:      parseFloat(k.TakerBuyBaseAssetVolume),
+			TakerBuyQuoteVolume: parseFloat(k.TakerBuyQuoteAssetVolume),
+		})
+	}
+	_, err := p.klines.Write(rows)
+	return err
+}
+
+func (p *parquetWriter) WriteTrades(symbol string, list []trades.Trade) error {
+	if p.klines != nil {
+		return fmt.Errorf("parquet: trades written to a klines file")
+	}
+	if p.trades == nil {
+		p.trades = parquet.NewGenericWriter[tradeRow](p.out)
+	}
+	rows := make([]tradeRow, 0, len(list))
+	for _, t := range list {
+		rows = append(rows, tradeRow{
+			Symbol:        symbol,
+			ID:            t.ID,
+			Time:          t.Time,
+			Price:         parseFloat(t.Price),
+			Quantity:      parseFloat(t.Quantity),
+			QuoteQuantity: parseFloat(t.QuoteQuantity),
+			IsBuyerMaker:  t.IsBuyerMaker,
+		})
+	}
+	_, err := p.trades.Write(rows)
+	return err
+}
+
+func (p *parquetWriter) Close() error {
+	switch {
+	case p.klines != nil:
+		return p.klines.Close()
+	case p.trades != nil:
+		return p.trades.Close()
+	}
+	return nil
+}
+
+// parseFloat returns 0 for an empty or malformed value, which only happens
+// for columns the source did not fill, like the volumes of stored klines.
+func parseFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
@@ -0,0 +1,188 @@
+// Package features computes microstructure features of a symbol from its
+// live order book and trade stream.
+package features
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/feed"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/trades"
+)
+
+// Features are the microstructure features of one symbol over one window.
+// Spreads are in bps of the mid. OFI is the order flow imbalance at the top
+// of the book summed over the window, TradeImbalance is (buy volume - sell
+// volume) / total volume using the aggressor side, RealizedVol is the square
+// root of the summed squared log returns of trade prices and TradeIntensity
+// is trades per second.
+type Features struct {
+	Symbol             string  `json:"symbol"`
+	Time               int64   `json:"time"`
+	Mid                float64 `json:"mid"`
+	Microprice         float64 `json:"microprice"`
+	QuotedSpreadBps    float64 `json:"quotedSpreadBps"`
+	EffectiveSpreadBps float64 `json:"effectiveSpreadBps"`
+	OFI                float64 `json:"ofi"`
+	TradeImbalance     float64 `json:"tradeImbalance"`
+	RealizedVol        float64 `json:"realizedVol"`
+	TradeIntensity     float64 `json:"tradeIntensity"`
+	Trades             int     `json:"trades"`
+}
+
+// Engine combines the live book and trade stream of one symbol and emits
+// Features every window.
+type Engine struct {
+	orderBook *orderbook.Book
+	trades    *trades.List
+	feed      feed.Feed[Features]
+
+	prevBid, prevAsk orderbook.PriceLevel
+	hasPrev          bool
+	lastMid          float64
+	lastPrice        float64
+	windowStart      time.Time
+
+	ofi             float64
+	buyVolume       float64
+	sellVolume      float64
+	effSpreadSum    float64
+	effSpreadTrades int
+	squaredReturns  float64
+	tradeCount      int
+}
+
+func NewEngine(orderBook *orderbook.Book, trades *trades.List) *Engine {
+	return &Engine{
+		orderBook: orderBook,
+		trades:    trades,
+	}
+}
+
+// Subscribe streams the features emitted after the call.
+func (e *Engine) Subscribe(buf int) (<-chan Features, func()) {
+	return e.feed.Subscribe(buf)
+}
+
+// Run computes the features every interval until ctx is done.
+func (e *Engine) Run(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	bookch, unsubBook := e.orderBook.Subscribe(100)
+	tradech, unsubTrades := e.trades.Subscribe(1000)
+	ticker := clock.NewTicker(interval)
+	e.windowStart = clock.Now()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer ticker.Stop()
+		defer unsubBook()
+		defer unsubTrades()
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("features engine is finished", "symbol", e.orderBook.Symbol)
+				return
+			case _, ok := <-bookch:
+				if !ok {
+					return
+				}
+				e.onBook()
+			case t, ok := <-tradech:
+				if !ok {
+					return
+				}
+				e.onTrade(t)
+			case now := <-ticker.C:
+				e.feed.Publish(e.emit(now))
+			}
+		}
+	}()
+}
+
+func (e *Engine) onBook() {
+	bid, ask, ok := e.orderBook.TopLevels()
+	if !ok {
+		return
+	}
+	e.lastMid = (bid.Price + ask.Price) / 2
+	if e.hasPrev {
+		if bid.Price >= e.prevBid.Price {
+			e.ofi += bid.Quantity
+		}
+		if bid.Price <= e.prevBid.Price {
+			e.ofi -= e.prevBid.Quantity
+		}
+		if ask.Price <= e.prevAsk.Price {
+			e.ofi -= ask.Quantity
+		}
+		if ask.Price >= e.prevAsk.Price {
+			e.ofi += e.prevAsk.Quantity
+		}
+	}
+	e.prevBid, e.prevAsk, e.hasPrev = bid, ask, true
+}
+
+func (e *Engine) onTrade(t trades.Trade) {
+	price, err := strconv.ParseFloat(t.Price, 64)
+	if err != nil {
+		return
+	}
+	qty, err := strconv.ParseFloat(t.Quantity, 64)
+	if err != nil {
+		return
+	}
+	e.tradeCount++
+	// the maker is the passive side, so a buyer maker print was sell initiated
+	if t.IsBuyerMaker {
+		e.sellVolume += qty
+	} else {
+		e.buyVolume += qty
+	}
+	if e.lastMid > 0 {
+		e.effSpreadSum += 2 * math.Abs(price-e.lastMid) / e.lastMid * 10000
+		e.effSpreadTrades++
+	}
+	if e.lastPrice > 0 {
+		r := math.Log(price / e.lastPrice)
+		e.squaredReturns += r * r
+	}
+	e.lastPrice = price
+}
+
+// emit builds the features of the window ending at now and starts a new one.
+func (e *Engine) emit(now time.Time) Features {
+	f := Features{
+		Symbol:      e.orderBook.Symbol,
+		Time:        now.UnixMilli(),
+		OFI:         e.ofi,
+		RealizedVol: math.Sqrt(e.squaredReturns),
+		Trades:      e.tradeCount,
+	}
+	if bid, ask, ok := e.orderBook.TopLevels(); ok {
+		f.Mid = (bid.Price + ask.Price) / 2
+		f.QuotedSpreadBps = (ask.Price - bid.Price) / f.Mid * 10000
+		if bid.Quantity+ask.Quantity > 0 {
+			f.Microprice = (bid.Price*ask.Quantity + ask.Price*bid.Quantity) / (bid.Quantity + ask.Quantity)
+		}
+	}
+	if e.effSpreadTrades > 0 {
+		f.EffectiveSpreadBps = e.effSpreadSum / float64(e.effSpreadTrades)
+	}
+	if total := e.buyVolume + e.sellVolume; total > 0 {
+		f.TradeImbalance = (e.buyVolume - e.sellVolume) / total
+	}
+	if elapsed := now.Sub(e.windowStart).Seconds(); elapsed > 0 {
+		f.TradeIntensity = float64(e.tradeCount) / elapsed
+	}
+
+	e.windowStart = now
+	e.ofi, e.buyVolume, e.sellVolume, e.squaredReturns = 0, 0, 0, 0
+	e.effSpreadSum, e.effSpreadTrades, e.tradeCount = 0, 0, 0
+	return f
+}
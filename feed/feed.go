@@ -0,0 +1,59 @@
+// Package feed fans values out to subscribers without blocking the
+// publisher.
+package feed
+
+import "sync"
+
+// Feed fans values out to any number of subscribers. Publish never blocks:
+// a value is dropped for a subscriber whose buffer is full, so a slow
+// consumer cannot stall the pipeline that publishes.
+type Feed[T any] struct {
+	mu sync.Mutex
+	// subs holds the values dropped for each subscriber.
+	subs map[chan T]int64
+}
+
+// Subscribe returns a channel receiving every published value and a function
+// that unsubscribes and closes the channel.
+func (f *Feed[T]) Subscribe(buf int) (<-chan T, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.subs == nil {
+		f.subs = make(map[chan T]int64)
+	}
+	ch := make(chan T, buf)
+	f.subs[ch] = 0
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.subs[ch]; ok {
+			delete(f.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// Publish sends v to every subscriber with room in its buffer.
+func (f *Feed[T]) Publish(v T) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.subs {
+		select {
+		case ch <- v:
+		default:
+			f.subs[ch]++
+		}
+	}
+}
+
+// Dropped returns the values dropped so far for the subscriber reading ch.
+func (f *Feed[T]) Dropped(ch <-chan T) int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for c, n := range f.subs {
+		if c == ch {
+			return n
+		}
+	}
+	return 0
+}
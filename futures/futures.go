@@ -0,0 +1,39 @@
+// Package futures holds the models only perpetual futures have: mark price
+// and funding, open interest and liquidations. Prices and quantities are the
+// decimal strings the exchange sends, times are in milliseconds.
+package futures
+
+// MarkPrice is a mark price update, with the funding rate that applies at
+// NextFundingTime.
+type MarkPrice struct {
+	Symbol               string `json:"symbol"`
+	Time                 int64  `json:"time"`
+	MarkPrice            string `json:"markPrice"`
+	IndexPrice           string `json:"indexPrice"`
+	EstimatedSettlePrice string `json:"estimatedSettlePrice"`
+	FundingRate          string `json:"fundingRate"`
+	NextFundingTime      int64  `json:"nextFundingTime"`
+}
+
+// OpenInterest is the number of open contracts of a symbol at Time.
+type OpenInterest struct {
+	Symbol       string `json:"symbol"`
+	Time         int64  `json:"time"`
+	OpenInterest string `json:"openInterest"`
+}
+
+// Liquidation is a forced order. Side is the side of the liquidation order,
+// so a SELL closes a long position.
+type Liquidation struct {
+	Symbol         string `json:"symbol"`
+	Time           int64  `json:"time"`
+	Side           string `json:"side"`
+	OrderType      string `json:"orderType"`
+	TimeInForce    string `json:"timeInForce"`
+	Quantity       string `json:"qty"`
+	Price          string `json:"price"`
+	AveragePrice   string `json:"avgPrice"`
+	Status         string `json:"status"`
+	LastFilledQty  string `json:"lastFilledQty"`
+	FilledQuantity string `json:"filledQty"`
+}
@@ -0,0 +1,199 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+)
+
+// window is a fixed size window of values with their sum and sum of
+// squares.
+type window struct {
+	values []float64
+	next   int
+	full   bool
+	sum    float64
+	sumSq  float64
+}
+
+func newWindow(n int) *window {
+	return &window{values: make([]float64, max(n, 1))}
+}
+
+// sums returns the sums the window would have with v pushed and whether it
+// would be full.
+func (w *window) sums(v float64) (sum, sumSq float64, full bool) {
+	old := 0.0
+	if w.full {
+		old = w.values[w.next]
+	}
+	return w.sum - old + v, w.sumSq - old*old + v*v, w.full || w.next == len(w.values)-1
+}
+
+func (w *window) push(v float64) {
+	w.sum, w.sumSq, _ = w.sums(v)
+	w.values[w.next] = v
+	w.next++
+	if w.next == len(w.values) {
+		w.next = 0
+		w.full = true
+	}
+}
+
+// SMA is the simple moving average of the close over Period candles.
+type SMA struct {
+	Period int
+	w      *window
+}
+
+func NewSMA(period int) *SMA {
+	return &SMA{Period: period, w: newWindow(period)}
+}
+
+func (s *SMA) Name() string { return fmt.Sprintf("sma_%d", s.Period) }
+
+func (s *SMA) Peek(c Candle) Value {
+	sum, _, full := s.w.sums(c.Close)
+	if !full {
+		return nil
+	}
+	return Value{"value": sum / float64(s.Period)}
+}
+
+func (s *SMA) Update(c Candle) Value {
+	v := s.Peek(c)
+	s.w.push(c.Close)
+	return v
+}
+
+// ema is an exponential moving average seeded with the simple average of
+// its first period values.
+type ema struct {
+	period int
+	k      float64
+	n      int
+	sum    float64
+	value  float64
+}
+
+func newEMA(period int) ema {
+	return ema{period: period, k: 2 / float64(period+1)}
+}
+
+// next returns the average with v added and whether it is seeded.
+func (e ema) next(v float64) (float64, bool) {
+	switch {
+	case e.n+1 < e.period:
+		return 0, false
+	case e.n+1 == e.period:
+		return (e.sum + v) / float64(e.period), true
+	default:
+		return e.value + e.k*(v-e.value), true
+	}
+}
+
+func (e *ema) push(v float64) (float64, bool) {
+	value, ok := e.next(v)
+	e.n++
+	e.sum += v
+	e.value = value
+	return value, ok
+}
+
+// EMA is the exponential moving average of the close over Period candles.
+type EMA struct {
+	Period int
+	e      ema
+}
+
+func NewEMA(period int) *EMA {
+	return &EMA{Period: period, e: newEMA(period)}
+}
+
+func (e *EMA) Name() string { return fmt.Sprintf("ema_%d", e.Period) }
+
+func (e *EMA) Peek(c Candle) Value {
+	v, ok := e.e.next(c.Close)
+	if !ok {
+		return nil
+	}
+	return Value{"value": v}
+}
+
+func (e *EMA) Update(c Candle) Value {
+	v, ok := e.e.push(c.Close)
+	if !ok {
+		return nil
+	}
+	return Value{"value": v}
+}
+
+// MACD is the difference of a Fast and a Slow EMA of the close, with its
+// Signal EMA and the histogram between them.
+type MACD struct {
+	Fast, Slow, Signal int
+	fast, slow, signal ema
+}
+
+func NewMACD(fast, slow, signal int) *MACD {
+	return &MACD{Fast: fast, Slow: slow, Signal: signal, fast: newEMA(fast), slow: newEMA(slow), signal: newEMA(signal)}
+}
+
+func (m *MACD) Name() string { return fmt.Sprintf("macd_%d_%d_%d", m.Fast, m.Slow, m.Signal) }
+
+func macdValue(macd, signal float64, ok bool) Value {
+	if !ok {
+		return nil
+	}
+	return Value{"macd": macd, "signal": signal, "histogram": macd - signal}
+}
+
+func (m *MACD) Peek(c Candle) Value {
+	fast, ok1 := m.fast.next(c.Close)
+	slow, ok2 := m.slow.next(c.Close)
+	if !ok1 || !ok2 {
+		return nil
+	}
+	signal, ok := m.signal.next(fast - slow)
+	return macdValue(fast-slow, signal, ok)
+}
+
+func (m *MACD) Update(c Candle) Value {
+	fast, ok1 := m.fast.push(c.Close)
+	slow, ok2 := m.slow.push(c.Close)
+	if !ok1 || !ok2 {
+		return nil
+	}
+	signal, ok := m.signal.push(fast - slow)
+	return macdValue(fast-slow, signal, ok)
+}
+
+// Bollinger is the SMA of the close over Period candles with bands K
+// standard deviations above and below it.
+type Bollinger struct {
+	Period int
+	K      float64
+	w      *window
+}
+
+func NewBollinger(period int, k float64) *Bollinger {
+	return &Bollinger{Period: period, K: k, w: newWindow(period)}
+}
+
+func (b *Bollinger) Name() string { return fmt.Sprintf("bb_%d_%g", b.Period, b.K) }
+
+func (b *Bollinger) Peek(c Candle) Value {
+	sum, sumSq, full := b.w.sums(c.Close)
+	if !full {
+		return nil
+	}
+	n := float64(b.Period)
+	mean := sum / n
+	sd := math.Sqrt(math.Max(sumSq/n-mean*mean, 0))
+	return Value{"middle": mean, "upper": mean + b.K*sd, "lower": mean - b.K*sd}
+}
+
+func (b *Bollinger) Update(c Candle) Value {
+	v := b.Peek(c)
+	b.w.push(c.Close)
+	return v
+}
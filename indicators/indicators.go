@@ -0,0 +1,112 @@
+// Package indicators computes technical indicators incrementally over the
+// klines of a symbol. Closed candles move an indicator forward; the running
+// candle only gives a preview of the value it would have if it closed now,
+// so the live value follows every kline update without disturbing the
+// state.
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"test.bhft.com/klines"
+)
+
+// Value is the output of an indicator for one candle, by field. Single value
+// indicators use the field "value". It is nil while the indicator is still
+// warming up.
+type Value map[string]float64
+
+// Indicator is one technical indicator. Update moves it forward by a closed
+// candle and returns its value; Peek returns the value the candle would
+// give without moving it.
+type Indicator interface {
+	Name() string
+	Update(c Candle) Value
+	Peek(c Candle) Value
+}
+
+// Candle is a kline with its prices and volumes parsed.
+type Candle struct {
+	OpenTime    int64
+	Open        float64
+	High        float64
+	Low         float64
+	Close       float64
+	Volume      float64
+	QuoteVolume float64
+}
+
+// NewCandle parses k.
+func NewCandle(k klines.Kline) Candle {
+	f := func(s string) float64 {
+		v, _ := strconv.ParseFloat(s, 64)
+		return v
+	}
+	return Candle{
+		OpenTime:    k.OpenTime,
+		Open:        f(k.Open),
+		High:        f(k.High),
+		Low:         f(k.Low),
+		Close:       f(k.Close),
+		Volume:      f(k.Volume),
+		QuoteVolume: f(k.QuoteAssetVolume),
+	}
+}
+
+// Parse builds an indicator from a spec like sma:20, ema:50, rsi:14,
+// macd:12:26:9, bb:20:2, atr:14, vwap or obv. Parameters left out take the
+// usual defaults.
+func Parse(spec string) (Indicator, error) {
+	kind, rest, _ := strings.Cut(strings.ToLower(strings.TrimSpace(spec)), ":")
+	var params []float64
+	if rest != "" {
+		for _, p := range strings.Split(rest, ":") {
+			v, err := strconv.ParseFloat(p, 64)
+			if err != nil || v <= 0 {
+				return nil, fmt.Errorf("%s: invalid parameter %q", spec, p)
+			}
+			params = append(params, v)
+		}
+	}
+	param := func(i int, def float64) float64 {
+		if i < len(params) {
+			return params[i]
+		}
+		return def
+	}
+	maxParams := map[string]int{"sma": 1, "ema": 1, "rsi": 1, "macd": 3, "bb": 2, "atr": 1, "vwap": 0, "obv": 0}
+	n, ok := maxParams[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown indicator %q", spec)
+	}
+	if len(params) > n {
+		return nil, fmt.Errorf("%s: too many parameters", spec)
+	}
+	// Every parameter is a number of candles but the width of the bands.
+	for i, v := range params {
+		if v != math.Trunc(v) && (kind != "bb" || i == 0) {
+			return nil, fmt.Errorf("%s: period %v is not a whole number", spec, v)
+		}
+	}
+	switch kind {
+	case "sma":
+		return NewSMA(int(param(0, 20))), nil
+	case "ema":
+		return NewEMA(int(param(0, 20))), nil
+	case "rsi":
+		return NewRSI(int(param(0, 14))), nil
+	case "macd":
+		return NewMACD(int(param(0, 12)), int(param(1, 26)), int(param(2, 9))), nil
+	case "bb":
+		return NewBollinger(int(param(0, 20)), param(1, 2)), nil
+	case "atr":
+		return NewATR(int(param(0, 14))), nil
+	case "vwap":
+		return NewVWAP(), nil
+	default:
+		return NewOBV(), nil
+	}
+}
@@ -0,0 +1,112 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+// candles closes at 10, 11, 12, 11, 13 and 14.
+var candles = []Candle{
+	{High: 11, Low: 9, Close: 10},
+	{High: 12, Low: 10, Close: 11},
+	{High: 13, Low: 11.5, Close: 12},
+	{High: 12.5, Low: 11, Close: 11},
+	{High: 14, Low: 12, Close: 13},
+	{High: 14.5, Low: 13.5, Close: 14},
+}
+
+// none marks a candle the indicator is still warming up on.
+var none = math.NaN()
+
+// TestIndicators checks every indicator against values worked out by hand
+// over candles, and that Peek previews the value Update then returns.
+func TestIndicators(t *testing.T) {
+	tests := []struct {
+		ind   Indicator
+		field string
+		want  []float64
+	}{
+		{NewSMA(3), "value", []float64{none, none, 11, 11.333333, 12, 12.666667}},
+		// k = 0.5, seeded with the SMA of the first three closes.
+		{NewEMA(3), "value", []float64{none, none, 11, 11, 12, 13}},
+		// EMA 2 minus EMA 3: 0.5, 0.166667, 0.388889 and 0.462963 from the
+		// third candle, the signal is their EMA 2.
+		{NewMACD(2, 3, 2), "macd", []float64{none, none, none, 0.166667, 0.388889, 0.462963}},
+		{NewMACD(2, 3, 2), "signal", []float64{none, none, none, 0.333333, 0.370370, 0.432099}},
+		{NewMACD(2, 3, 2), "histogram", []float64{none, none, none, -0.166667, 0.018519, 0.030864}},
+		// Population standard deviation of the last three closes.
+		{NewBollinger(3, 2), "middle", []float64{none, none, 11, 11.333333, 12, 12.666667}},
+		{NewBollinger(3, 2), "upper", []float64{none, none, 12.632993, 12.276142, 13.632993, 15.161105}},
+		{NewBollinger(3, 2), "lower", []float64{none, none, 9.367007, 10.390524, 10.367007, 10.172229}},
+		// Gains 1, 1, 0, 2, 1 and losses 0, 0, 1, 0, 0 with Wilder smoothing.
+		{NewRSI(3), "value", []float64{none, none, none, 66.666667, 83.333333, 87.878788}},
+		// True ranges 2, 2, 2, 1.5, 3 and 1.5 with Wilder smoothing.
+		{NewATR(3), "value", []float64{none, none, 2, 1.833333, 2.222222, 1.981481}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ind.Name()+"/"+tt.field, func(t *testing.T) {
+			for i, c := range candles {
+				peek := tt.ind.Peek(c)
+				got := tt.ind.Update(c)
+				if math.IsNaN(tt.want[i]) {
+					if got != nil || peek != nil {
+						t.Errorf("candle %d: got %v, peek %v, want none", i, got, peek)
+					}
+					continue
+				}
+				if got == nil {
+					t.Errorf("candle %d: got none, want %.6f", i, tt.want[i])
+					continue
+				}
+				if math.Abs(got[tt.field]-tt.want[i]) > 1e-6 {
+					t.Errorf("candle %d: got %.6f, want %.6f", i, got[tt.field], tt.want[i])
+				}
+				if peek[tt.field] != got[tt.field] {
+					t.Errorf("candle %d: peek %.6f, update %.6f", i, peek[tt.field], got[tt.field])
+				}
+			}
+		})
+	}
+}
+
+func TestRSIWithoutLosses(t *testing.T) {
+	r := NewRSI(2)
+	var got Value
+	for _, c := range []float64{1, 2, 3, 4} {
+		got = r.Update(Candle{Close: c})
+	}
+	if got["value"] != 100 {
+		t.Errorf("got %v, want 100", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec string
+		name string // empty when the spec is rejected
+	}{
+		{spec: "sma:20", name: "sma_20"},
+		{spec: "EMA", name: "ema_20"},
+		{spec: "bb:20:2.5", name: "bb_20_2.5"},
+		{spec: "macd:12:26:9", name: "macd_12_26_9"},
+		{spec: "sma:0.5"},
+		{spec: "ema:2.5"},
+		{spec: "bb:20.5:2"},
+		{spec: "macd:12:26.5:9"},
+		{spec: "rsi:0"},
+		{spec: "atr:14:3"},
+		{spec: "vwap:1"},
+		{spec: "kama:10"},
+	}
+	for _, tt := range tests {
+		ind, err := Parse(tt.spec)
+		switch {
+		case tt.name == "" && err == nil:
+			t.Errorf("%s: parsed as %s, want an error", tt.spec, ind.Name())
+		case tt.name != "" && err != nil:
+			t.Errorf("%s: %v", tt.spec, err)
+		case tt.name != "" && ind.Name() != tt.name:
+			t.Errorf("%s: parsed as %s, want %s", tt.spec, ind.Name(), tt.name)
+		}
+	}
+}
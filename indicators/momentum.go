@@ -0,0 +1,215 @@
+package indicators
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// wilder is a Wilder smoothed average, seeded with the simple average of
+// its first period values.
+type wilder struct {
+	period int
+	n      int
+	sum    float64
+	value  float64
+}
+
+func (w wilder) next(v float64) (float64, bool) {
+	switch {
+	case w.n+1 < w.period:
+		return 0, false
+	case w.n+1 == w.period:
+		return (w.sum + v) / float64(w.period), true
+	default:
+		return (w.value*float64(w.period-1) + v) / float64(w.period), true
+	}
+}
+
+func (w *wilder) push(v float64) (float64, bool) {
+	value, ok := w.next(v)
+	w.n++
+	w.sum += v
+	w.value = value
+	return value, ok
+}
+
+// RSI is the relative strength index of the close over Period candles with
+// Wilder smoothing, from 0 to 100.
+type RSI struct {
+	Period     int
+	gain, loss wilder
+	prevClose  float64
+	started    bool
+}
+
+func NewRSI(period int) *RSI {
+	return &RSI{Period: period, gain: wilder{period: period}, loss: wilder{period: period}}
+}
+
+func (r *RSI) Name() string { return fmt.Sprintf("rsi_%d", r.Period) }
+
+func (r *RSI) moves(c Candle) (gain, loss float64) {
+	change := c.Close - r.prevClose
+	return math.Max(change, 0), math.Max(-change, 0)
+}
+
+func rsiValue(gain, loss float64) Value {
+	if loss == 0 {
+		return Value{"value": 100}
+	}
+	return Value{"value": 100 - 100/(1+gain/loss)}
+}
+
+func (r *RSI) Peek(c Candle) Value {
+	if !r.started {
+		return nil
+	}
+	g, l := r.moves(c)
+	gain, ok := r.gain.next(g)
+	loss, _ := r.loss.next(l)
+	if !ok {
+		return nil
+	}
+	return rsiValue(gain, loss)
+}
+
+func (r *RSI) Update(c Candle) Value {
+	if !r.started {
+		r.started = true
+		r.prevClose = c.Close
+		return nil
+	}
+	g, l := r.moves(c)
+	r.prevClose = c.Close
+	gain, ok := r.gain.push(g)
+	loss, _ := r.loss.push(l)
+	if !ok {
+		return nil
+	}
+	return rsiValue(gain, loss)
+}
+
+// ATR is the average true range over Period candles with Wilder smoothing.
+type ATR struct {
+	Period    int
+	avg       wilder
+	prevClose float64
+	started   bool
+}
+
+func NewATR(period int) *ATR {
+	return &ATR{Period: period, avg: wilder{period: period}}
+}
+
+func (a *ATR) Name() string { return fmt.Sprintf("atr_%d", a.Period) }
+
+func (a *ATR) trueRange(c Candle) float64 {
+	if !a.started {
+		return c.High - c.Low
+	}
+	return math.Max(c.High-c.Low, math.Max(math.Abs(c.High-a.prevClose), math.Abs(c.Low-a.prevClose)))
+}
+
+func (a *ATR) Peek(c Candle) Value {
+	v, ok := a.avg.next(a.trueRange(c))
+	if !ok {
+		return nil
+	}
+	return Value{"value": v}
+}
+
+func (a *ATR) Update(c Candle) Value {
+	v, ok := a.avg.push(a.trueRange(c))
+	a.prevClose = c.Close
+	a.started = true
+	if !ok {
+		return nil
+	}
+	return Value{"value": v}
+}
+
+// day is the length of a VWAP session.
+const day = int64(24 * time.Hour / time.Millisecond)
+
+// VWAP is the volume weighted average price since UTC midnight. Each candle
+// counts at its own average price, its quote volume over its volume, or its
+// typical price when the venue sends no quote volume.
+type VWAP struct {
+	session          int64
+	volume, notional float64
+}
+
+func NewVWAP() *VWAP {
+	return &VWAP{session: -1}
+}
+
+func (v *VWAP) Name() string { return "vwap" }
+
+func (v *VWAP) next(c Candle) (volume, notional float64) {
+	volume, notional = v.volume, v.notional
+	if c.OpenTime/day != v.session {
+		volume, notional = 0, 0
+	}
+	volume += c.Volume
+	if c.QuoteVolume > 0 {
+		notional += c.QuoteVolume
+	} else {
+		notional += (c.High + c.Low + c.Close) / 3 * c.Volume
+	}
+	return volume, notional
+}
+
+func vwapValue(volume, notional float64) Value {
+	if volume == 0 {
+		return nil
+	}
+	return Value{"value": notional / volume}
+}
+
+func (v *VWAP) Peek(c Candle) Value {
+	return vwapValue(v.next(c))
+}
+
+func (v *VWAP) Update(c Candle) Value {
+	v.volume, v.notional = v.next(c)
+	v.session = c.OpenTime / day
+	return vwapValue(v.volume, v.notional)
+}
+
+// OBV is the on balance volume: the volume of every candle added when it
+// closed up and subtracted when it closed down.
+type OBV struct {
+	value     float64
+	prevClose float64
+	started   bool
+}
+
+func NewOBV() *OBV {
+	return &OBV{}
+}
+
+func (o *OBV) Name() string { return "obv" }
+
+func (o *OBV) next(c Candle) float64 {
+	switch {
+	case !o.started:
+		return 0
+	case c.Close > o.prevClose:
+		return o.value + c.Volume
+	case c.Close < o.prevClose:
+		return o.value - c.Volume
+	}
+	return o.value
+}
+
+func (o *OBV) Peek(c Candle) Value {
+	return Value{"value": o.next(c)}
+}
+
+func (o *OBV) Update(c Candle) Value {
+	o.value = o.next(c)
+	o.prevClose = c.Close
+	o.started = true
+	return Value{"value": o.value}
+}
@@ -0,0 +1,85 @@
+package indicators
+
+import (
+	"sync"
+
+	"test.bhft.com/klines"
+)
+
+// Point is the value of an indicator on the candle opened at OpenTime.
+// Closed is false for the live value of the running candle.
+type Point struct {
+	Indicator string `json:"indicator"`
+	OpenTime  int64  `json:"openTime"`
+	Closed    bool   `json:"closed"`
+	Value     Value  `json:"value"`
+}
+
+// Set runs indicators over the klines of one symbol and interval. It keeps
+// the last closed and the live point of every indicator.
+type Set struct {
+	sync.Mutex
+	Symbol     string
+	Interval   string
+	indicators []Indicator
+	lastClosed int64
+	closed     map[string]Point
+	live       map[string]Point
+}
+
+func NewSet(symbol, interval string, indicators []Indicator) *Set {
+	return &Set{
+		Symbol:     symbol,
+		Interval:   interval,
+		indicators: indicators,
+		lastClosed: -1,
+		closed:     make(map[string]Point),
+		live:       make(map[string]Point),
+	}
+}
+
+// Update feeds a kline to the indicators. A closed kline moves them forward
+// and its points are returned, once; closed klines not after the last one
+// are skipped. Other klines update the live points.
+func (s *Set) Update(k klines.Kline, closed bool) []Point {
+	s.Lock()
+	defer s.Unlock()
+	if k.OpenTime <= s.lastClosed {
+		return nil
+	}
+	c := NewCandle(k)
+	if !closed {
+		for _, ind := range s.indicators {
+			s.live[ind.Name()] = Point{Indicator: ind.Name(), OpenTime: k.OpenTime, Value: ind.Peek(c)}
+		}
+		return nil
+	}
+	s.lastClosed = k.OpenTime
+	points := make([]Point, 0, len(s.indicators))
+	for _, ind := range s.indicators {
+		p := Point{Indicator: ind.Name(), OpenTime: k.OpenTime, Closed: true, Value: ind.Update(c)}
+		s.closed[ind.Name()] = p
+		delete(s.live, ind.Name())
+		if p.Value != nil {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// State returns the last closed point of every indicator, followed by its
+// live point when the running candle was seen.
+func (s *Set) State() []Point {
+	s.Lock()
+	defer s.Unlock()
+	var res []Point
+	for _, ind := range s.indicators {
+		if p, ok := s.closed[ind.Name()]; ok {
+			res = append(res, p)
+		}
+		if p, ok := s.live[ind.Name()]; ok {
+			res = append(res, p)
+		}
+	}
+	return res
+}
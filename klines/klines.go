@@ -0,0 +1,101 @@
+// Package klines keeps the candles of a symbol and interval and streams
+// their updates.
+package klines
+
+import (
+	"sync"
+
+	"test.bhft.com/feed"
+)
+
+// Kline is one candle. Prices and volumes are the decimal strings the
+// exchange sends, times are in milliseconds.
+type Kline struct {
+	OpenTime                 int64  `json:"openTime"`
+	Open                     string `json:"open"`
+	High                     string `json:"high"`
+	Low                      string `json:"low"`
+	Close                    string `json:"close"`
+	Volume                   string `json:"volume"`
+	CloseTime                int64  `json:"closeTime"`
+	QuoteAssetVolume         string `json:"quoteAssetVolume"`
+	NumberOfTrades           int64  `json:"numberOfTrades"`
+	TakerBuyBaseAssetVolume  string `json:"takerBuyBaseAssetVolume"`
+	TakerBuyQuoteAssetVolume string `json:"takerBuyQuoteAssetVolume"`
+}
+
+// List holds the candles of one symbol and interval, oldest first. Index is
+// the first candle that may have changed since it was last stored.
+type List struct {
+	sync.Mutex
+	Index    int
+	Symbol   string
+	Interval string
+	List     []Kline
+	feed     feed.Feed[Update]
+}
+
+// Update applies a kline update, replacing the last candle when it has the
+// same open time, and publishes it to the subscribers.
+func (kl *List) Update(u Update) {
+	kl.Lock()
+	defer kl.Unlock()
+
+	if len(kl.List) == 0 || kl.List[len(kl.List)-1].OpenTime != u.Kline.OpenTime {
+		kl.List = append(kl.List, u.Kline)
+	} else {
+		kl.List[len(kl.List)-1] = u.Kline
+	}
+	kl.feed.Publish(u)
+}
+
+// Update is a normalized kline stream event. Closed is set on the last
+// update of a candle.
+type Update struct {
+	Symbol   string `json:"symbol"`
+	Interval string `json:"interval"`
+	Kline    Kline  `json:"kline"`
+	Closed   bool   `json:"closed"`
+}
+
+// Subscribe streams every kline update received after the call.
+func (kl *List) Subscribe(buf int) (<-chan Update, func()) {
+	return kl.feed.Subscribe(buf)
+}
+
+// GetToInsert returns the candles to store: the ones added since the last
+// call and the last one again, which may still be open. A candle is
+// returned a last time with its final values once a newer one follows it.
+func (kl *List) GetToInsert() []Kline {
+	kl.Lock()
+	defer kl.Unlock()
+	if len(kl.List) == 0 {
+		return nil
+	}
+	start := min(kl.Index, len(kl.List)-1)
+	klines := append([]Kline(nil), kl.List[start:]...)
+	kl.Index = len(kl.List) - 1
+	return klines
+}
+
+// Range copies the klines opened between start and end inclusive, in
+// milliseconds.
+func (kl *List) Range(start, end int64) []Kline {
+	kl.Lock()
+	defer kl.Unlock()
+	var res []Kline
+	for _, k := range kl.List {
+		if k.OpenTime >= start && k.OpenTime <= end {
+			res = append(res, k)
+		}
+	}
+	return res
+}
+
+func New(symbol, interval string) *List {
+	return &List{
+		Symbol:   symbol,
+		Interval: interval,
+		List:     make([]Kline, 0),
+	}
+}
@@ -0,0 +1,5 @@
+// Package marketdatapb holds the protobuf messages and gRPC service of the
+// market data API.
+package marketdatapb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative marketdata.proto
@@ -0,0 +1,1264 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: marketdata.proto
+
+package marketdatapb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Level struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Price         string                 `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity      string                 `protobuf:"bytes,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Level) Reset() {
+	*x = Level{}
+	mi := &file_marketdata_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Level) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Level) ProtoMessage() {}
+
+func (x *Level) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Level.ProtoReflect.Descriptor instead.
+func (*Level) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Level) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Level) GetQuantity() string {
+	if x != nil {
+		return x.Quantity
+	}
+	return ""
+}
+
+type OrderBookSnapshot struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Symbol string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// time is in milliseconds since the epoch.
+	Time         int64 `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
+	LastUpdateId int64 `protobuf:"varint,3,opt,name=last_update_id,json=lastUpdateId,proto3" json:"last_update_id,omitempty"`
+	// bids are sorted from the best (highest) price, asks from the lowest.
+	Bids          []*Level `protobuf:"bytes,4,rep,name=bids,proto3" json:"bids,omitempty"`
+	Asks          []*Level `protobuf:"bytes,5,rep,name=asks,proto3" json:"asks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBookSnapshot) Reset() {
+	*x = OrderBookSnapshot{}
+	mi := &file_marketdata_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBookSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBookSnapshot) ProtoMessage() {}
+
+func (x *OrderBookSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBookSnapshot.ProtoReflect.Descriptor instead.
+func (*OrderBookSnapshot) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OrderBookSnapshot) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *OrderBookSnapshot) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *OrderBookSnapshot) GetLastUpdateId() int64 {
+	if x != nil {
+		return x.LastUpdateId
+	}
+	return 0
+}
+
+func (x *OrderBookSnapshot) GetBids() []*Level {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *OrderBookSnapshot) GetAsks() []*Level {
+	if x != nil {
+		return x.Asks
+	}
+	return nil
+}
+
+type OrderBookDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	EventTime     int64                  `protobuf:"varint,2,opt,name=event_time,json=eventTime,proto3" json:"event_time,omitempty"`
+	FirstUpdateId int64                  `protobuf:"varint,3,opt,name=first_update_id,json=firstUpdateId,proto3" json:"first_update_id,omitempty"`
+	FinalUpdateId int64                  `protobuf:"varint,4,opt,name=final_update_id,json=finalUpdateId,proto3" json:"final_update_id,omitempty"`
+	// a level with a zero quantity is removed from the book.
+	Bids          []*Level `protobuf:"bytes,5,rep,name=bids,proto3" json:"bids,omitempty"`
+	Asks          []*Level `protobuf:"bytes,6,rep,name=asks,proto3" json:"asks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBookDelta) Reset() {
+	*x = OrderBookDelta{}
+	mi := &file_marketdata_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBookDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBookDelta) ProtoMessage() {}
+
+func (x *OrderBookDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBookDelta.ProtoReflect.Descriptor instead.
+func (*OrderBookDelta) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OrderBookDelta) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *OrderBookDelta) GetEventTime() int64 {
+	if x != nil {
+		return x.EventTime
+	}
+	return 0
+}
+
+func (x *OrderBookDelta) GetFirstUpdateId() int64 {
+	if x != nil {
+		return x.FirstUpdateId
+	}
+	return 0
+}
+
+func (x *OrderBookDelta) GetFinalUpdateId() int64 {
+	if x != nil {
+		return x.FinalUpdateId
+	}
+	return 0
+}
+
+func (x *OrderBookDelta) GetBids() []*Level {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *OrderBookDelta) GetAsks() []*Level {
+	if x != nil {
+		return x.Asks
+	}
+	return nil
+}
+
+type OrderBookEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*OrderBookEvent_Snapshot
+	//	*OrderBookEvent_Delta
+	Event         isOrderBookEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBookEvent) Reset() {
+	*x = OrderBookEvent{}
+	mi := &file_marketdata_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBookEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBookEvent) ProtoMessage() {}
+
+func (x *OrderBookEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBookEvent.ProtoReflect.Descriptor instead.
+func (*OrderBookEvent) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OrderBookEvent) GetEvent() isOrderBookEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *OrderBookEvent) GetSnapshot() *OrderBookSnapshot {
+	if x != nil {
+		if x, ok := x.Event.(*OrderBookEvent_Snapshot); ok {
+			return x.Snapshot
+		}
+	}
+	return nil
+}
+
+func (x *OrderBookEvent) GetDelta() *OrderBookDelta {
+	if x != nil {
+		if x, ok := x.Event.(*OrderBookEvent_Delta); ok {
+			return x.Delta
+		}
+	}
+	return nil
+}
+
+type isOrderBookEvent_Event interface {
+	isOrderBookEvent_Event()
+}
+
+type OrderBookEvent_Snapshot struct {
+	Snapshot *OrderBookSnapshot `protobuf:"bytes,1,opt,name=snapshot,proto3,oneof"`
+}
+
+type OrderBookEvent_Delta struct {
+	Delta *OrderBookDelta `protobuf:"bytes,2,opt,name=delta,proto3,oneof"`
+}
+
+func (*OrderBookEvent_Snapshot) isOrderBookEvent_Event() {}
+
+func (*OrderBookEvent_Delta) isOrderBookEvent_Event() {}
+
+type Trade struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Id            int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Price         string                 `protobuf:"bytes,3,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity      string                 `protobuf:"bytes,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	QuoteQuantity string                 `protobuf:"bytes,5,opt,name=quote_quantity,json=quoteQuantity,proto3" json:"quote_quantity,omitempty"`
+	Time          int64                  `protobuf:"varint,6,opt,name=time,proto3" json:"time,omitempty"`
+	IsBuyerMaker  bool                   `protobuf:"varint,7,opt,name=is_buyer_maker,json=isBuyerMaker,proto3" json:"is_buyer_maker,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Trade) Reset() {
+	*x = Trade{}
+	mi := &file_marketdata_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trade) ProtoMessage() {}
+
+func (x *Trade) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trade.ProtoReflect.Descriptor instead.
+func (*Trade) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Trade) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Trade) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Trade) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Trade) GetQuantity() string {
+	if x != nil {
+		return x.Quantity
+	}
+	return ""
+}
+
+func (x *Trade) GetQuoteQuantity() string {
+	if x != nil {
+		return x.QuoteQuantity
+	}
+	return ""
+}
+
+func (x *Trade) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *Trade) GetIsBuyerMaker() bool {
+	if x != nil {
+		return x.IsBuyerMaker
+	}
+	return false
+}
+
+type Kline struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	OpenTime                 int64                  `protobuf:"varint,1,opt,name=open_time,json=openTime,proto3" json:"open_time,omitempty"`
+	CloseTime                int64                  `protobuf:"varint,2,opt,name=close_time,json=closeTime,proto3" json:"close_time,omitempty"`
+	Open                     string                 `protobuf:"bytes,3,opt,name=open,proto3" json:"open,omitempty"`
+	High                     string                 `protobuf:"bytes,4,opt,name=high,proto3" json:"high,omitempty"`
+	Low                      string                 `protobuf:"bytes,5,opt,name=low,proto3" json:"low,omitempty"`
+	Close                    string                 `protobuf:"bytes,6,opt,name=close,proto3" json:"close,omitempty"`
+	Volume                   string                 `protobuf:"bytes,7,opt,name=volume,proto3" json:"volume,omitempty"`
+	QuoteAssetVolume         string                 `protobuf:"bytes,8,opt,name=quote_asset_volume,json=quoteAssetVolume,proto3" json:"quote_asset_volume,omitempty"`
+	NumberOfTrades           int64                  `protobuf:"varint,9,opt,name=number_of_trades,json=numberOfTrades,proto3" json:"number_of_trades,omitempty"`
+	TakerBuyBaseAssetVolume  string                 `protobuf:"bytes,10,opt,name=taker_buy_base_asset_volume,json=takerBuyBaseAssetVolume,proto3" json:"taker_buy_base_asset_volume,omitempty"`
+	TakerBuyQuoteAssetVolume string                 `protobuf:"bytes,11,opt,name=taker_buy_quote_asset_volume,json=takerBuyQuoteAssetVolume,proto3" json:"taker_buy_quote_asset_volume,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *Kline) Reset() {
+	*x = Kline{}
+	mi := &file_marketdata_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Kline) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Kline) ProtoMessage() {}
+
+func (x *Kline) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Kline.ProtoReflect.Descriptor instead.
+func (*Kline) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Kline) GetOpenTime() int64 {
+	if x != nil {
+		return x.OpenTime
+	}
+	return 0
+}
+
+func (x *Kline) GetCloseTime() int64 {
+	if x != nil {
+		return x.CloseTime
+	}
+	return 0
+}
+
+func (x *Kline) GetOpen() string {
+	if x != nil {
+		return x.Open
+	}
+	return ""
+}
+
+func (x *Kline) GetHigh() string {
+	if x != nil {
+		return x.High
+	}
+	return ""
+}
+
+func (x *Kline) GetLow() string {
+	if x != nil {
+		return x.Low
+	}
+	return ""
+}
+
+func (x *Kline) GetClose() string {
+	if x != nil {
+		return x.Close
+	}
+	return ""
+}
+
+func (x *Kline) GetVolume() string {
+	if x != nil {
+		return x.Volume
+	}
+	return ""
+}
+
+func (x *Kline) GetQuoteAssetVolume() string {
+	if x != nil {
+		return x.QuoteAssetVolume
+	}
+	return ""
+}
+
+func (x *Kline) GetNumberOfTrades() int64 {
+	if x != nil {
+		return x.NumberOfTrades
+	}
+	return 0
+}
+
+func (x *Kline) GetTakerBuyBaseAssetVolume() string {
+	if x != nil {
+		return x.TakerBuyBaseAssetVolume
+	}
+	return ""
+}
+
+func (x *Kline) GetTakerBuyQuoteAssetVolume() string {
+	if x != nil {
+		return x.TakerBuyQuoteAssetVolume
+	}
+	return ""
+}
+
+type KlineUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Interval      string                 `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	Kline         *Kline                 `protobuf:"bytes,3,opt,name=kline,proto3" json:"kline,omitempty"`
+	Closed        bool                   `protobuf:"varint,4,opt,name=closed,proto3" json:"closed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KlineUpdate) Reset() {
+	*x = KlineUpdate{}
+	mi := &file_marketdata_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KlineUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KlineUpdate) ProtoMessage() {}
+
+func (x *KlineUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KlineUpdate.ProtoReflect.Descriptor instead.
+func (*KlineUpdate) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *KlineUpdate) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *KlineUpdate) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+func (x *KlineUpdate) GetKline() *Kline {
+	if x != nil {
+		return x.Kline
+	}
+	return nil
+}
+
+func (x *KlineUpdate) GetClosed() bool {
+	if x != nil {
+		return x.Closed
+	}
+	return false
+}
+
+type GetOrderBookRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Symbol string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	// depth is the number of levels per side, 0 returns the whole book.
+	Depth         int32 `protobuf:"varint,2,opt,name=depth,proto3" json:"depth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderBookRequest) Reset() {
+	*x = GetOrderBookRequest{}
+	mi := &file_marketdata_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderBookRequest) ProtoMessage() {}
+
+func (x *GetOrderBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderBookRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderBookRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetOrderBookRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *GetOrderBookRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+type GetOrderBookAtRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Time          int64                  `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
+	Depth         int32                  `protobuf:"varint,3,opt,name=depth,proto3" json:"depth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderBookAtRequest) Reset() {
+	*x = GetOrderBookAtRequest{}
+	mi := &file_marketdata_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderBookAtRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderBookAtRequest) ProtoMessage() {}
+
+func (x *GetOrderBookAtRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderBookAtRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderBookAtRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetOrderBookAtRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *GetOrderBookAtRequest) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *GetOrderBookAtRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+type GetTradesRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Symbol string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Limit  int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	// from_id returns trades starting at this ID instead of the latest ones,
+	// from storage when memory no longer holds it.
+	FromId        int64 `protobuf:"varint,3,opt,name=from_id,json=fromId,proto3" json:"from_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTradesRequest) Reset() {
+	*x = GetTradesRequest{}
+	mi := &file_marketdata_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTradesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTradesRequest) ProtoMessage() {}
+
+func (x *GetTradesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTradesRequest.ProtoReflect.Descriptor instead.
+func (*GetTradesRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetTradesRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *GetTradesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetTradesRequest) GetFromId() int64 {
+	if x != nil {
+		return x.FromId
+	}
+	return 0
+}
+
+type GetTradesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trades        []*Trade               `protobuf:"bytes,1,rep,name=trades,proto3" json:"trades,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTradesResponse) Reset() {
+	*x = GetTradesResponse{}
+	mi := &file_marketdata_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTradesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTradesResponse) ProtoMessage() {}
+
+func (x *GetTradesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTradesResponse.ProtoReflect.Descriptor instead.
+func (*GetTradesResponse) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetTradesResponse) GetTrades() []*Trade {
+	if x != nil {
+		return x.Trades
+	}
+	return nil
+}
+
+type GetKlinesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Interval      string                 `protobuf:"bytes,2,opt,name=interval,proto3" json:"interval,omitempty"`
+	Start         int64                  `protobuf:"varint,3,opt,name=start,proto3" json:"start,omitempty"`
+	End           int64                  `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetKlinesRequest) Reset() {
+	*x = GetKlinesRequest{}
+	mi := &file_marketdata_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetKlinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetKlinesRequest) ProtoMessage() {}
+
+func (x *GetKlinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetKlinesRequest.ProtoReflect.Descriptor instead.
+func (*GetKlinesRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetKlinesRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *GetKlinesRequest) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+func (x *GetKlinesRequest) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *GetKlinesRequest) GetEnd() int64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *GetKlinesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetKlinesResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Klines []*Kline               `protobuf:"bytes,1,rep,name=klines,proto3" json:"klines,omitempty"`
+	// next is the start of the following page, 0 on the last page.
+	Next          int64 `protobuf:"varint,2,opt,name=next,proto3" json:"next,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetKlinesResponse) Reset() {
+	*x = GetKlinesResponse{}
+	mi := &file_marketdata_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetKlinesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetKlinesResponse) ProtoMessage() {}
+
+func (x *GetKlinesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetKlinesResponse.ProtoReflect.Descriptor instead.
+func (*GetKlinesResponse) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetKlinesResponse) GetKlines() []*Kline {
+	if x != nil {
+		return x.Klines
+	}
+	return nil
+}
+
+func (x *GetKlinesResponse) GetNext() int64 {
+	if x != nil {
+		return x.Next
+	}
+	return 0
+}
+
+type SubscribeOrderBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeOrderBookRequest) Reset() {
+	*x = SubscribeOrderBookRequest{}
+	mi := &file_marketdata_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeOrderBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeOrderBookRequest) ProtoMessage() {}
+
+func (x *SubscribeOrderBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeOrderBookRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeOrderBookRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SubscribeOrderBookRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+type SubscribeTradesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeTradesRequest) Reset() {
+	*x = SubscribeTradesRequest{}
+	mi := &file_marketdata_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeTradesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeTradesRequest) ProtoMessage() {}
+
+func (x *SubscribeTradesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeTradesRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeTradesRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SubscribeTradesRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+type SubscribeKlinesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeKlinesRequest) Reset() {
+	*x = SubscribeKlinesRequest{}
+	mi := &file_marketdata_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeKlinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeKlinesRequest) ProtoMessage() {}
+
+func (x *SubscribeKlinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_marketdata_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeKlinesRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeKlinesRequest) Descriptor() ([]byte, []int) {
+	return file_marketdata_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SubscribeKlinesRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+var File_marketdata_proto protoreflect.FileDescriptor
+
+const file_marketdata_proto_rawDesc = "" +
+	"\n" +
+	"\x10marketdata.proto\x12\rmarketdata.v1\"9\n" +
+	"\x05Level\x12\x14\n" +
+	"\x05price\x18\x01 \x01(\tR\x05price\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\tR\bquantity\"\xb9\x01\n" +
+	"\x11OrderBookSnapshot\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\x03R\x04time\x12$\n" +
+	"\x0elast_update_id\x18\x03 \x01(\x03R\flastUpdateId\x12(\n" +
+	"\x04bids\x18\x04 \x03(\v2\x14.marketdata.v1.LevelR\x04bids\x12(\n" +
+	"\x04asks\x18\x05 \x03(\v2\x14.marketdata.v1.LevelR\x04asks\"\xeb\x01\n" +
+	"\x0eOrderBookDelta\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x1d\n" +
+	"\n" +
+	"event_time\x18\x02 \x01(\x03R\teventTime\x12&\n" +
+	"\x0ffirst_update_id\x18\x03 \x01(\x03R\rfirstUpdateId\x12&\n" +
+	"\x0ffinal_update_id\x18\x04 \x01(\x03R\rfinalUpdateId\x12(\n" +
+	"\x04bids\x18\x05 \x03(\v2\x14.marketdata.v1.LevelR\x04bids\x12(\n" +
+	"\x04asks\x18\x06 \x03(\v2\x14.marketdata.v1.LevelR\x04asks\"\x90\x01\n" +
+	"\x0eOrderBookEvent\x12>\n" +
+	"\bsnapshot\x18\x01 \x01(\v2 .marketdata.v1.OrderBookSnapshotH\x00R\bsnapshot\x125\n" +
+	"\x05delta\x18\x02 \x01(\v2\x1d.marketdata.v1.OrderBookDeltaH\x00R\x05deltaB\a\n" +
+	"\x05event\"\xc2\x01\n" +
+	"\x05Trade\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\tR\x05price\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\tR\bquantity\x12%\n" +
+	"\x0equote_quantity\x18\x05 \x01(\tR\rquoteQuantity\x12\x12\n" +
+	"\x04time\x18\x06 \x01(\x03R\x04time\x12$\n" +
+	"\x0eis_buyer_maker\x18\a \x01(\bR\fisBuyerMaker\"\x81\x03\n" +
+	"\x05Kline\x12\x1b\n" +
+	"\topen_time\x18\x01 \x01(\x03R\bopenTime\x12\x1d\n" +
+	"\n" +
+	"close_time\x18\x02 \x01(\x03R\tcloseTime\x12\x12\n" +
+	"\x04open\x18\x03 \x01(\tR\x04open\x12\x12\n" +
+	"\x04high\x18\x04 \x01(\tR\x04high\x12\x10\n" +
+	"\x03low\x18\x05 \x01(\tR\x03low\x12\x14\n" +
+	"\x05close\x18\x06 \x01(\tR\x05close\x12\x16\n" +
+	"\x06volume\x18\a \x01(\tR\x06volume\x12,\n" +
+	"\x12quote_asset_volume\x18\b \x01(\tR\x10quoteAssetVolume\x12(\n" +
+	"\x10number_of_trades\x18\t \x01(\x03R\x0enumberOfTrades\x12<\n" +
+	"\x1btaker_buy_base_asset_volume\x18\n" +
+	" \x01(\tR\x17takerBuyBaseAssetVolume\x12>\n" +
+	"\x1ctaker_buy_quote_asset_volume\x18\v \x01(\tR\x18takerBuyQuoteAssetVolume\"\x85\x01\n" +
+	"\vKlineUpdate\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x1a\n" +
+	"\binterval\x18\x02 \x01(\tR\binterval\x12*\n" +
+	"\x05kline\x18\x03 \x01(\v2\x14.marketdata.v1.KlineR\x05kline\x12\x16\n" +
+	"\x06closed\x18\x04 \x01(\bR\x06closed\"C\n" +
+	"\x13GetOrderBookRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x14\n" +
+	"\x05depth\x18\x02 \x01(\x05R\x05depth\"Y\n" +
+	"\x15GetOrderBookAtRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\x03R\x04time\x12\x14\n" +
+	"\x05depth\x18\x03 \x01(\x05R\x05depth\"Y\n" +
+	"\x10GetTradesRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x17\n" +
+	"\afrom_id\x18\x03 \x01(\x03R\x06fromId\"A\n" +
+	"\x11GetTradesResponse\x12,\n" +
+	"\x06trades\x18\x01 \x03(\v2\x14.marketdata.v1.TradeR\x06trades\"\x84\x01\n" +
+	"\x10GetKlinesRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x1a\n" +
+	"\binterval\x18\x02 \x01(\tR\binterval\x12\x14\n" +
+	"\x05start\x18\x03 \x01(\x03R\x05start\x12\x10\n" +
+	"\x03end\x18\x04 \x01(\x03R\x03end\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"U\n" +
+	"\x11GetKlinesResponse\x12,\n" +
+	"\x06klines\x18\x01 \x03(\v2\x14.marketdata.v1.KlineR\x06klines\x12\x12\n" +
+	"\x04next\x18\x02 \x01(\x03R\x04next\"3\n" +
+	"\x19SubscribeOrderBookRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\"0\n" +
+	"\x16SubscribeTradesRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\"0\n" +
+	"\x16SubscribeKlinesRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol2\xe7\x04\n" +
+	"\n" +
+	"MarketData\x12T\n" +
+	"\fGetOrderBook\x12\".marketdata.v1.GetOrderBookRequest\x1a .marketdata.v1.OrderBookSnapshot\x12X\n" +
+	"\x0eGetOrderBookAt\x12$.marketdata.v1.GetOrderBookAtRequest\x1a .marketdata.v1.OrderBookSnapshot\x12N\n" +
+	"\tGetTrades\x12\x1f.marketdata.v1.GetTradesRequest\x1a .marketdata.v1.GetTradesResponse\x12N\n" +
+	"\tGetKlines\x12\x1f.marketdata.v1.GetKlinesRequest\x1a .marketdata.v1.GetKlinesResponse\x12_\n" +
+	"\x12SubscribeOrderBook\x12(.marketdata.v1.SubscribeOrderBookRequest\x1a\x1d.marketdata.v1.OrderBookEvent0\x01\x12P\n" +
+	"\x0fSubscribeTrades\x12%.marketdata.v1.SubscribeTradesRequest\x1a\x14.marketdata.v1.Trade0\x01\x12V\n" +
+	"\x0fSubscribeKlines\x12%.marketdata.v1.SubscribeKlinesRequest\x1a\x1a.marketdata.v1.KlineUpdate0\x01B\x1cZ\x1atest.bhft.com/marketdatapbb\x06proto3"
+
+var (
+	file_marketdata_proto_rawDescOnce sync.Once
+	file_marketdata_proto_rawDescData []byte
+)
+
+func file_marketdata_proto_rawDescGZIP() []byte {
+	file_marketdata_proto_rawDescOnce.Do(func() {
+		file_marketdata_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_marketdata_proto_rawDesc), len(file_marketdata_proto_rawDesc)))
+	})
+	return file_marketdata_proto_rawDescData
+}
+
+var file_marketdata_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_marketdata_proto_goTypes = []any{
+	(*Level)(nil),                     // 0: marketdata.v1.Level
+	(*OrderBookSnapshot)(nil),         // 1: marketdata.v1.OrderBookSnapshot
+	(*OrderBookDelta)(nil),            // 2: marketdata.v1.OrderBookDelta
+	(*OrderBookEvent)(nil),            // 3: marketdata.v1.OrderBookEvent
+	(*Trade)(nil),                     // 4: marketdata.v1.Trade
+	(*Kline)(nil),                     // 5: marketdata.v1.Kline
+	(*KlineUpdate)(nil),               // 6: marketdata.v1.KlineUpdate
+	(*GetOrderBookRequest)(nil),       // 7: marketdata.v1.GetOrderBookRequest
+	(*GetOrderBookAtRequest)(nil),     // 8: marketdata.v1.GetOrderBookAtRequest
+	(*GetTradesRequest)(nil),          // 9: marketdata.v1.GetTradesRequest
+	(*GetTradesResponse)(nil),         // 10: marketdata.v1.GetTradesResponse
+	(*GetKlinesRequest)(nil),          // 11: marketdata.v1.GetKlinesRequest
+	(*GetKlinesResponse)(nil),         // 12: marketdata.v1.GetKlinesResponse
+	(*SubscribeOrderBookRequest)(nil), // 13: marketdata.v1.SubscribeOrderBookRequest
+	(*SubscribeTradesRequest)(nil),    // 14: marketdata.v1.SubscribeTradesRequest
+	(*SubscribeKlinesRequest)(nil),    // 15: marketdata.v1.SubscribeKlinesRequest
+}
+var file_marketdata_proto_depIdxs = []int32{
+	0,  // 0: marketdata.v1.OrderBookSnapshot.bids:type_name -> marketdata.v1.Level
+	0,  // 1: marketdata.v1.OrderBookSnapshot.asks:type_name -> marketdata.v1.Level
+	0,  // 2: marketdata.v1.OrderBookDelta.bids:type_name -> marketdata.v1.Level
+	0,  // 3: marketdata.v1.OrderBookDelta.asks:type_name -> marketdata.v1.Level
+	1,  // 4: marketdata.v1.OrderBookEvent.snapshot:type_name -> marketdata.v1.OrderBookSnapshot
+	2,  // 5: marketdata.v1.OrderBookEvent.delta:type_name -> marketdata.v1.OrderBookDelta
+	5,  // 6: marketdata.v1.KlineUpdate.kline:type_name -> marketdata.v1.Kline
+	4,  // 7: marketdata.v1.GetTradesResponse.trades:type_name -> marketdata.v1.Trade
+	5,  // 8: marketdata.v1.GetKlinesResponse.klines:type_name -> marketdata.v1.Kline
+	7,  // 9: marketdata.v1.MarketData.GetOrderBook:input_type -> marketdata.v1.GetOrderBookRequest
+	8,  // 10: marketdata.v1.MarketData.GetOrderBookAt:input_type -> marketdata.v1.GetOrderBookAtRequest
+	9,  // 11: marketdata.v1.MarketData.GetTrades:input_type -> marketdata.v1.GetTradesRequest
+	11, // 12: marketdata.v1.MarketData.GetKlines:input_type -> marketdata.v1.GetKlinesRequest
+	13, // 13: marketdata.v1.MarketData.SubscribeOrderBook:input_type -> marketdata.v1.SubscribeOrderBookRequest
+	14, // 14: marketdata.v1.MarketData.SubscribeTrades:input_type -> marketdata.v1.SubscribeTradesRequest
+	15, // 15: marketdata.v1.MarketData.SubscribeKlines:input_type -> marketdata.v1.SubscribeKlinesRequest
+	1,  // 16: marketdata.v1.MarketData.GetOrderBook:output_type -> marketdata.v1.OrderBookSnapshot
+	1,  // 17: marketdata.v1.MarketData.GetOrderBookAt:output_type -> marketdata.v1.OrderBookSnapshot
+	10, // 18: marketdata.v1.MarketData.GetTrades:output_type -> marketdata.v1.GetTradesResponse
+	12, // 19: marketdata.v1.MarketData.GetKlines:output_type -> marketdata.v1.GetKlinesResponse
+	3,  // 20: marketdata.v1.MarketData.SubscribeOrderBook:output_type -> marketdata.v1.OrderBookEvent
+	4,  // 21: marketdata.v1.MarketData.SubscribeTrades:output_type -> marketdata.v1.Trade
+	6,  // 22: marketdata.v1.MarketData.SubscribeKlines:output_type -> marketdata.v1.KlineUpdate
+	16, // [16:23] is the sub-list for method output_type
+	9,  // [9:16] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_marketdata_proto_init() }
+func file_marketdata_proto_init() {
+	if File_marketdata_proto != nil {
+		return
+	}
+	file_marketdata_proto_msgTypes[3].OneofWrappers = []any{
+		(*OrderBookEvent_Snapshot)(nil),
+		(*OrderBookEvent_Delta)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_marketdata_proto_rawDesc), len(file_marketdata_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_marketdata_proto_goTypes,
+		DependencyIndexes: file_marketdata_proto_depIdxs,
+		MessageInfos:      file_marketdata_proto_msgTypes,
+	}.Build()
+	File_marketdata_proto = out.File
+	file_marketdata_proto_goTypes = nil
+	file_marketdata_proto_depIdxs = nil
+}
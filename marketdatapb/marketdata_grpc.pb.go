@@ -0,0 +1,385 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: marketdata.proto
+
+package marketdatapb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MarketData_GetOrderBook_FullMethodName       = "/marketdata.v1.MarketData/GetOrderBook"
+	MarketData_GetOrderBookAt_FullMethodName     = "/marketdata.v1.MarketData/GetOrderBookAt"
+	MarketData_GetTrades_FullMethodName          = "/marketdata.v1.MarketData/GetTrades"
+	MarketData_GetKlines_FullMethodName          = "/marketdata.v1.MarketData/GetKlines"
+	MarketData_SubscribeOrderBook_FullMethodName = "/marketdata.v1.MarketData/SubscribeOrderBook"
+	MarketData_SubscribeTrades_FullMethodName    = "/marketdata.v1.MarketData/SubscribeTrades"
+	MarketData_SubscribeKlines_FullMethodName    = "/marketdata.v1.MarketData/SubscribeKlines"
+)
+
+// MarketDataClient is the client API for MarketData service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MarketData serves the live state kept by the collector and the history
+// stored in Postgres, for every venue collected. Prices and quantities are
+// decimal strings: live values as the venue sends them, stored ones
+// formatted back from their NUMERIC columns, so trailing zeros may differ.
+type MarketDataClient interface {
+	// GetOrderBook returns the current book of a symbol.
+	GetOrderBook(ctx context.Context, in *GetOrderBookRequest, opts ...grpc.CallOption) (*OrderBookSnapshot, error)
+	// GetOrderBookAt rebuilds the book at a past moment from the diff archive.
+	GetOrderBookAt(ctx context.Context, in *GetOrderBookAtRequest, opts ...grpc.CallOption) (*OrderBookSnapshot, error)
+	// GetTrades returns the most recent trades held in memory, or with from_id
+	// a page starting at that trade ID, read from Postgres when it is older
+	// than memory.
+	GetTrades(ctx context.Context, in *GetTradesRequest, opts ...grpc.CallOption) (*GetTradesResponse, error)
+	// GetKlines returns stored klines in a time range.
+	GetKlines(ctx context.Context, in *GetKlinesRequest, opts ...grpc.CallOption) (*GetKlinesResponse, error)
+	// SubscribeOrderBook starts with a full snapshot followed by deltas. A new
+	// snapshot is sent whenever the stream had to skip deltas.
+	SubscribeOrderBook(ctx context.Context, in *SubscribeOrderBookRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderBookEvent], error)
+	SubscribeTrades(ctx context.Context, in *SubscribeTradesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Trade], error)
+	SubscribeKlines(ctx context.Context, in *SubscribeKlinesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[KlineUpdate], error)
+}
+
+type marketDataClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMarketDataClient(cc grpc.ClientConnInterface) MarketDataClient {
+	return &marketDataClient{cc}
+}
+
+func (c *marketDataClient) GetOrderBook(ctx context.Context, in *GetOrderBookRequest, opts ...grpc.CallOption) (*OrderBookSnapshot, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderBookSnapshot)
+	err := c.cc.Invoke(ctx, MarketData_GetOrderBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataClient) GetOrderBookAt(ctx context.Context, in *GetOrderBookAtRequest, opts ...grpc.CallOption) (*OrderBookSnapshot, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderBookSnapshot)
+	err := c.cc.Invoke(ctx, MarketData_GetOrderBookAt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataClient) GetTrades(ctx context.Context, in *GetTradesRequest, opts ...grpc.CallOption) (*GetTradesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTradesResponse)
+	err := c.cc.Invoke(ctx, MarketData_GetTrades_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataClient) GetKlines(ctx context.Context, in *GetKlinesRequest, opts ...grpc.CallOption) (*GetKlinesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetKlinesResponse)
+	err := c.cc.Invoke(ctx, MarketData_GetKlines_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketDataClient) SubscribeOrderBook(ctx context.Context, in *SubscribeOrderBookRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderBookEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MarketData_ServiceDesc.Streams[0], MarketData_SubscribeOrderBook_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeOrderBookRequest, OrderBookEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MarketData_SubscribeOrderBookClient = grpc.ServerStreamingClient[OrderBookEvent]
+
+func (c *marketDataClient) SubscribeTrades(ctx context.Context, in *SubscribeTradesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Trade], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MarketData_ServiceDesc.Streams[1], MarketData_SubscribeTrades_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeTradesRequest, Trade]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MarketData_SubscribeTradesClient = grpc.ServerStreamingClient[Trade]
+
+func (c *marketDataClient) SubscribeKlines(ctx context.Context, in *SubscribeKlinesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[KlineUpdate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MarketData_ServiceDesc.Streams[2], MarketData_SubscribeKlines_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeKlinesRequest, KlineUpdate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MarketData_SubscribeKlinesClient = grpc.ServerStreamingClient[KlineUpdate]
+
+// MarketDataServer is the server API for MarketData service.
+// All implementations must embed UnimplementedMarketDataServer
+// for forward compatibility.
+//
+// MarketData serves the live state kept by the collector and the history
+// stored in Postgres, for every venue collected. Prices and quantities are
+// decimal strings: live values as the venue sends them, stored ones
+// formatted back from their NUMERIC columns, so trailing zeros may differ.
+type MarketDataServer interface {
+	// GetOrderBook returns the current book of a symbol.
+	GetOrderBook(context.Context, *GetOrderBookRequest) (*OrderBookSnapshot, error)
+	// GetOrderBookAt rebuilds the book at a past moment from the diff archive.
+	GetOrderBookAt(context.Context, *GetOrderBookAtRequest) (*OrderBookSnapshot, error)
+	// GetTrades returns the most recent trades held in memory, or with from_id
+	// a page starting at that trade ID, read from Postgres when it is older
+	// than memory.
+	GetTrades(context.Context, *GetTradesRequest) (*GetTradesResponse, error)
+	// GetKlines returns stored klines in a time range.
+	GetKlines(context.Context, *GetKlinesRequest) (*GetKlinesResponse, error)
+	// SubscribeOrderBook starts with a full snapshot followed by deltas. A new
+	// snapshot is sent whenever the stream had to skip deltas.
+	SubscribeOrderBook(*SubscribeOrderBookRequest, grpc.ServerStreamingServer[OrderBookEvent]) error
+	SubscribeTrades(*SubscribeTradesRequest, grpc.ServerStreamingServer[Trade]) error
+	SubscribeKlines(*SubscribeKlinesRequest, grpc.ServerStreamingServer[KlineUpdate]) error
+	mustEmbedUnimplementedMarketDataServer()
+}
+
+// UnimplementedMarketDataServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMarketDataServer struct{}
+
+func (UnimplementedMarketDataServer) GetOrderBook(context.Context, *GetOrderBookRequest) (*OrderBookSnapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderBook not implemented")
+}
+func (UnimplementedMarketDataServer) GetOrderBookAt(context.Context, *GetOrderBookAtRequest) (*OrderBookSnapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderBookAt not implemented")
+}
+func (UnimplementedMarketDataServer) GetTrades(context.Context, *GetTradesRequest) (*GetTradesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTrades not implemented")
+}
+func (UnimplementedMarketDataServer) GetKlines(context.Context, *GetKlinesRequest) (*GetKlinesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKlines not implemented")
+}
+func (UnimplementedMarketDataServer) SubscribeOrderBook(*SubscribeOrderBookRequest, grpc.ServerStreamingServer[OrderBookEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeOrderBook not implemented")
+}
+func (UnimplementedMarketDataServer) SubscribeTrades(*SubscribeTradesRequest, grpc.ServerStreamingServer[Trade]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeTrades not implemented")
+}
+func (UnimplementedMarketDataServer) SubscribeKlines(*SubscribeKlinesRequest, grpc.ServerStreamingServer[KlineUpdate]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeKlines not implemented")
+}
+func (UnimplementedMarketDataServer) mustEmbedUnimplementedMarketDataServer() {}
+func (UnimplementedMarketDataServer) testEmbeddedByValue()                    {}
+
+// UnsafeMarketDataServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MarketDataServer will
+// result in compilation errors.
+type UnsafeMarketDataServer interface {
+	mustEmbedUnimplementedMarketDataServer()
+}
+
+func RegisterMarketDataServer(s grpc.ServiceRegistrar, srv MarketDataServer) {
+	// If the following call pancis, it indicates UnimplementedMarketDataServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MarketData_ServiceDesc, srv)
+}
+
+func _MarketData_GetOrderBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServer).GetOrderBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketData_GetOrderBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServer).GetOrderBook(ctx, req.(*GetOrderBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketData_GetOrderBookAt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderBookAtRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServer).GetOrderBookAt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketData_GetOrderBookAt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServer).GetOrderBookAt(ctx, req.(*GetOrderBookAtRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketData_GetTrades_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTradesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServer).GetTrades(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketData_GetTrades_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServer).GetTrades(ctx, req.(*GetTradesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketData_GetKlines_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetKlinesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketDataServer).GetKlines(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MarketData_GetKlines_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketDataServer).GetKlines(ctx, req.(*GetKlinesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MarketData_SubscribeOrderBook_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeOrderBookRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketDataServer).SubscribeOrderBook(m, &grpc.GenericServerStream[SubscribeOrderBookRequest, OrderBookEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MarketData_SubscribeOrderBookServer = grpc.ServerStreamingServer[OrderBookEvent]
+
+func _MarketData_SubscribeTrades_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeTradesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketDataServer).SubscribeTrades(m, &grpc.GenericServerStream[SubscribeTradesRequest, Trade]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MarketData_SubscribeTradesServer = grpc.ServerStreamingServer[Trade]
+
+func _MarketData_SubscribeKlines_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeKlinesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketDataServer).SubscribeKlines(m, &grpc.GenericServerStream[SubscribeKlinesRequest, KlineUpdate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MarketData_SubscribeKlinesServer = grpc.ServerStreamingServer[KlineUpdate]
+
+// MarketData_ServiceDesc is the grpc.ServiceDesc for MarketData service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MarketData_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "marketdata.v1.MarketData",
+	HandlerType: (*MarketDataServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetOrderBook",
+			Handler:    _MarketData_GetOrderBook_Handler,
+		},
+		{
+			MethodName: "GetOrderBookAt",
+			Handler:    _MarketData_GetOrderBookAt_Handler,
+		},
+		{
+			MethodName: "GetTrades",
+			Handler:    _MarketData_GetTrades_Handler,
+		},
+		{
+			MethodName: "GetKlines",
+			Handler:    _MarketData_GetKlines_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeOrderBook",
+			Handler:       _MarketData_SubscribeOrderBook_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeTrades",
+			Handler:       _MarketData_SubscribeTrades_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeKlines",
+			Handler:       _MarketData_SubscribeKlines_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "marketdata.proto",
+}
@@ -0,0 +1,112 @@
+// Package markets is the registry of the live state kept for each symbol.
+package markets
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"test.bhft.com/indicators"
+	"test.bhft.com/klines"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/paper"
+	"test.bhft.com/quotes"
+	"test.bhft.com/tape"
+	"test.bhft.com/ticker"
+	"test.bhft.com/trades"
+)
+
+// Market groups the live state the pipelines maintain for one symbol.
+type Market struct {
+	Symbol string
+	// Venue is the name of the venue the market is collected from, like
+	// binance or okx.
+	Venue string
+	// Feeds lists the feeds collected for the market, named like in
+	// -feeds.
+	Feeds  []string
+	Book   *orderbook.Book
+	Trades *trades.List
+	Klines *klines.List
+	Quotes *quotes.Latest
+	// Tickers holds the venue ticker stats and the ones computed from
+	// Trades.
+	Tickers *ticker.Board
+	// Tape runs the trade tape analytics over Trades.
+	Tape *tape.Analyzer
+	// Indicators runs the technical indicators over Klines.
+	Indicators *indicators.Set
+	// Paper simulates orders against Book and Trades.
+	Paper *paper.Simulator
+}
+
+// BestQuote returns the best bid and ask of the market: the last top of book
+// quote when the quote feed runs, otherwise the top of the order book while
+// it is in sync. ok is false when neither is available.
+func (m *Market) BestQuote() (quotes.Quote, bool) {
+	if m.Quotes != nil {
+		if q, ok := m.Quotes.Get(); ok {
+			return q, true
+		}
+	}
+	if m.Book == nil || !m.Book.Synced() {
+		return quotes.Quote{}, false
+	}
+	s := m.Book.Snapshot(1)
+	if len(s.Bids) == 0 || len(s.Asks) == 0 {
+		return quotes.Quote{}, false
+	}
+	return quotes.Quote{
+		Symbol:   m.Symbol,
+		UpdateID: s.LastUpdateId,
+		Time:     s.Time,
+		BidPrice: s.Bids[0].Price,
+		BidQty:   s.Bids[0].Quantity,
+		AskPrice: s.Asks[0].Price,
+		AskQty:   s.Asks[0].Quantity,
+	}, true
+}
+
+// Registry holds the live markets, keyed by upper case symbol.
+type Registry struct {
+	sync.RWMutex
+	m map[string]*Market
+}
+
+func NewRegistry() *Registry {
+	return &Registry{m: make(map[string]*Market)}
+}
+
+func (ms *Registry) Add(m *Market) {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.m[strings.ToUpper(m.Symbol)] = m
+}
+
+// Get returns the market of symbol in any case, nil when it is unknown.
+func (ms *Registry) Get(symbol string) *Market {
+	ms.RLock()
+	defer ms.RUnlock()
+	return ms.m[strings.ToUpper(symbol)]
+}
+
+// BestQuote returns the best quote of symbol, see Market.BestQuote.
+func (ms *Registry) BestQuote(symbol string) (quotes.Quote, bool) {
+	m := ms.Get(symbol)
+	if m == nil {
+		return quotes.Quote{}, false
+	}
+	return m.BestQuote()
+}
+
+// Symbols lists the registered symbols, sorted.
+func (ms *Registry) Symbols() []string {
+	ms.RLock()
+	defer ms.RUnlock()
+	symbols := make([]string, 0, len(ms.m))
+	for s := range ms.m {
+		symbols = append(symbols, s)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
@@ -0,0 +1,263 @@
+// Package okx is the OKX v5 spot adapter: a client for the public REST API
+// and websocket channels that decodes the exchange messages into the
+// orderbook, trades and klines types. The order book comes as a snapshot on
+// the books channel and is verified against the checksum of every message.
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"test.bhft.com/capture"
+	"test.bhft.com/exchange"
+	"test.bhft.com/telemetry"
+)
+
+const (
+	DefaultBaseURL     = "https://www.okx.com"
+	DefaultPublicURL   = "wss://ws.okx.com:8443/ws/v5/public"
+	DefaultBusinessURL = "wss://ws.okx.com:8443/ws/v5/business"
+)
+
+var (
+	serverTimePath = "/api/v5/public/time"
+	// pingInterval keeps the connections alive, OKX drops them after 30s
+	// without a message.
+	pingInterval = 20 * time.Second
+)
+
+// Client talks to OKX over HTTP and websockets. Most channels are on
+// PublicURL, candles on BusinessURL. With Replayer set the channels are read
+// from a capture and with Recorder set every frame read is recorded; REST
+// requests go through HTTP, whose transport is expected to do the same.
+type Client struct {
+	HTTP        *http.Client
+	BaseURL     string
+	PublicURL   string
+	BusinessURL string
+	Recorder    *capture.Recorder
+	Replayer    *capture.Replayer
+
+	mu    sync.Mutex
+	books map[string]*exchange.RedialConn
+}
+
+var (
+	_ exchange.Venue            = (*Client)(nil)
+	_ exchange.BookVerifier     = (*Client)(nil)
+	_ exchange.BookResubscriber = (*Client)(nil)
+	_ exchange.QuoteFeed        = (*Client)(nil)
+)
+
+func NewClient(httpClient *http.Client) *Client {
+	return &Client{
+		HTTP:        httpClient,
+		BaseURL:     DefaultBaseURL,
+		PublicURL:   DefaultPublicURL,
+		BusinessURL: DefaultBusinessURL,
+	}
+}
+
+// response is the envelope of every REST response, a code other than "0"
+// is an error.
+type response struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+func (c *Client) get(path string, params url.Values, v any) error {
+	u, err := url.ParseRequestURI(c.BaseURL)
+	if err != nil {
+		return err
+	}
+	u.Path = path
+	u.RawQuery = params.Encode()
+	slog.Debug("okx request", "url", u.String())
+	resp, err := c.HTTP.Get(u.String())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status: %s", path, resp.Status)
+	}
+	var body response
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+	if body.Code != "0" {
+		return fmt.Errorf("%s: code %s: %s", path, body.Code, body.Msg)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(body.Data, v)
+}
+
+// Ping checks the connectivity to the REST API.
+func (c *Client) Ping() error {
+	_, err := c.ServerTime()
+	return err
+}
+
+// ServerTime returns the exchange time in milliseconds.
+func (c *Client) ServerTime() (int64, error) {
+	var body []struct {
+		Ts string `json:"ts"`
+	}
+	if err := c.get(serverTimePath, nil, &body); err != nil {
+		return 0, err
+	}
+	if len(body) == 0 {
+		return 0, fmt.Errorf("%s: empty response", serverTimePath)
+	}
+	return strconv.ParseInt(body[0].Ts, 10, 64)
+}
+
+// Name is the venue name used in symbols like okx:BTC-USDT.
+func (c *Client) Name() string {
+	return "okx"
+}
+
+type arg struct {
+	Channel string `json:"channel"`
+	InstID  string `json:"instId"`
+}
+
+// event is the part every channel message shares. Event is set on the
+// answers to subscriptions and on errors, Action on the books channel.
+type event struct {
+	Event  string          `json:"event"`
+	Code   string          `json:"code"`
+	Msg    string          `json:"msg"`
+	Arg    arg             `json:"arg"`
+	Action string          `json:"action"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Subscribe connects to rawurl and subscribes to channel for instID. The
+// connection state is reported to telemetry.Health under stream. Frames are
+// recorded and replayed under rawurl/channel/instID since every channel of
+// an endpoint shares its URL. Live connections reconnect and subscribe again
+// when they drop.
+func (c *Client) Subscribe(rawurl, channel, instID, stream string) (capture.Conn, error) {
+	key := rawurl + "/" + channel + "/" + instID
+	if c.Replayer != nil {
+		conn, err := c.Replayer.Open(capture.StreamKey(key))
+		if err != nil {
+			return nil, err
+		}
+		return exchange.TrackConn(conn, c.Name(), stream, instID), nil
+	}
+	return exchange.Redial(func() (capture.Conn, error) {
+		return c.subscribe(rawurl, key, channel, instID, stream)
+	}, stream, instID)
+}
+
+// subscribe opens one live subscription of channel for instID.
+func (c *Client) subscribe(rawurl, key, channel, instID, stream string) (capture.Conn, error) {
+	telemetry.ObserveDial(stream, instID)
+	conn, _, err := websocket.DefaultDialer.Dial(rawurl, nil)
+	if err != nil {
+		return nil, err
+	}
+	sub := struct {
+		Op   string `json:"op"`
+		Args []arg  `json:"args"`
+	}{Op: "subscribe", Args: []arg{{Channel: channel, InstID: instID}}}
+	if err := conn.WriteJSON(sub); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("subscribe %s %s: %w", channel, instID, err)
+	}
+	go keepAlive(conn)
+
+	var rc capture.Conn = conn
+	if c.Recorder != nil {
+		rc = c.Recorder.Conn(conn, key)
+	}
+	return exchange.TrackConn(rc, c.Name(), stream, instID), nil
+}
+
+// keepAlive sends a ping every pingInterval until writing fails, which it
+// does once the connection is closed. The readers skip the pong answers.
+func keepAlive(conn *websocket.Conn) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+			return
+		}
+	}
+}
+
+// decode parses a channel message. A pong is returned as an event named
+// pong, so readers only handle messages without Event.
+func decode(message []byte) (event, error) {
+	var e event
+	if string(message) == "pong" {
+		e.Event = "pong"
+		return e, nil
+	}
+	err := json.Unmarshal(message, &e)
+	return e, err
+}
+
+func parseInt(s string) int64 {
+	v, _ := strconv.ParseInt(s, 10, 64)
+	return v
+}
+
+// read decodes the data of every message of conn into []T and hands it to
+// handle until ctx is done, the connection fails, the venue sends an error
+// or handle fails, then calls done.
+func read[T any](ctx context.Context, wg *sync.WaitGroup, conn capture.Conn, logger *slog.Logger, venue, stream, instID string, handle func(event, []T) error, done func()) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer done()
+		defer exchange.CloseOnDone(ctx, conn)()
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				exchange.LogReadEnd(ctx, logger, err)
+				return
+			}
+			telemetry.ObserveMessage(venue, stream, instID)
+			telemetry.LogRawEvent(logger, stream, instID, message)
+			e, err := decode(message)
+			if err != nil {
+				telemetry.DecodeErrors.WithLabelValues(stream, instID).Inc()
+				logger.Error("decode message", "err", err)
+				return
+			}
+			switch e.Event {
+			case "":
+			case "error":
+				logger.Error("channel error", "code", e.Code, "msg", e.Msg)
+				return
+			default:
+				continue
+			}
+			var data []T
+			if err := json.Unmarshal(e.Data, &data); err != nil {
+				telemetry.DecodeErrors.WithLabelValues(stream, instID).Inc()
+				logger.Error("decode message data", "err", err)
+				return
+			}
+			if err := handle(e, data); err != nil {
+				logger.Error("stream ended", "err", err)
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,172 @@
+package okx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"test.bhft.com/exchange"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/telemetry"
+)
+
+var (
+	booksChannel = "books"
+	// checksumDepth is the number of levels per side the checksum covers.
+	checksumDepth = 25
+)
+
+// booksData is a message of the books channel. Levels are price, size, a
+// deprecated field and the number of orders.
+type booksData struct {
+	Asks      [][]string `json:"asks"`
+	Bids      [][]string `json:"bids"`
+	Ts        string     `json:"ts"`
+	Checksum  int32      `json:"checksum"`
+	PrevSeqID int64      `json:"prevSeqId"`
+	SeqID     int64      `json:"seqId"`
+}
+
+func (d booksData) snapshot(instID string) *orderbook.Snapshot {
+	return &orderbook.Snapshot{
+		Symbol:       instID,
+		Time:         parseInt(d.Ts),
+		LastUpdateId: d.SeqID,
+		Bids:         levels(d.Bids),
+		Asks:         levels(d.Asks),
+	}
+}
+
+func (d booksData) update(instID string) orderbook.Update {
+	return orderbook.Update{
+		EventTime:     parseInt(d.Ts),
+		Symbol:        instID,
+		FirstUpdateID: d.PrevSeqID + 1,
+		FinalUpdateID: d.SeqID,
+		Bids:          pairs(d.Bids),
+		Asks:          pairs(d.Asks),
+	}
+}
+
+func levels(l [][]string) []orderbook.Level {
+	res := make([]orderbook.Level, 0, len(l))
+	for _, v := range l {
+		if len(v) < 2 {
+			continue
+		}
+		res = append(res, orderbook.Level{Price: v[0], Quantity: v[1]})
+	}
+	return res
+}
+
+// pairs keeps the price and size of each level, the form of diffs.
+func pairs(l [][]string) [][]string {
+	res := make([][]string, 0, len(l))
+	for _, v := range l {
+		if len(v) < 2 {
+			continue
+		}
+		res = append(res, v[:2])
+	}
+	return res
+}
+
+// OrderBook returns an empty book: the books channel starts with a full
+// snapshot, so there is nothing to load over REST.
+func (c *Client) OrderBook(symbol string, limit int) (*orderbook.Book, error) {
+	return orderbook.New(symbol), nil
+}
+
+// BookStream reads the books channel of symbol until ctx is done or the
+// connection fails, then closes the returned channel. The first event is a
+// snapshot; the diffs chain on seqId and every event carries the checksum
+// VerifyBook expects. On a sequence reset the channel is subscribed again
+// and starts over with a snapshot; a replayed stream ends instead.
+func (c *Client) BookStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan exchange.BookEvent, error) {
+	ch := make(chan exchange.BookEvent, 10)
+	conn, err := c.Subscribe(c.PublicURL, booksChannel, symbol, telemetry.StreamBook)
+	if err != nil {
+		return nil, err
+	}
+	rc, _ := conn.(*exchange.RedialConn)
+	if rc != nil {
+		c.mu.Lock()
+		if c.books == nil {
+			c.books = make(map[string]*exchange.RedialConn)
+		}
+		c.books[symbol] = rc
+		c.mu.Unlock()
+	}
+
+	logger := slog.With("stream", telemetry.StreamBook, "symbol", symbol)
+	read(ctx, wg, conn, logger, c.Name(), telemetry.StreamBook, symbol, func(e event, data []booksData) error {
+		for _, d := range data {
+			var be exchange.BookEvent
+			switch {
+			case e.Action == "snapshot":
+				be = exchange.BookEvent{Snapshot: d.snapshot(symbol), Checksum: d.Checksum}
+			case d.SeqID < d.PrevSeqID:
+				if rc == nil {
+					return fmt.Errorf("sequence reset: seqId %d, prevSeqId %d", d.SeqID, d.PrevSeqID)
+				}
+				logger.Warn("sequence reset, subscribing again", "seqId", d.SeqID, "prevSeqId", d.PrevSeqID)
+				rc.Reset()
+				return nil
+			case d.SeqID == d.PrevSeqID:
+				// No change, sent as a heartbeat.
+				continue
+			default:
+				be = exchange.BookEvent{Diff: d.update(symbol), Checksum: d.Checksum}
+			}
+			telemetry.ObserveEvent(telemetry.StreamBook, symbol, parseInt(d.Ts))
+			ch <- be
+			telemetry.QueueDepth.WithLabelValues(telemetry.StreamBook, symbol).Set(float64(len(ch)))
+		}
+		return nil
+	}, func() {
+		if rc != nil {
+			c.mu.Lock()
+			delete(c.books, symbol)
+			c.mu.Unlock()
+		}
+		close(ch)
+	})
+	return ch, nil
+}
+
+// ResubscribeBook subscribes the books channel of symbol again, the stream
+// then starts over with a snapshot.
+func (c *Client) ResubscribeBook(symbol string) error {
+	c.mu.Lock()
+	rc := c.books[symbol]
+	c.mu.Unlock()
+	if rc == nil {
+		return errors.New("no live book stream to subscribe again")
+	}
+	rc.Reset()
+	return nil
+}
+
+// VerifyBook compares the CRC32 of the best 25 levels of each side, written
+// as bid price:size:ask price:size alternating, with checksum.
+func (c *Client) VerifyBook(orderBook *orderbook.Book, checksum int32) error {
+	snapshot := orderBook.Snapshot(checksumDepth)
+	fields := make([]string, 0, 4*checksumDepth)
+	for i := 0; i < checksumDepth; i++ {
+		if i < len(snapshot.Bids) {
+			fields = append(fields, snapshot.Bids[i].Price, snapshot.Bids[i].Quantity)
+		}
+		if i < len(snapshot.Asks) {
+			fields = append(fields, snapshot.Asks[i].Price, snapshot.Asks[i].Quantity)
+		}
+	}
+	got := int32(crc32.ChecksumIEEE([]byte(strings.Join(fields, ":"))))
+	if got != checksum {
+		return fmt.Errorf("checksum %d, want %d", got, checksum)
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package okx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"test.bhft.com/exchange"
+	"test.bhft.com/orderbook"
+)
+
+// fakeServer is an OKX public endpoint. The nth connection gets the frames
+// of the nth session, the last session is repeated.
+type fakeServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	conns int
+}
+
+func newFakeServer(t *testing.T, sessions ...[]string) *fakeServer {
+	s := &fakeServer{}
+	upgrader := websocket.Upgrader{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var sub struct {
+			Op   string `json:"op"`
+			Args []arg  `json:"args"`
+		}
+		if err := conn.ReadJSON(&sub); err != nil {
+			return
+		}
+		if sub.Op != "subscribe" || len(sub.Args) != 1 || sub.Args[0].Channel != booksChannel {
+			t.Errorf("subscribe message %+v", sub)
+			return
+		}
+		s.mu.Lock()
+		frames := sessions[min(s.conns, len(sessions)-1)]
+		s.conns++
+		s.mu.Unlock()
+		for _, f := range frames {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(f)); err != nil {
+				return
+			}
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeServer) client() *Client {
+	c := NewClient(s.Server.Client())
+	c.PublicURL = "ws" + strings.TrimPrefix(s.URL, "http")
+	return c
+}
+
+// checksum is the OKX checksum of the levels written out as OKX documents
+// them.
+func checksum(s string) int32 {
+	return int32(crc32.ChecksumIEEE([]byte(s)))
+}
+
+func booksFrame(action string, prevSeqID, seqID int64, bids, asks [][]string, sum int32) string {
+	data, _ := json.Marshal([]booksData{{Asks: asks, Bids: bids, Ts: "1700000000000", Checksum: sum, PrevSeqID: prevSeqID, SeqID: seqID}})
+	return fmt.Sprintf(`{"arg":{"channel":"books","instId":"BTC-USDT"},"action":%q,"data":%s}`, action, data)
+}
+
+func next(t *testing.T, ch chan exchange.BookEvent) exchange.BookEvent {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			t.Fatal("book stream ended")
+		}
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("no book event")
+	}
+	return exchange.BookEvent{}
+}
+
+func TestVerifyBook(t *testing.T) {
+	tests := []struct {
+		name       string
+		bids, asks []orderbook.Level
+		fields     string
+	}{
+		{
+			name:   "alternating",
+			bids:   []orderbook.Level{{Price: "3366.1", Quantity: "7"}, {Price: "3366", Quantity: "6"}},
+			asks:   []orderbook.Level{{Price: "3366.8", Quantity: "9"}, {Price: "3368", Quantity: "8"}},
+			fields: "3366.1:7:3366.8:9:3366:6:3368:8",
+		},
+		{
+			name:   "more bids than asks",
+			bids:   []orderbook.Level{{Price: "10", Quantity: "1"}, {Price: "9", Quantity: "2"}, {Price: "8", Quantity: "3"}},
+			asks:   []orderbook.Level{{Price: "11", Quantity: "4"}},
+			fields: "10:1:11:4:9:2:8:3",
+		},
+	}
+	c := NewClient(http.DefaultClient)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := orderbook.FromSnapshot(orderbook.Snapshot{Symbol: "BTC-USDT", Bids: tt.bids, Asks: tt.asks})
+			if err := c.VerifyBook(book, checksum(tt.fields)); err != nil {
+				t.Error(err)
+			}
+			if err := c.VerifyBook(book, checksum(tt.fields)+1); err == nil {
+				t.Error("wrong checksum accepted")
+			}
+		})
+	}
+}
+
+func TestVerifyBookDepth(t *testing.T) {
+	var bids, asks []orderbook.Level
+	var fields []string
+	for i := 0; i < 30; i++ {
+		bid := orderbook.Level{Price: fmt.Sprint(1000 - i), Quantity: "1"}
+		ask := orderbook.Level{Price: fmt.Sprint(1001 + i), Quantity: "2"}
+		bids, asks = append(bids, bid), append(asks, ask)
+		if i < checksumDepth {
+			fields = append(fields, bid.Price, bid.Quantity, ask.Price, ask.Quantity)
+		}
+	}
+	book := orderbook.FromSnapshot(orderbook.Snapshot{Symbol: "BTC-USDT", Bids: bids, Asks: asks})
+	if err := NewClient(http.DefaultClient).VerifyBook(book, checksum(strings.Join(fields, ":"))); err != nil {
+		t.Errorf("checksum over %d levels: %v", checksumDepth, err)
+	}
+}
+
+// TestBookStream follows a snapshot with diffs chained on seqId and checks
+// the book against the checksum after every event, the way the collector
+// does.
+func TestBookStream(t *testing.T) {
+	s := newFakeServer(t, []string{
+		booksFrame("snapshot", -1, 10, [][]string{{"100", "2", "0", "1"}}, [][]string{{"101", "1", "0", "1"}}, checksum("100:2:101:1")),
+		booksFrame("update", 10, 12, [][]string{{"100", "3", "0", "1"}, {"99"}}, nil, checksum("100:3:101:1")),
+		// A heartbeat repeats the seqId and is skipped.
+		booksFrame("update", 12, 12, nil, nil, checksum("100:3:101:1")),
+		booksFrame("update", 12, 15, nil, [][]string{{"101", "0", "0", "0"}, {"102", "1", "0", "1"}}, checksum("100:3:102:1")),
+	})
+	c := s.client()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	ch, err := c.BookStream(ctx, &wg, "BTC-USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	book := orderbook.New("BTC-USDT")
+	e := next(t, ch)
+	if e.Snapshot == nil || e.Snapshot.LastUpdateId != 10 {
+		t.Fatalf("first event %+v, want the snapshot at 10", e)
+	}
+	book.Reset(*e.Snapshot)
+	if err := c.VerifyBook(book, e.Checksum); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+
+	wantDiffs := []struct{ first, final int64 }{{11, 12}, {13, 15}}
+	for _, want := range wantDiffs {
+		e := next(t, ch)
+		if e.Snapshot != nil || e.Diff.FirstUpdateID != want.first || e.Diff.FinalUpdateID != want.final {
+			t.Fatalf("got %+v, want a diff from %d to %d", e, want.first, want.final)
+		}
+		for _, l := range append(e.Diff.Bids, e.Diff.Asks...) {
+			if len(l) != 2 {
+				t.Fatalf("diff level %v, want price and size", l)
+			}
+		}
+		if !book.Update(&e.Diff) {
+			t.Fatalf("diff %d-%d does not follow the book at %d", want.first, want.final, book.LastID())
+		}
+		if err := c.VerifyBook(book, e.Checksum); err != nil {
+			t.Fatalf("diff %d-%d: %v", want.first, want.final, err)
+		}
+	}
+	if !book.Synced() || book.LastID() != 15 {
+		t.Errorf("book synced %v at %d, want synced at 15", book.Synced(), book.LastID())
+	}
+}
+
+// TestBookStreamSequenceReset checks a seqId lower than prevSeqId makes the
+// stream subscribe again and start over from a snapshot.
+func TestBookStreamSequenceReset(t *testing.T) {
+	s := newFakeServer(t,
+		[]string{
+			booksFrame("snapshot", -1, 10, [][]string{{"100", "2", "0", "1"}}, [][]string{{"101", "1", "0", "1"}}, checksum("100:2:101:1")),
+			booksFrame("update", 10, 3, [][]string{{"100", "1", "0", "1"}}, nil, checksum("100:1:101:1")),
+		},
+		[]string{
+			booksFrame("snapshot", -1, 3, [][]string{{"100", "1", "0", "1"}}, [][]string{{"101", "1", "0", "1"}}, checksum("100:1:101:1")),
+		},
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	ch, err := s.client().BookStream(ctx, &wg, "BTC-USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, ch); e.Snapshot == nil || e.Snapshot.LastUpdateId != 10 {
+		t.Fatalf("first event %+v, want the snapshot at 10", e)
+	}
+	if e := next(t, ch); e.Snapshot == nil || e.Snapshot.LastUpdateId != 3 {
+		t.Fatalf("event after the reset %+v, want the snapshot at 3", e)
+	}
+}
+
+// TestResubscribeBook checks ResubscribeBook restarts the books channel
+// with a fresh snapshot.
+func TestResubscribeBook(t *testing.T) {
+	s := newFakeServer(t,
+		[]string{booksFrame("snapshot", -1, 10, [][]string{{"100", "2", "0", "1"}}, [][]string{{"101", "1", "0", "1"}}, 0)},
+		[]string{booksFrame("snapshot", -1, 20, [][]string{{"100", "5", "0", "1"}}, [][]string{{"101", "1", "0", "1"}}, 0)},
+	)
+	c := s.client()
+	if err := c.ResubscribeBook("BTC-USDT"); err == nil {
+		t.Error("resubscribed a book that is not streamed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	ch, err := c.BookStream(ctx, &wg, "BTC-USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, ch); e.Snapshot == nil || e.Snapshot.LastUpdateId != 10 {
+		t.Fatalf("first event %+v, want the snapshot at 10", e)
+	}
+	if err := c.ResubscribeBook("BTC-USDT"); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(t, ch); e.Snapshot == nil || e.Snapshot.LastUpdateId != 20 {
+		t.Fatalf("event after resubscribing %+v, want the snapshot at 20", e)
+	}
+}
@@ -0,0 +1,151 @@
+package okx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"test.bhft.com/klines"
+	"test.bhft.com/telemetry"
+)
+
+var (
+	candlesPath   = "/api/v5/market/candles"
+	candleChannel = "candle%s"
+	// maxCandles is the most candles the REST endpoint returns.
+	maxCandles = 300
+)
+
+// bars maps the Binance intervals to OKX bars. Bars of 6 hours and longer
+// are taken in UTC like on Binance, OKX defaults to Hong Kong time.
+var bars = map[string]string{
+	"1m":  "1m",
+	"3m":  "3m",
+	"5m":  "5m",
+	"15m": "15m",
+	"30m": "30m",
+	"1h":  "1H",
+	"2h":  "2H",
+	"4h":  "4H",
+	"6h":  "6Hutc",
+	"12h": "12Hutc",
+	"1d":  "1Dutc",
+	"3d":  "3Dutc",
+	"1w":  "1Wutc",
+	"1M":  "1Mutc",
+}
+
+func bar(interval string) (string, error) {
+	b, ok := bars[interval]
+	if !ok {
+		return "", fmt.Errorf("okx: unsupported interval %q", interval)
+	}
+	return b, nil
+}
+
+// closeTime returns the last millisecond of the candle of interval opened at
+// openTime, the way Binance sets it.
+func closeTime(openTime int64, interval string) int64 {
+	open := time.UnixMilli(openTime).UTC()
+	var next time.Time
+	switch unit, n := interval[len(interval)-1], interval[:len(interval)-1]; unit {
+	case 'M':
+		months, _ := strconv.Atoi(n)
+		next = open.AddDate(0, months, 0)
+	case 'w':
+		weeks, _ := strconv.Atoi(n)
+		next = open.AddDate(0, 0, 7*weeks)
+	case 'd':
+		days, _ := strconv.Atoi(n)
+		next = open.AddDate(0, 0, days)
+	default:
+		d, _ := time.ParseDuration(interval)
+		next = open.Add(d)
+	}
+	return next.UnixMilli() - 1
+}
+
+// candle converts an OKX candle: open time, open, high, low, close, volume
+// in base currency, volume in quote currency for derivatives, volume in
+// quote currency and whether it is closed. OKX does not send the number of
+// trades or the taker volumes.
+func candle(c []string, interval string) (klines.Kline, bool, error) {
+	if len(c) < 9 {
+		return klines.Kline{}, false, fmt.Errorf("okx: candle with %d fields", len(c))
+	}
+	openTime := parseInt(c[0])
+	return klines.Kline{
+		OpenTime:         openTime,
+		Open:             c[1],
+		High:             c[2],
+		Low:              c[3],
+		Close:            c[4],
+		Volume:           c[5],
+		CloseTime:        closeTime(openTime, interval),
+		QuoteAssetVolume: c[7],
+	}, c[8] == "1", nil
+}
+
+// Klines loads the latest limit candles of symbol and interval, at most 300.
+func (c *Client) Klines(symbol, interval string, limit int) (*klines.List, error) {
+	b, err := bar(interval)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Add("instId", symbol)
+	params.Add("bar", b)
+	params.Add("limit", strconv.Itoa(min(limit, maxCandles)))
+	var body [][]string
+	if err := c.get(candlesPath, params, &body); err != nil {
+		return nil, err
+	}
+
+	list := make([]klines.Kline, 0, len(body))
+	for _, v := range body {
+		k, _, err := candle(v, interval)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, k)
+	}
+	// OKX returns the newest candle first.
+	slices.Reverse(list)
+	klineList := klines.New(symbol, interval)
+	klineList.List = list
+	return klineList, nil
+}
+
+// KlineStream reads the candle channel of symbol and interval until ctx is
+// done or the connection fails, then closes the returned channel. Candle
+// messages carry no event time, so no latency is observed for them.
+func (c *Client) KlineStream(ctx context.Context, wg *sync.WaitGroup, symbol, interval string) (chan klines.Update, error) {
+	b, err := bar(interval)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan klines.Update, 100)
+	conn, err := c.Subscribe(c.BusinessURL, fmt.Sprintf(candleChannel, b), symbol, telemetry.StreamKlines)
+	if err != nil {
+		return nil, fmt.Errorf("klines: subscribe: %w", err)
+	}
+
+	logger := slog.With("stream", telemetry.StreamKlines, "symbol", symbol, "interval", interval)
+	read(ctx, wg, conn, logger, c.Name(), telemetry.StreamKlines, symbol, func(_ event, data [][]string) error {
+		for _, v := range data {
+			k, closed, err := candle(v, interval)
+			if err != nil {
+				return err
+			}
+			ch <- klines.Update{Symbol: symbol, Interval: interval, Kline: k, Closed: closed}
+			telemetry.QueueDepth.WithLabelValues(telemetry.StreamKlines, symbol).Set(float64(len(ch)))
+		}
+		return nil
+	}, func() { close(ch) })
+	return ch, nil
+}
@@ -0,0 +1,54 @@
+package okx
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"test.bhft.com/quotes"
+	"test.bhft.com/telemetry"
+)
+
+var bboChannel = "bbo-tbt"
+
+// bboData is a message of the bbo-tbt channel, the best level of each side
+// on every change.
+type bboData struct {
+	Asks  [][]string `json:"asks"`
+	Bids  [][]string `json:"bids"`
+	Ts    string     `json:"ts"`
+	SeqID int64      `json:"seqId"`
+}
+
+// QuoteStream reads the bbo-tbt channel of symbol until ctx is done or the
+// connection fails, then closes the returned channel.
+func (c *Client) QuoteStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan quotes.Quote, error) {
+	ch := make(chan quotes.Quote, 100)
+	conn, err := c.Subscribe(c.PublicURL, bboChannel, symbol, telemetry.StreamQuotes)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := slog.With("stream", telemetry.StreamQuotes, "symbol", symbol)
+	read(ctx, wg, conn, logger, c.Name(), telemetry.StreamQuotes, symbol, func(_ event, data []bboData) error {
+		for _, d := range data {
+			if len(d.Bids) == 0 || len(d.Asks) == 0 {
+				continue
+			}
+			ts := parseInt(d.Ts)
+			telemetry.ObserveEvent(telemetry.StreamQuotes, symbol, ts)
+			ch <- quotes.Quote{
+				Symbol:   symbol,
+				UpdateID: d.SeqID,
+				Time:     ts,
+				BidPrice: d.Bids[0][0],
+				BidQty:   d.Bids[0][1],
+				AskPrice: d.Asks[0][0],
+				AskQty:   d.Asks[0][1],
+			}
+			telemetry.QueueDepth.WithLabelValues(telemetry.StreamQuotes, symbol).Set(float64(len(ch)))
+		}
+		return nil
+	}, func() { close(ch) })
+	return ch, nil
+}
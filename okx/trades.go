@@ -0,0 +1,83 @@
+package okx
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+
+	"test.bhft.com/telemetry"
+	"test.bhft.com/trades"
+)
+
+var (
+	tradesPath    = "/api/v5/market/trades"
+	tradesChannel = "trades"
+	// maxTrades is the most trades the REST endpoint returns.
+	maxTrades = 500
+)
+
+type tradeData struct {
+	InstID  string `json:"instId"`
+	TradeID string `json:"tradeId"`
+	Px      string `json:"px"`
+	Sz      string `json:"sz"`
+	Side    string `json:"side"`
+	Ts      string `json:"ts"`
+}
+
+// trade converts a trade, side is the taker side so a sell was made against
+// a resting buyer. OKX sends no quote quantity, it is price times size.
+func (d tradeData) trade() trades.Trade {
+	price, _ := strconv.ParseFloat(d.Px, 64)
+	qty, _ := strconv.ParseFloat(d.Sz, 64)
+	return trades.Trade{
+		ID:            parseInt(d.TradeID),
+		Price:         d.Px,
+		Quantity:      d.Sz,
+		QuoteQuantity: strconv.FormatFloat(price*qty, 'f', -1, 64),
+		Time:          parseInt(d.Ts),
+		IsBuyerMaker:  d.Side == "sell",
+	}
+}
+
+// Trades loads the latest limit trades of symbol, at most 500.
+func (c *Client) Trades(symbol string, limit int) (*trades.List, error) {
+	params := url.Values{}
+	params.Add("instId", symbol)
+	params.Add("limit", strconv.Itoa(min(limit, maxTrades)))
+	var body []tradeData
+	if err := c.get(tradesPath, params, &body); err != nil {
+		return nil, err
+	}
+	list := make([]trades.Trade, 0, len(body))
+	for _, d := range body {
+		list = append(list, d.trade())
+	}
+	// OKX returns the newest trade first.
+	slices.Reverse(list)
+	return trades.New(symbol, list), nil
+}
+
+// TradeStream reads the trades channel of symbol until ctx is done or the
+// connection fails, then closes the returned channel.
+func (c *Client) TradeStream(ctx context.Context, wg *sync.WaitGroup, symbol string) (chan trades.Trade, error) {
+	ch := make(chan trades.Trade, 100)
+	conn, err := c.Subscribe(c.PublicURL, tradesChannel, symbol, telemetry.StreamTrades)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := slog.With("stream", telemetry.StreamTrades, "symbol", symbol)
+	read(ctx, wg, conn, logger, c.Name(), telemetry.StreamTrades, symbol, func(_ event, data []tradeData) error {
+		for _, d := range data {
+			telemetry.ObserveEvent(telemetry.StreamTrades, symbol, parseInt(d.Ts))
+			ch <- d.trade()
+			telemetry.QueueDepth.WithLabelValues(telemetry.StreamTrades, symbol).Set(float64(len(ch)))
+		}
+		return nil
+	}, func() { close(ch) })
+	return ch, nil
+}
@@ -0,0 +1,10 @@
+package okx
+
+import "testing"
+
+func TestTrade(t *testing.T) {
+	got := tradeData{TradeID: "7", Px: "65000.5", Sz: "0.02", Side: "sell", Ts: "1700000000000"}.trade()
+	if got.ID != 7 || got.Price != "65000.5" || got.Quantity != "0.02" || got.QuoteQuantity != "1300.01" || got.Time != 1700000000000 || !got.IsBuyerMaker {
+		t.Errorf("trade %+v", got)
+	}
+}
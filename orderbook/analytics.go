@@ -0,0 +1,300 @@
+package orderbook
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+)
+
+// Sides of a market order.
+const (
+	Buy  = "buy"
+	Sell = "sell"
+)
+
+// PriceLevel is a level with parsed price and quantity.
+type PriceLevel struct {
+	Price    float64
+	Quantity float64
+}
+
+func parseLevels(levels []Level) []PriceLevel {
+	res := make([]PriceLevel, 0, len(levels))
+	for _, l := range levels {
+		price, err := strconv.ParseFloat(l.Price, 64)
+		if err != nil {
+			continue
+		}
+		qty, err := strconv.ParseFloat(l.Quantity, 64)
+		if err != nil {
+			continue
+		}
+		res = append(res, PriceLevel{Price: price, Quantity: qty})
+	}
+	return res
+}
+
+// Fill describes how a hypothetical market order would execute against the
+// current book. Requested and Filled are in base asset for FillSize and in
+// quote asset for FillNotional; Filled is less than Requested when the book
+// is too thin. SlippageBps is measured from the best price on the side the
+// order takes, ImpactBps from the mid price.
+type Fill struct {
+	Side        string  `json:"side"`
+	Requested   float64 `json:"requested"`
+	Filled      float64 `json:"filled"`
+	Notional    float64 `json:"notional"`
+	AvgPrice    float64 `json:"avgPrice"`
+	WorstPrice  float64 `json:"worstPrice"`
+	SlippageBps float64 `json:"slippageBps"`
+	ImpactBps   float64 `json:"impactBps"`
+	Levels      int     `json:"levels"`
+}
+
+// Depth is the resting quantity and notional within a distance of the mid.
+type Depth struct {
+	Bps         float64 `json:"bps"`
+	BidQty      float64 `json:"bidQty"`
+	AskQty      float64 `json:"askQty"`
+	BidNotional float64 `json:"bidNotional"`
+	AskNotional float64 `json:"askNotional"`
+}
+
+// Top returns the best bid and ask. ok is false when a side is empty.
+func (ob *Book) Top() (bid, ask float64, ok bool) {
+	b, a, ok := ob.TopLevels()
+	return b.Price, a.Price, ok
+}
+
+// TopLevels returns the best bid and ask levels. ok is false when a side is
+// empty.
+func (ob *Book) TopLevels() (bid, ask PriceLevel, ok bool) {
+	s := ob.Snapshot(1)
+	bids, asks := parseLevels(s.Bids), parseLevels(s.Asks)
+	if len(bids) == 0 || len(asks) == 0 {
+		return bid, ask, false
+	}
+	return bids[0], asks[0], true
+}
+
+// Mid returns the mid price, 0 when a side is empty.
+func (ob *Book) Mid() float64 {
+	bid, ask, ok := ob.Top()
+	if !ok {
+		return 0
+	}
+	return (bid + ask) / 2
+}
+
+// FillSize walks the book for a market order of qty base asset. A buy takes
+// the asks, a sell takes the bids.
+func (ob *Book) FillSize(side string, qty float64) Fill {
+	return ob.fill(side, qty, false, 0)
+}
+
+// FillLimit walks the book for a limit order of qty base asset at price,
+// taking only the levels at price or better.
+func (ob *Book) FillLimit(side string, qty, price float64) Fill {
+	return ob.fill(side, qty, false, price)
+}
+
+// FillNotional walks the book for a market order spending notional quote asset.
+func (ob *Book) FillNotional(side string, notional float64) Fill {
+	return ob.fill(side, notional, true, 0)
+}
+
+// fill walks the levels of the side the order takes, stopping at the first
+// level worse than limit when it is set.
+func (ob *Book) fill(side string, amount float64, byNotional bool, limit float64) Fill {
+	s := ob.Snapshot(0)
+	bids, asks := parseLevels(s.Bids), parseLevels(s.Asks)
+	f := Fill{Side: side, Requested: amount}
+
+	levels := asks
+	if side == Sell {
+		levels = bids
+	}
+	if len(levels) == 0 {
+		return f
+	}
+
+	var base float64
+	left := amount
+	for _, l := range levels {
+		if left <= 0 {
+			break
+		}
+		if limit > 0 && ((side == Sell && l.Price < limit) || (side != Sell && l.Price > limit)) {
+			break
+		}
+		qty := min(l.Quantity, left)
+		if byNotional {
+			qty = min(l.Quantity, left/l.Price)
+			left -= qty * l.Price
+		} else {
+			left -= qty
+		}
+		base += qty
+		f.Notional += qty * l.Price
+		f.WorstPrice = l.Price
+		f.Levels++
+	}
+	if base == 0 {
+		return f
+	}
+	f.Filled = base
+	if byNotional {
+		f.Filled = f.Notional
+	}
+	f.AvgPrice = f.Notional / base
+
+	best := levels[0].Price
+	f.SlippageBps = (f.AvgPrice - best) / best * 10000
+	if side == Sell {
+		f.SlippageBps = -f.SlippageBps
+	}
+	if len(bids) > 0 && len(asks) > 0 {
+		mid := (bids[0].Price + asks[0].Price) / 2
+		f.ImpactBps = (f.AvgPrice - mid) / mid * 10000
+		if side == Sell {
+			f.ImpactBps = -f.ImpactBps
+		}
+	}
+	return f
+}
+
+// LevelQty returns the quantity resting at price on the bid side, or the
+// ask side with ask set, 0 when there is no such level.
+func (ob *Book) LevelQty(price float64, ask bool) float64 {
+	ob.Lock()
+	defer ob.Unlock()
+	side := ob.Bids
+	if ask {
+		side = ob.Asks
+	}
+	for p, q := range side {
+		if v, err := strconv.ParseFloat(p, 64); err == nil && v == price {
+			qty, _ := strconv.ParseFloat(q, 64)
+			return qty
+		}
+	}
+	return 0
+}
+
+// DepthWithin sums the resting liquidity priced within bps of the mid.
+func (ob *Book) DepthWithin(bps float64) Depth {
+	s := ob.Snapshot(0)
+	bids, asks := parseLevels(s.Bids), parseLevels(s.Asks)
+	d := Depth{Bps: bps}
+	if len(bids) == 0 || len(asks) == 0 {
+		return d
+	}
+	mid := (bids[0].Price + asks[0].Price) / 2
+	low, high := mid*(1-bps/10000), mid*(1+bps/10000)
+	for _, l := range bids {
+		if l.Price < low {
+			break
+		}
+		d.BidQty += l.Quantity
+		d.BidNotional += l.Quantity * l.Price
+	}
+	for _, l := range asks {
+		if l.Price > high {
+			break
+		}
+		d.AskQty += l.Quantity
+		d.AskNotional += l.Quantity * l.Price
+	}
+	return d
+}
+
+// Imbalance is (bid qty - ask qty) / (bid qty + ask qty) over the best
+// levels of each side, from -1 (all asks) to 1 (all bids).
+func (ob *Book) Imbalance(levels int) float64 {
+	s := ob.Snapshot(levels)
+	var bidQty, askQty float64
+	for _, l := range parseLevels(s.Bids) {
+		bidQty += l.Quantity
+	}
+	for _, l := range parseLevels(s.Asks) {
+		askQty += l.Quantity
+	}
+	if bidQty+askQty == 0 {
+		return 0
+	}
+	return (bidQty - askQty) / (bidQty + askQty)
+}
+
+// Metrics are the liquidity metrics of the book at Time.
+type Metrics struct {
+	Symbol    string          `json:"symbol"`
+	Time      int64           `json:"time"`
+	Mid       float64         `json:"mid"`
+	SpreadBps float64         `json:"spreadBps"`
+	Imbalance map[int]float64 `json:"imbalance"`
+	Depth     []Depth         `json:"depth"`
+	Fills     []Fill          `json:"fills"`
+}
+
+// MetricsConfig lists what is computed on every tick: fills for market
+// orders of each size on both sides, depth within each bps band and
+// imbalance over each number of levels.
+type MetricsConfig struct {
+	Interval time.Duration
+	Sizes    []float64
+	Bps      []float64
+	Levels   []int
+}
+
+// Metrics computes the metrics listed in cfg.
+func (ob *Book) Metrics(cfg MetricsConfig) Metrics {
+	m := Metrics{
+		Symbol:    ob.Symbol,
+		Time:      clock.Now().UnixMilli(),
+		Imbalance: make(map[int]float64),
+	}
+	if bid, ask, ok := ob.Top(); ok {
+		m.Mid = (bid + ask) / 2
+		m.SpreadBps = (ask - bid) / m.Mid * 10000
+	}
+	for _, n := range cfg.Levels {
+		m.Imbalance[n] = ob.Imbalance(n)
+	}
+	for _, bps := range cfg.Bps {
+		m.Depth = append(m.Depth, ob.DepthWithin(bps))
+	}
+	for _, size := range cfg.Sizes {
+		m.Fills = append(m.Fills, ob.FillSize(Buy, size), ob.FillSize(Sell, size))
+	}
+	return m
+}
+
+// StreamMetrics emits the book metrics every cfg.Interval until ctx is done.
+// A tick is skipped when the reader has not taken the previous value.
+func StreamMetrics(ctx context.Context, wg *sync.WaitGroup, orderBook *Book, cfg MetricsConfig) <-chan Metrics {
+	ch := make(chan Metrics, 1)
+	ticker := clock.NewTicker(cfg.Interval)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer ticker.Stop()
+		defer close(ch)
+		for {
+			select {
+			case <-ctx.Done():
+				slog.Info("book metrics are finished", "symbol", orderBook.Symbol)
+				return
+			case <-ticker.C:
+				select {
+				case ch <- orderBook.Metrics(cfg):
+				default:
+				}
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,308 @@
+// Package orderbook maintains a local L2 order book from a depth snapshot
+// and the diffs of the depth stream, and computes analytics on it.
+package orderbook
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/feed"
+)
+
+// Update is a diff of the depth stream of any venue. Prices and quantities
+// are kept as the decimal strings the venue sends, a zero quantity removes a
+// level. The diff holds every change after FirstUpdateID-1 up to
+// FinalUpdateID. The JSON names are those of the diff archive, which started
+// out as the Binance wire format.
+type Update struct {
+	EventTime     int64      `json:"E"`
+	Symbol        string     `json:"s"`
+	FirstUpdateID int64      `json:"U"`
+	FinalUpdateID int64      `json:"u"`
+	Bids          [][]string `json:"b"`
+	Asks          [][]string `json:"a"`
+}
+
+// Book is the L2 book of one symbol, price to quantity per side.
+type Book struct {
+	sync.Mutex
+	Symbol       string
+	Updated      bool
+	LastUpdateId int64
+	Bids         map[string]string
+	Asks         map[string]string
+	feed         feed.Feed[Update]
+	// gap is set when a diff skipped update IDs after the book was in sync,
+	// the book stays behind the stream from then on.
+	gap bool
+	// epoch counts the snapshots loaded by Reset and Load.
+	epoch int64
+}
+
+func New(symbol string) *Book {
+	return &Book{
+		Symbol: symbol,
+		Bids:   make(map[string]string),
+		Asks:   make(map[string]string),
+	}
+}
+
+// Update applies a diff from the depth stream and reports whether it was
+// applied. Diffs that are stale or out of sequence are skipped, applied
+// diffs are published to the subscribers.
+func (ob *Book) Update(update *Update) bool {
+	ob.Lock()
+	defer ob.Unlock()
+	if update.FinalUpdateID <= ob.LastUpdateId {
+		return false
+	}
+	if !ob.Updated {
+		if update.FirstUpdateID > ob.LastUpdateId+1 || update.FinalUpdateID < ob.LastUpdateId+1 {
+			return false
+		}
+		ob.Updated = true
+	} else {
+		if update.FirstUpdateID != ob.LastUpdateId+1 {
+			if update.FirstUpdateID > ob.LastUpdateId+1 {
+				ob.gap = true
+			}
+			return false
+		}
+	}
+	ob.LastUpdateId = update.FinalUpdateID
+	applyLevels(ob.Bids, update.Bids)
+	applyLevels(ob.Asks, update.Asks)
+	ob.feed.Publish(*update)
+	return true
+}
+
+func applyLevels(side map[string]string, levels [][]string) {
+	for _, l := range levels {
+		price, qty := l[0], l[1]
+		if isZero(qty) {
+			delete(side, price)
+			continue
+		}
+		side[price] = qty
+	}
+}
+
+// isZero reports whether qty is zero however the venue writes it, like
+// "0.00000000" on Binance or "0" on OKX.
+func isZero(qty string) bool {
+	v, err := strconv.ParseFloat(qty, 64)
+	return err == nil && v == 0
+}
+
+// Reset replaces the whole book with s, for venues that send snapshots on
+// the stream. The book is in sync from s on. Subscribers only see the diffs
+// that follow; the update IDs may jump or even restart lower, so they
+// notice the change from Epoch.
+func (ob *Book) Reset(s Snapshot) {
+	ob.Lock()
+	defer ob.Unlock()
+	ob.epoch++
+	ob.LastUpdateId = s.LastUpdateId
+	ob.Bids = make(map[string]string, len(s.Bids))
+	ob.Asks = make(map[string]string, len(s.Asks))
+	for _, l := range s.Bids {
+		ob.Bids[l.Price] = l.Quantity
+	}
+	for _, l := range s.Asks {
+		ob.Asks[l.Price] = l.Quantity
+	}
+	ob.Updated = true
+	ob.gap = false
+}
+
+// Replace swaps the whole book for s at once, for partial book streams
+// whose every message is the top of the book. The change is published to
+// the subscribers as a diff from the current update ID to the one of s, so
+// they can follow the book as if it came from diffs. Snapshots not newer
+// than the book are skipped.
+func (ob *Book) Replace(s Snapshot) (Update, bool) {
+	ob.Lock()
+	defer ob.Unlock()
+	if s.LastUpdateId <= ob.LastUpdateId {
+		return Update{}, false
+	}
+	u := Update{
+		EventTime:     s.Time,
+		Symbol:        ob.Symbol,
+		FirstUpdateID: ob.LastUpdateId + 1,
+		FinalUpdateID: s.LastUpdateId,
+	}
+	ob.Bids, u.Bids = replaceLevels(ob.Bids, s.Bids)
+	ob.Asks, u.Asks = replaceLevels(ob.Asks, s.Asks)
+	ob.LastUpdateId = s.LastUpdateId
+	ob.Updated = true
+	ob.gap = false
+	ob.feed.Publish(u)
+	return u, true
+}
+
+// replaceLevels returns the side made of levels and the diff turning side
+// into it.
+func replaceLevels(side map[string]string, levels []Level) (map[string]string, [][]string) {
+	next := make(map[string]string, len(levels))
+	var diff [][]string
+	for _, l := range levels {
+		next[l.Price] = l.Quantity
+		if side[l.Price] != l.Quantity {
+			diff = append(diff, []string{l.Price, l.Quantity})
+		}
+	}
+	for price := range side {
+		if _, ok := next[price]; !ok {
+			diff = append(diff, []string{price, "0"})
+		}
+	}
+	return next, diff
+}
+
+// Load replaces the book with a REST snapshot to resync it. As with the
+// first snapshot the book is in sync again once a diff spanning the update
+// ID of s is applied; diffs older than s are skipped until then.
+func (ob *Book) Load(s Snapshot) {
+	ob.Lock()
+	defer ob.Unlock()
+	ob.epoch++
+	ob.LastUpdateId = s.LastUpdateId
+	ob.Bids = make(map[string]string, len(s.Bids))
+	ob.Asks = make(map[string]string, len(s.Asks))
+	for _, l := range s.Bids {
+		ob.Bids[l.Price] = l.Quantity
+	}
+	for _, l := range s.Asks {
+		ob.Asks[l.Price] = l.Quantity
+	}
+	ob.Updated = false
+	ob.gap = false
+}
+
+// Invalidate marks the book as out of sync until the next Reset or Load,
+// for example when it does not match the venue checksum.
+func (ob *Book) Invalidate() {
+	ob.Lock()
+	defer ob.Unlock()
+	ob.gap = true
+}
+
+// Synced reports whether a diff from the depth stream was applied on top of
+// the snapshot the book started from and no update IDs were missed since,
+// or the book was reset from a stream snapshot.
+func (ob *Book) Synced() bool {
+	ob.Lock()
+	defer ob.Unlock()
+	return ob.Updated && !ob.gap
+}
+
+// LastID returns the update ID the book is at.
+func (ob *Book) LastID() int64 {
+	ob.Lock()
+	defer ob.Unlock()
+	return ob.LastUpdateId
+}
+
+// Epoch changes whenever Reset or Load replaced the book. Subscribers that
+// see it change stop applying diffs and take a new snapshot.
+func (ob *Book) Epoch() int64 {
+	ob.Lock()
+	defer ob.Unlock()
+	return ob.epoch
+}
+
+// Subscribe streams every diff applied to the book after the call.
+func (ob *Book) Subscribe(buf int) (<-chan Update, func()) {
+	return ob.feed.Subscribe(buf)
+}
+
+func (ob *Book) String() string {
+	ob.Lock()
+	defer ob.Unlock()
+	var sb strings.Builder
+	sb.WriteString("Bids:\n")
+	for price, qty := range ob.Bids {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", price, qty))
+	}
+	sb.WriteString("Asks:\n")
+	for price, qty := range ob.Asks {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", price, qty))
+	}
+	return sb.String()
+}
+
+// Level is one price level of a side.
+type Level struct {
+	Price    string `json:"price"`
+	Quantity string `json:"qty"`
+}
+
+// Snapshot is a copy of the book sorted by price, Time is in milliseconds.
+type Snapshot struct {
+	Symbol       string  `json:"symbol"`
+	Time         int64   `json:"time"`
+	LastUpdateId int64   `json:"lastUpdateId"`
+	Bids         []Level `json:"bids"`
+	Asks         []Level `json:"asks"`
+}
+
+// Snapshot copies the best depth levels of each side, bids from the highest
+// price and asks from the lowest. Depth 0 copies the whole book.
+func (ob *Book) Snapshot(depth int) Snapshot {
+	ob.Lock()
+	defer ob.Unlock()
+	return Snapshot{
+		Symbol:       ob.Symbol,
+		Time:         clock.Now().UnixMilli(),
+		LastUpdateId: ob.LastUpdateId,
+		Bids:         sortedLevels(ob.Bids, true, depth),
+		Asks:         sortedLevels(ob.Asks, false, depth),
+	}
+}
+
+func sortedLevels(side map[string]string, desc bool, depth int) []Level {
+	type level struct {
+		price float64
+		Level
+	}
+	levels := make([]level, 0, len(side))
+	for price, qty := range side {
+		p, err := strconv.ParseFloat(price, 64)
+		if err != nil {
+			continue
+		}
+		levels = append(levels, level{price: p, Level: Level{Price: price, Quantity: qty}})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if desc {
+			return levels[i].price > levels[j].price
+		}
+		return levels[i].price < levels[j].price
+	})
+	if depth > 0 && len(levels) > depth {
+		levels = levels[:depth]
+	}
+	res := make([]Level, len(levels))
+	for i, l := range levels {
+		res[i] = l.Level
+	}
+	return res
+}
+
+// FromSnapshot builds a book at the state of s.
+func FromSnapshot(s Snapshot) *Book {
+	ob := New(s.Symbol)
+	ob.LastUpdateId = s.LastUpdateId
+	for _, l := range s.Bids {
+		ob.Bids[l.Price] = l.Quantity
+	}
+	for _, l := range s.Asks {
+		ob.Asks[l.Price] = l.Quantity
+	}
+	return ob
+}
@@ -0,0 +1,336 @@
+// Package paper simulates orders of one symbol against the live order book
+// and trade stream, without sending anything to the exchange. Market orders
+// and the marketable part of limit orders take liquidity from the book; the
+// rest of a limit order rests with an estimate of the queue ahead of it,
+// worked down by the trades printed at its price.
+package paper
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/trades"
+)
+
+const (
+	Market = "market"
+	Limit  = "limit"
+
+	StatusOpen      = "open"
+	StatusFilled    = "filled"
+	StatusCancelled = "cancelled"
+
+	fillHistory = 1000
+)
+
+// Config sets the fees charged on fills, in basis points of the notional.
+type Config struct {
+	MakerFeeBps float64
+	TakerFeeBps float64
+}
+
+// Order is a simulated order. QueueAhead is the estimated quantity resting
+// before a limit order at its price. Times are in unix ms.
+type Order struct {
+	ID         int64   `json:"id"`
+	Side       string  `json:"side"`
+	Type       string  `json:"type"`
+	Price      float64 `json:"price,omitempty"`
+	Quantity   float64 `json:"qty"`
+	Filled     float64 `json:"filled"`
+	AvgPrice   float64 `json:"avgPrice,omitempty"`
+	QueueAhead float64 `json:"queueAhead,omitempty"`
+	Status     string  `json:"status"`
+	Time       int64   `json:"time"`
+}
+
+func (o *Order) remaining() float64 {
+	return o.Quantity - o.Filled
+}
+
+// Fill is one execution of an order. Maker is set when the order rested.
+type Fill struct {
+	OrderID  int64   `json:"orderId"`
+	Side     string  `json:"side"`
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"qty"`
+	Fee      float64 `json:"fee"`
+	Maker    bool    `json:"maker"`
+	Time     int64   `json:"time"`
+}
+
+// Account is the position and PnL of the simulated fills, in quote asset.
+// UnrealizedPnL is marked at Mark, the mid price or the last trade.
+type Account struct {
+	Position      float64 `json:"position"`
+	AvgPrice      float64 `json:"avgPrice"`
+	Mark          float64 `json:"mark"`
+	RealizedPnL   float64 `json:"realizedPnl"`
+	UnrealizedPnL float64 `json:"unrealizedPnl"`
+	Fees          float64 `json:"fees"`
+	NetPnL        float64 `json:"netPnl"`
+	Volume        float64 `json:"volume"`
+}
+
+// State is a copy of the simulator: the open orders by ID and the latest
+// fills, oldest first.
+type State struct {
+	Symbol  string  `json:"symbol"`
+	Account Account `json:"account"`
+	Orders  []Order `json:"orders"`
+	Fills   []Fill  `json:"fills"`
+}
+
+// Simulator matches the orders of one symbol. OnBook and OnTrade feed it
+// the live data, see collector.RunPaper.
+type Simulator struct {
+	sync.Mutex
+	Symbol string
+	cfg    Config
+	book   *orderbook.Book
+
+	nextID    int64
+	orders    []*Order
+	fills     []Fill
+	account   Account
+	lastTrade float64
+}
+
+func NewSimulator(symbol string, book *orderbook.Book, cfg Config) *Simulator {
+	return &Simulator{Symbol: symbol, cfg: cfg, book: book}
+}
+
+// Submit places an order. Market orders fill at once against the book and
+// drop what it cannot fill; limit orders take what crosses the book up to
+// price and rest the rest. The book must be in sync.
+func (s *Simulator) Submit(side, typ string, qty, price float64) (Order, error) {
+	if side != orderbook.Buy && side != orderbook.Sell {
+		return Order{}, fmt.Errorf("invalid side %q", side)
+	}
+	if qty <= 0 {
+		return Order{}, errors.New("quantity must be positive")
+	}
+	switch typ {
+	case Market:
+		price = 0
+	case Limit:
+		if price <= 0 {
+			return Order{}, errors.New("limit order needs a positive price")
+		}
+	default:
+		return Order{}, fmt.Errorf("invalid order type %q", typ)
+	}
+	if !s.book.Synced() {
+		return Order{}, errors.New("order book is out of sync")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	now := clock.Now().UnixMilli()
+	s.nextID++
+	o := &Order{ID: s.nextID, Side: side, Type: typ, Price: price, Quantity: qty, Status: StatusOpen, Time: now}
+
+	var f orderbook.Fill
+	if typ == Market {
+		f = s.book.FillSize(side, qty)
+	} else {
+		f = s.book.FillLimit(side, qty, price)
+	}
+	if f.Filled > 0 {
+		s.fill(o, f.AvgPrice, f.Filled, false, now)
+	}
+	switch {
+	case o.remaining() <= 0:
+	case typ == Market:
+		o.Status = StatusCancelled
+	default:
+		o.QueueAhead = s.book.LevelQty(price, side == orderbook.Sell)
+		s.orders = append(s.orders, o)
+	}
+	return *o, nil
+}
+
+// Cancel cancels the open order id. ok is false when there is none.
+func (s *Simulator) Cancel(id int64) (Order, bool) {
+	s.Lock()
+	defer s.Unlock()
+	for i, o := range s.orders {
+		if o.ID == id {
+			o.Status = StatusCancelled
+			s.orders = append(s.orders[:i], s.orders[i+1:]...)
+			return *o, true
+		}
+	}
+	return Order{}, false
+}
+
+// OnBook applies a book update: the queue ahead of a resting order can only
+// shrink to what is left at its price, and an order the opposite side has
+// moved through is filled at its price.
+func (s *Simulator) OnBook(u orderbook.Update) {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.orders) == 0 {
+		return
+	}
+	for _, o := range s.orders {
+		levels := u.Bids
+		if o.Side == orderbook.Sell {
+			levels = u.Asks
+		}
+		for _, l := range levels {
+			if len(l) < 2 {
+				continue
+			}
+			p, err := strconv.ParseFloat(l[0], 64)
+			if err != nil || p != o.Price {
+				continue
+			}
+			if q, err := strconv.ParseFloat(l[1], 64); err == nil && q < o.QueueAhead {
+				o.QueueAhead = q
+			}
+		}
+	}
+	bid, ask, ok := s.book.Top()
+	if !ok {
+		return
+	}
+	for _, o := range s.orders {
+		if (o.Side == orderbook.Buy && ask <= o.Price) || (o.Side == orderbook.Sell && bid >= o.Price) {
+			s.fill(o, o.Price, o.remaining(), true, u.EventTime)
+		}
+	}
+	s.prune()
+}
+
+// OnTrade works the resting orders on the side the trade hit: a trade
+// through the price fills them, one at the price first consumes the queue
+// ahead. Each order is estimated on its own, ignoring the others.
+func (s *Simulator) OnTrade(t trades.Trade) {
+	price, err := strconv.ParseFloat(t.Price, 64)
+	if err != nil {
+		return
+	}
+	qty, err := strconv.ParseFloat(t.Quantity, 64)
+	if err != nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.lastTrade = price
+	// A sell initiated trade hits the bids, a buy initiated one the asks.
+	side := orderbook.Sell
+	if t.IsBuyerMaker {
+		side = orderbook.Buy
+	}
+	for _, o := range s.orders {
+		if o.Side != side {
+			continue
+		}
+		through := (side == orderbook.Buy && price < o.Price) || (side == orderbook.Sell && price > o.Price)
+		switch {
+		case through:
+			s.fill(o, o.Price, o.remaining(), true, t.Time)
+		case price == o.Price:
+			left := qty - o.QueueAhead
+			o.QueueAhead = math.Max(0, o.QueueAhead-qty)
+			if left > 0 {
+				s.fill(o, o.Price, math.Min(left, o.remaining()), true, t.Time)
+			}
+		}
+	}
+	s.prune()
+}
+
+// fill executes qty of o at price and books it on the account.
+func (s *Simulator) fill(o *Order, price, qty float64, maker bool, now int64) {
+	if qty <= 0 {
+		return
+	}
+	feeBps := s.cfg.TakerFeeBps
+	if maker {
+		feeBps = s.cfg.MakerFeeBps
+	}
+	f := Fill{OrderID: o.ID, Side: o.Side, Price: price, Quantity: qty, Fee: price * qty * feeBps / 1e4, Maker: maker, Time: now}
+
+	o.AvgPrice = (o.AvgPrice*o.Filled + price*qty) / (o.Filled + qty)
+	o.Filled += qty
+	if o.remaining() <= 1e-12 {
+		o.Filled = o.Quantity
+		o.Status = StatusFilled
+	}
+
+	signed := qty
+	if o.Side == orderbook.Sell {
+		signed = -qty
+	}
+	s.apply(signed, price)
+	s.account.Fees += f.Fee
+	s.account.Volume += price * qty
+
+	s.fills = append(s.fills, f)
+	if len(s.fills) > fillHistory {
+		s.fills = s.fills[len(s.fills)-fillHistory:]
+	}
+}
+
+// apply moves the position by signed qty at price, realizing the PnL of the
+// part that reduces it.
+func (s *Simulator) apply(qty, price float64) {
+	a := &s.account
+	if a.Position == 0 || (a.Position > 0) == (qty > 0) {
+		a.AvgPrice = (a.AvgPrice*math.Abs(a.Position) + price*math.Abs(qty)) / (math.Abs(a.Position) + math.Abs(qty))
+		a.Position += qty
+		return
+	}
+	closed := math.Min(math.Abs(qty), math.Abs(a.Position))
+	sign := math.Copysign(1, a.Position)
+	a.RealizedPnL += (price - a.AvgPrice) * closed * sign
+	a.Position -= closed * sign
+	if rest := math.Abs(qty) - closed; rest > 1e-12 {
+		a.Position = math.Copysign(rest, qty)
+		a.AvgPrice = price
+	} else if math.Abs(a.Position) < 1e-12 {
+		a.Position, a.AvgPrice = 0, 0
+	}
+}
+
+// prune drops the orders that are no longer open.
+func (s *Simulator) prune() {
+	open := s.orders[:0]
+	for _, o := range s.orders {
+		if o.Status == StatusOpen {
+			open = append(open, o)
+		}
+	}
+	s.orders = open
+}
+
+// State returns a copy of the account, open orders and fills, marking the
+// position at the mid price, or the last trade when the book is not in sync.
+func (s *Simulator) State() State {
+	mark := 0.0
+	if s.book.Synced() {
+		mark = s.book.Mid()
+	}
+	s.Lock()
+	defer s.Unlock()
+	if mark == 0 {
+		mark = s.lastTrade
+	}
+	st := State{Symbol: s.Symbol, Account: s.account, Orders: make([]Order, 0, len(s.orders)), Fills: append([]Fill{}, s.fills...)}
+	for _, o := range s.orders {
+		st.Orders = append(st.Orders, *o)
+	}
+	st.Account.Mark = mark
+	if mark > 0 && st.Account.Position != 0 {
+		st.Account.UnrealizedPnL = (mark - st.Account.AvgPrice) * st.Account.Position
+	}
+	st.Account.NetPnL = st.Account.RealizedPnL + st.Account.UnrealizedPnL - st.Account.Fees
+	return st
+}
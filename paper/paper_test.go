@@ -0,0 +1,133 @@
+package paper
+
+import (
+	"math"
+	"testing"
+
+	"test.bhft.com/orderbook"
+	"test.bhft.com/trades"
+)
+
+// newSimulator trades against a synced book with 5 bid at 100 and 5 asked
+// at 101.
+func newSimulator() *Simulator {
+	book := orderbook.New("BTCUSDT")
+	book.Reset(orderbook.Snapshot{
+		Symbol:       "BTCUSDT",
+		LastUpdateId: 1,
+		Bids:         []orderbook.Level{{Price: "100", Quantity: "5"}},
+		Asks:         []orderbook.Level{{Price: "101", Quantity: "5"}},
+	})
+	return NewSimulator("BTCUSDT", book, Config{MakerFeeBps: 1, TakerFeeBps: 5})
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// TestApply moves the account through a flip and back to flat.
+func TestApply(t *testing.T) {
+	tests := []struct {
+		qty, price              float64
+		position, avg, realized float64
+	}{
+		{1, 100, 1, 100, 0},
+		{1, 110, 2, 105, 0},
+		// Closes the long at 120 and opens a short of 1 at the same price.
+		{-3, 120, -1, 120, 30},
+		{0.5, 110, -0.5, 120, 35},
+		{0.5, 100, 0, 0, 45},
+	}
+	s := newSimulator()
+	for i, tt := range tests {
+		s.apply(tt.qty, tt.price)
+		a := s.account
+		if !near(a.Position, tt.position) || !near(a.AvgPrice, tt.avg) || !near(a.RealizedPnL, tt.realized) {
+			t.Errorf("step %d: position %v at %v, realized %v, want %v at %v, realized %v", i, a.Position, a.AvgPrice, a.RealizedPnL, tt.position, tt.avg, tt.realized)
+		}
+	}
+}
+
+// TestApplyRounding checks closing a position with a quantity a rounding
+// error larger leaves it flat instead of flipping to a dust position.
+func TestApplyRounding(t *testing.T) {
+	s := newSimulator()
+	a, b := 0.1, 0.2
+	s.apply(0.3, 100)
+	s.apply(-(a + b), 110)
+	if s.account.Position != 0 || s.account.AvgPrice != 0 {
+		t.Errorf("position %v at %v, want flat", s.account.Position, s.account.AvgPrice)
+	}
+}
+
+// TestQueueAhead rests a buy behind the 5 bid at its price and works it
+// down with the trades printed there.
+func TestQueueAhead(t *testing.T) {
+	s := newSimulator()
+	o, err := s.Submit(orderbook.Buy, Limit, 2, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != StatusOpen || o.QueueAhead != 5 {
+		t.Fatalf("order %+v, want open behind 5", o)
+	}
+
+	steps := []struct {
+		price, qty   string
+		isBuyerMaker bool
+		queue        float64
+		filled       float64
+	}{
+		// A buy initiated trade hits the asks, not the resting buy.
+		{"100", "4", false, 5, 0},
+		{"100", "3", true, 2, 0},
+		// 2 clear the queue, 1 fills the order.
+		{"100", "3", true, 0, 1},
+		{"100", "0.5", true, 0, 1.5},
+		// A trade through the price fills the rest.
+		{"99.5", "0.1", true, 0, 2},
+	}
+	for i, st := range steps {
+		s.OnTrade(trades.Trade{ID: int64(i + 1), Price: st.price, Quantity: st.qty, IsBuyerMaker: st.isBuyerMaker, Time: int64(i + 1)})
+		state := s.State()
+		filled, queue := 2.0, 0.0
+		if len(state.Orders) == 1 {
+			filled, queue = state.Orders[0].Filled, state.Orders[0].QueueAhead
+		}
+		if !near(filled, st.filled) || !near(queue, st.queue) {
+			t.Errorf("trade %d: filled %v behind %v, want %v behind %v", i, filled, queue, st.filled, st.queue)
+		}
+	}
+
+	state := s.State()
+	if len(state.Orders) != 0 || len(state.Fills) != 3 {
+		t.Fatalf("orders %+v, fills %+v, want the order filled in 3 fills", state.Orders, state.Fills)
+	}
+	for _, f := range state.Fills {
+		if !f.Maker || f.Price != 100 {
+			t.Errorf("fill %+v, want a maker fill at 100", f)
+		}
+	}
+	if !near(state.Account.Position, 2) || !near(state.Account.Fees, 200*1e-4) {
+		t.Errorf("account %+v, want 2 long with 0.02 of fees", state.Account)
+	}
+}
+
+// TestQueueAheadBook checks the queue shrinks to what the book has left at
+// the price, and never grows back.
+func TestQueueAheadBook(t *testing.T) {
+	s := newSimulator()
+	if _, err := s.Submit(orderbook.Buy, Limit, 1, 100); err != nil {
+		t.Fatal(err)
+	}
+	for _, qty := range []string{"3", "4"} {
+		u := orderbook.Update{FirstUpdateID: s.book.LastID() + 1, FinalUpdateID: s.book.LastID() + 1, Bids: [][]string{{"100", qty}}}
+		if !s.book.Update(&u) {
+			t.Fatal("update not applied")
+		}
+		s.OnBook(u)
+	}
+	if q := s.State().Orders[0].QueueAhead; q != 3 {
+		t.Errorf("queue ahead %v, want 3", q)
+	}
+}
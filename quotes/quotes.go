@@ -0,0 +1,72 @@
+// Package quotes keeps the best bid and ask of a symbol from a top of book
+// feed and streams their changes.
+package quotes
+
+import (
+	"strconv"
+	"sync"
+
+	"test.bhft.com/feed"
+)
+
+// Quote is the best bid and ask of a symbol. Prices and quantities are the
+// decimal strings the venue sends, Time is in milliseconds. UpdateID orders
+// the quotes of a symbol, 0 when the venue does not send one.
+type Quote struct {
+	Symbol   string `json:"symbol"`
+	UpdateID int64  `json:"updateId"`
+	Time     int64  `json:"time"`
+	BidPrice string `json:"bidPrice"`
+	BidQty   string `json:"bidQty"`
+	AskPrice string `json:"askPrice"`
+	AskQty   string `json:"askQty"`
+}
+
+// Floats parses the prices and quantities of q, fields that do not parse
+// are 0.
+func (q Quote) Floats() (bidPrice, bidQty, askPrice, askQty float64) {
+	bidPrice, _ = strconv.ParseFloat(q.BidPrice, 64)
+	bidQty, _ = strconv.ParseFloat(q.BidQty, 64)
+	askPrice, _ = strconv.ParseFloat(q.AskPrice, 64)
+	askQty, _ = strconv.ParseFloat(q.AskQty, 64)
+	return bidPrice, bidQty, askPrice, askQty
+}
+
+// Latest holds the last quote of a symbol.
+type Latest struct {
+	sync.Mutex
+	Symbol string
+	quote  Quote
+	ok     bool
+	feed   feed.Feed[Quote]
+}
+
+func New(symbol string) *Latest {
+	return &Latest{Symbol: symbol}
+}
+
+// Update replaces the quote and publishes it to the subscribers. Quotes with
+// an update ID not above the current one are stale and skipped.
+func (l *Latest) Update(q Quote) bool {
+	l.Lock()
+	defer l.Unlock()
+	if l.ok && q.UpdateID != 0 && q.UpdateID <= l.quote.UpdateID {
+		return false
+	}
+	l.quote = q
+	l.ok = true
+	l.feed.Publish(q)
+	return true
+}
+
+// Get returns the last quote, ok is false before the first one.
+func (l *Latest) Get() (Quote, bool) {
+	l.Lock()
+	defer l.Unlock()
+	return l.quote, l.ok
+}
+
+// Subscribe streams every quote received after the call.
+func (l *Latest) Subscribe(buf int) (<-chan Quote, func()) {
+	return l.feed.Subscribe(buf)
+}
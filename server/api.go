@@ -0,0 +1,434 @@
+// Package server exposes the live markets and the stored history over
+// HTTP, websockets and gRPC.
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"test.bhft.com/indicators"
+	"test.bhft.com/klines"
+	"test.bhft.com/markets"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/storage"
+	"test.bhft.com/trades"
+)
+
+const (
+	defaultBookDepth   = 20
+	defaultTradesLimit = 100
+	maxTradesLimit     = 1000
+	defaultKlinesLimit = 500
+	maxKlinesLimit     = 1000
+)
+
+// APIServer serves the live state of the registered markets and the history
+// stored in Postgres as JSON.
+type APIServer struct {
+	markets *markets.Registry
+	db      *sql.DB
+	mux     *http.ServeMux
+}
+
+func NewAPIServer(registry *markets.Registry, db *sql.DB) *APIServer {
+	s := &APIServer{
+		markets: registry,
+		db:      db,
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /book/{symbol}", s.handleBook)
+	s.mux.HandleFunc("GET /quote/{symbol}", s.handleQuote)
+	s.mux.HandleFunc("GET /ticker/{symbol}", s.handleTicker)
+	s.mux.HandleFunc("GET /tape/{symbol}", s.handleTape)
+	s.mux.HandleFunc("GET /indicators/{symbol}", s.handleIndicators)
+	s.mux.HandleFunc("GET /paper/{symbol}", s.handlePaper)
+	s.mux.HandleFunc("POST /paper/{symbol}/orders", s.handlePaperSubmit)
+	s.mux.HandleFunc("DELETE /paper/{symbol}/orders/{id}", s.handlePaperCancel)
+	s.mux.HandleFunc("GET /trades/{symbol}", s.handleTrades)
+	s.mux.HandleFunc("GET /klines/{symbol}", s.handleKlines)
+	return s
+}
+
+// Handle registers an extra handler on the server.
+func (s *APIServer) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+func (s *APIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Run serves on addr until ctx is done.
+func (s *APIServer) Run(ctx context.Context, wg *sync.WaitGroup, addr string) {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: time.Second * 5,
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		slog.Info("api server listening", "addr", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("api server", "err", err)
+		}
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("api server shutdown", "err", err)
+		}
+		slog.Info("api server is finished")
+	}()
+}
+
+type bookResponse struct {
+	orderbook.Snapshot
+	Synced bool `json:"synced"`
+}
+
+func (s *APIServer) handleBook(w http.ResponseWriter, r *http.Request) {
+	m := s.market(w, r)
+	if m == nil {
+		return
+	}
+	if m.Book == nil {
+		writeError(w, http.StatusNotFound, "no order book for "+m.Symbol)
+		return
+	}
+	depth, ok := intParam(w, r, "depth", defaultBookDepth, 0, math.MaxInt)
+	if !ok {
+		return
+	}
+	snapshot := m.Book.Snapshot(depth)
+	writeJSON(w, http.StatusOK, bookResponse{Snapshot: snapshot, Synced: m.Book.Synced()})
+}
+
+func (s *APIServer) handleQuote(w http.ResponseWriter, r *http.Request) {
+	m := s.market(w, r)
+	if m == nil {
+		return
+	}
+	q, ok := m.BestQuote()
+	if !ok {
+		writeError(w, http.StatusNotFound, "no quote for "+m.Symbol)
+		return
+	}
+	writeJSON(w, http.StatusOK, q)
+}
+
+// handleTicker returns every venue and local ticker stats of the symbol,
+// sorted by window.
+func (s *APIServer) handleTicker(w http.ResponseWriter, r *http.Request) {
+	m := s.market(w, r)
+	if m == nil {
+		return
+	}
+	if m.Tickers == nil {
+		writeError(w, http.StatusNotFound, "no ticker stats for "+m.Symbol)
+		return
+	}
+	writeJSON(w, http.StatusOK, m.Tickers.All())
+}
+
+// handleTape returns the trade tape analytics of the symbol.
+func (s *APIServer) handleTape(w http.ResponseWriter, r *http.Request) {
+	m := s.market(w, r)
+	if m == nil {
+		return
+	}
+	if m.Tape == nil {
+		writeError(w, http.StatusNotFound, "no tape analytics for "+m.Symbol)
+		return
+	}
+	writeJSON(w, http.StatusOK, m.Tape.State())
+}
+
+type indicatorsResponse struct {
+	Symbol   string             `json:"symbol"`
+	Interval string             `json:"interval"`
+	Points   []indicators.Point `json:"points"`
+}
+
+// handleIndicators returns the last closed and the live value of every
+// indicator of the symbol.
+func (s *APIServer) handleIndicators(w http.ResponseWriter, r *http.Request) {
+	m := s.market(w, r)
+	if m == nil {
+		return
+	}
+	if m.Indicators == nil {
+		writeError(w, http.StatusNotFound, "no indicators for "+m.Symbol)
+		return
+	}
+	writeJSON(w, http.StatusOK, indicatorsResponse{Symbol: m.Symbol, Interval: m.Indicators.Interval, Points: m.Indicators.State()})
+}
+
+// paperMarket returns the market of the request when it runs a paper
+// simulator.
+func (s *APIServer) paperMarket(w http.ResponseWriter, r *http.Request) *markets.Market {
+	m := s.market(w, r)
+	if m == nil {
+		return nil
+	}
+	if m.Paper == nil {
+		writeError(w, http.StatusNotFound, "no paper trading for "+m.Symbol)
+		return nil
+	}
+	return m
+}
+
+// handlePaper returns the paper account, open orders and fills of the symbol.
+func (s *APIServer) handlePaper(w http.ResponseWriter, r *http.Request) {
+	if m := s.paperMarket(w, r); m != nil {
+		writeJSON(w, http.StatusOK, m.Paper.State())
+	}
+}
+
+type paperOrderRequest struct {
+	Side     string  `json:"side"`
+	Type     string  `json:"type"`
+	Quantity float64 `json:"qty"`
+	Price    float64 `json:"price"`
+}
+
+// handlePaperSubmit places a paper order and returns it as it stands after
+// the immediate fills.
+func (s *APIServer) handlePaperSubmit(w http.ResponseWriter, r *http.Request) {
+	m := s.paperMarket(w, r)
+	if m == nil {
+		return
+	}
+	var req paperOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid order: "+err.Error())
+		return
+	}
+	o, err := m.Paper.Submit(req.Side, req.Type, req.Quantity, req.Price)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, o)
+}
+
+func (s *APIServer) handlePaperCancel(w http.ResponseWriter, r *http.Request) {
+	m := s.paperMarket(w, r)
+	if m == nil {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid order id")
+		return
+	}
+	o, ok := m.Paper.Cancel(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no open order "+r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, o)
+}
+
+type tradesResponse struct {
+	Symbol string         `json:"symbol"`
+	Trades []trades.Trade `json:"trades"`
+	Next   int64          `json:"next,omitempty"`
+}
+
+func (s *APIServer) handleTrades(w http.ResponseWriter, r *http.Request) {
+	m := s.market(w, r)
+	if m == nil {
+		return
+	}
+	if m.Trades == nil {
+		writeError(w, http.StatusNotFound, "no trades for "+m.Symbol)
+		return
+	}
+	limit, ok := intParam(w, r, "limit", defaultTradesLimit, 1, maxTradesLimit)
+	if !ok {
+		return
+	}
+	fromID, ok := int64Param(w, r, "fromId", 0)
+	if !ok {
+		return
+	}
+	list, err := loadTrades(r.Context(), s.db, m, fromID, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := tradesResponse{Symbol: m.Symbol, Trades: list}
+	if fromID > 0 && len(resp.Trades) == limit {
+		resp.Next = resp.Trades[len(resp.Trades)-1].ID + 1
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type klinesResponse struct {
+	Symbol   string         `json:"symbol"`
+	Interval string         `json:"interval"`
+	Klines   []klines.Kline `json:"klines"`
+	Next     int64          `json:"next,omitempty"`
+}
+
+func (s *APIServer) handleKlines(w http.ResponseWriter, r *http.Request) {
+	m := s.market(w, r)
+	if m == nil {
+		return
+	}
+	interval := r.URL.Query().Get("interval")
+	if interval == "" && m.Klines != nil {
+		interval = m.Klines.Interval
+	}
+	if interval == "" {
+		writeError(w, http.StatusBadRequest, "interval is required")
+		return
+	}
+	start, ok := int64Param(w, r, "start", 0)
+	if !ok {
+		return
+	}
+	end, ok := int64Param(w, r, "end", math.MaxInt64)
+	if !ok {
+		return
+	}
+	if start > end {
+		writeError(w, http.StatusBadRequest, "start is after end")
+		return
+	}
+	limit, ok := intParam(w, r, "limit", defaultKlinesLimit, 1, maxKlinesLimit)
+	if !ok {
+		return
+	}
+
+	list, next, err := loadKlines(r.Context(), s.db, m, interval, start, end, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := klinesResponse{Symbol: m.Symbol, Interval: interval, Klines: list, Next: next}
+	if resp.Klines == nil {
+		resp.Klines = []klines.Kline{}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// loadTrades returns up to limit trades of m. With fromID 0 they are the
+// latest trades in memory. Otherwise they start at fromID: trades older than
+// the ones kept in memory are read from Postgres and topped up from memory.
+func loadTrades(ctx context.Context, db *sql.DB, m *markets.Market, fromID int64, limit int) ([]trades.Trade, error) {
+	if fromID == 0 || db == nil {
+		return m.Trades.Page(fromID, limit), nil
+	}
+	if first := m.Trades.FirstID(); first > 0 && fromID >= first {
+		return m.Trades.Page(fromID, limit), nil
+	}
+	list, err := storage.QueryTrades(ctx, db, m.Symbol, fromID, 0, math.MaxInt64, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) < limit {
+		next := fromID
+		if len(list) > 0 {
+			next = list[len(list)-1].ID + 1
+		}
+		list = append(list, m.Trades.Page(next, limit-len(list))...)
+	}
+	return list, nil
+}
+
+// loadKlines reads klines opened in [start, end] from Postgres and merges
+// the live list over them, so the current candle and any candle stored
+// before it closed come with their live values. When more than limit
+// klines match, next is the start of the following page.
+func loadKlines(ctx context.Context, db *sql.DB, m *markets.Market, interval string, start, end int64, limit int) ([]klines.Kline, int64, error) {
+	var list []klines.Kline
+	if db != nil {
+		stored, err := storage.QueryKlines(ctx, db, m.Symbol, interval, start, end, limit+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		list = stored
+	}
+	if m.Klines != nil && m.Klines.Interval == interval {
+		index := make(map[int64]int, len(list))
+		for i, k := range list {
+			index[k.OpenTime] = i
+		}
+		for _, k := range m.Klines.Range(start, end) {
+			if i, ok := index[k.OpenTime]; ok {
+				list[i] = k
+			} else {
+				list = append(list, k)
+			}
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].OpenTime < list[j].OpenTime
+		})
+	}
+	if len(list) > limit {
+		return list[:limit], list[limit].OpenTime, nil
+	}
+	return list, 0, nil
+}
+
+func (s *APIServer) market(w http.ResponseWriter, r *http.Request) *markets.Market {
+	symbol := r.PathValue("symbol")
+	m := s.markets.Get(symbol)
+	if m == nil {
+		writeError(w, http.StatusNotFound, "unknown symbol "+symbol)
+	}
+	return m
+}
+
+func intParam(w http.ResponseWriter, r *http.Request, name string, def, min, max int) (int, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, true
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < min || v > max {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("%s must be an integer between %d and %d", name, min, max))
+		return 0, false
+	}
+	return v, true
+}
+
+func int64Param(w http.ResponseWriter, r *http.Request, name string, def int64) (int64, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return def, true
+	}
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v < 0 {
+		writeError(w, http.StatusBadRequest, name+" must be a non negative integer")
+		return 0, false
+	}
+	return v, true
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("write response", "err", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
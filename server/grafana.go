@@ -0,0 +1,385 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/markets"
+	"test.bhft.com/telemetry"
+)
+
+// Series a Grafana target can ask for. A target is a series followed by a
+// symbol, for example "close.BTCUSDT", or "latency.book.BTCUSDT" for the
+// latency of one stream.
+const (
+	seriesCandles = "candles"
+	seriesOpen    = "open"
+	seriesHigh    = "high"
+	seriesLow     = "low"
+	seriesClose   = "close"
+	seriesVolume  = "volume"
+	seriesSpread  = "spread"
+	seriesLatency = "latency"
+)
+
+// GrafanaDatasource serves candle, volume, spread and latency series in two
+// flavours: the search/query/annotations API of the Grafana JSON datasource
+// and plain GET endpoints for the Infinity datasource.
+type GrafanaDatasource struct {
+	markets *markets.Registry
+	db      *sql.DB
+	mux     *http.ServeMux
+}
+
+func NewGrafanaDatasource(registry *markets.Registry, db *sql.DB) *GrafanaDatasource {
+	g := &GrafanaDatasource{
+		markets: registry,
+		db:      db,
+		mux:     http.NewServeMux(),
+	}
+	g.mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	g.mux.HandleFunc("POST /search", g.handleSearch)
+	g.mux.HandleFunc("POST /query", g.handleQuery)
+	g.mux.HandleFunc("POST /annotations", g.handleAnnotations)
+	g.mux.HandleFunc("GET /series/{target}", g.handleSeries)
+	g.mux.HandleFunc("GET /candles/{symbol}", g.handleCandles)
+	return g
+}
+
+func (g *GrafanaDatasource) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+func (g *GrafanaDatasource) targets() []string {
+	var targets []string
+	for _, symbol := range g.markets.Symbols() {
+		for _, s := range []string{seriesCandles, seriesOpen, seriesHigh, seriesLow, seriesClose, seriesVolume, seriesSpread} {
+			targets = append(targets, s+"."+symbol)
+		}
+	}
+	for _, stream := range telemetry.Latencies.Streams() {
+		targets = append(targets, seriesLatency+"."+stream)
+	}
+	return targets
+}
+
+type grafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+type grafanaTarget struct {
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+type grafanaQuery struct {
+	Range   grafanaRange    `json:"range"`
+	Targets []grafanaTarget `json:"targets"`
+}
+
+type grafanaTimeserie struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+type grafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+type grafanaTable struct {
+	Type    string          `json:"type"`
+	Columns []grafanaColumn `json:"columns"`
+	Rows    [][]any         `json:"rows"`
+}
+
+type grafanaAnnotationQuery struct {
+	Range      grafanaRange `json:"range"`
+	Annotation struct {
+		Name  string `json:"name"`
+		Query string `json:"query"`
+	} `json:"annotation"`
+}
+
+type grafanaAnnotation struct {
+	Time    int64    `json:"time"`
+	TimeEnd int64    `json:"timeEnd,omitempty"`
+	Title   string   `json:"title"`
+	Text    string   `json:"text"`
+	Tags    []string `json:"tags"`
+}
+
+func (g *GrafanaDatasource) handleSearch(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Target string `json:"target"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && r.ContentLength != 0 {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	res := make([]string, 0)
+	for _, t := range g.targets() {
+		if strings.Contains(strings.ToLower(t), strings.ToLower(req.Target)) {
+			res = append(res, t)
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (g *GrafanaDatasource) handleQuery(w http.ResponseWriter, r *http.Request) {
+	var req grafanaQuery
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	res := make([]any, 0, len(req.Targets))
+	for _, t := range req.Targets {
+		series, symbol, _ := strings.Cut(t.Target, ".")
+		if series == seriesCandles || t.Type == "table" {
+			table, err := g.candlesTable(r.Context(), symbol, req.Range.From, req.Range.To)
+			if err != nil {
+				writeError(w, grafanaStatus(err), err.Error())
+				return
+			}
+			res = append(res, table)
+			continue
+		}
+		points, err := g.series(r.Context(), t.Target, req.Range.From, req.Range.To)
+		if err != nil {
+			writeError(w, grafanaStatus(err), err.Error())
+			return
+		}
+		ts := grafanaTimeserie{Target: t.Target, Datapoints: make([][2]float64, len(points))}
+		for i, p := range points {
+			ts.Datapoints[i] = [2]float64{p.Value, float64(p.Time)}
+		}
+		res = append(res, ts)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// handleAnnotations marks the periods where a series crosses a threshold.
+// The annotation query looks like "spread.BTCUSDT > 5" or
+// "latency.book.BTCUSDT > 1000", with > or <.
+func (g *GrafanaDatasource) handleAnnotations(w http.ResponseWriter, r *http.Request) {
+	var req grafanaAnnotationQuery
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	target, op, threshold, err := parseThreshold(req.Annotation.Query)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	points, err := g.series(r.Context(), target, req.Range.From, req.Range.To)
+	if err != nil {
+		writeError(w, grafanaStatus(err), err.Error())
+		return
+	}
+	res := make([]grafanaAnnotation, 0)
+	var open *grafanaAnnotation
+	for _, p := range points {
+		hit := p.Value > threshold
+		if op == "<" {
+			hit = p.Value < threshold
+		}
+		if hit && open == nil {
+			open = &grafanaAnnotation{
+				Time:  p.Time,
+				Title: req.Annotation.Name,
+				Text:  fmt.Sprintf("%s %s %g: %g", target, op, threshold, p.Value),
+				Tags:  []string{target},
+			}
+		}
+		if !hit && open != nil {
+			open.TimeEnd = p.Time
+			res = append(res, *open)
+			open = nil
+		}
+	}
+	if open != nil {
+		open.TimeEnd = points[len(points)-1].Time
+		res = append(res, *open)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func parseThreshold(query string) (string, string, float64, error) {
+	for _, op := range []string{">", "<"} {
+		target, value, ok := strings.Cut(query, op)
+		if !ok {
+			continue
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return "", "", 0, fmt.Errorf("annotation query %q: %w", query, err)
+		}
+		return strings.TrimSpace(target), op, threshold, nil
+	}
+	return "", "", 0, fmt.Errorf("annotation query %q: expected <target> > <value> or <target> < <value>", query)
+}
+
+// handleSeries returns [{"time": ms, "value": v}] for the Infinity datasource.
+// from and to are in milliseconds, the default is the last 24 hours.
+func (g *GrafanaDatasource) handleSeries(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := msRange(w, r)
+	if !ok {
+		return
+	}
+	points, err := g.series(r.Context(), r.PathValue("target"), from, to)
+	if err != nil {
+		writeError(w, grafanaStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, points)
+}
+
+type candle struct {
+	Time   int64   `json:"time"`
+	Open   float64 `json:"open"`
+	High   float64 `json:"high"`
+	Low    float64 `json:"low"`
+	Close  float64 `json:"close"`
+	Volume float64 `json:"volume"`
+}
+
+// handleCandles returns OHLCV rows for the Infinity datasource.
+func (g *GrafanaDatasource) handleCandles(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := msRange(w, r)
+	if !ok {
+		return
+	}
+	candles, err := g.candles(r.Context(), r.PathValue("symbol"), from, to)
+	if err != nil {
+		writeError(w, grafanaStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, candles)
+}
+
+func msRange(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	now := clock.Now()
+	from, ok := int64Param(w, r, "from", now.Add(-time.Hour*24).UnixMilli())
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	to, ok := int64Param(w, r, "to", now.UnixMilli())
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	return time.UnixMilli(from), time.UnixMilli(to), true
+}
+
+type errUnknownTarget string
+
+func (e errUnknownTarget) Error() string {
+	return "unknown target " + string(e)
+}
+
+func grafanaStatus(err error) int {
+	if _, ok := err.(errUnknownTarget); ok {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func (g *GrafanaDatasource) candles(ctx context.Context, symbol string, from, to time.Time) ([]candle, error) {
+	m := g.markets.Get(symbol)
+	if m == nil || m.Klines == nil {
+		return nil, errUnknownTarget(seriesCandles + "." + symbol)
+	}
+	list, _, err := loadKlines(ctx, g.db, m, m.Klines.Interval, from.UnixMilli(), to.UnixMilli(), maxKlinesLimit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]candle, 0, len(list))
+	for _, k := range list {
+		c := candle{Time: k.OpenTime}
+		for _, f := range []struct {
+			dst *float64
+			src string
+		}{{&c.Open, k.Open}, {&c.High, k.High}, {&c.Low, k.Low}, {&c.Close, k.Close}, {&c.Volume, k.Volume}} {
+			*f.dst, _ = strconv.ParseFloat(f.src, 64)
+		}
+		res = append(res, c)
+	}
+	return res, nil
+}
+
+func (g *GrafanaDatasource) candlesTable(ctx context.Context, symbol string, from, to time.Time) (grafanaTable, error) {
+	t := grafanaTable{
+		Type: "table",
+		Columns: []grafanaColumn{
+			{Text: "time", Type: "time"},
+			{Text: "open", Type: "number"},
+			{Text: "high", Type: "number"},
+			{Text: "low", Type: "number"},
+			{Text: "close", Type: "number"},
+			{Text: "volume", Type: "number"},
+		},
+		Rows: make([][]any, 0),
+	}
+	candles, err := g.candles(ctx, symbol, from, to)
+	if err != nil {
+		return t, err
+	}
+	for _, c := range candles {
+		t.Rows = append(t.Rows, []any{c.Time, c.Open, c.High, c.Low, c.Close, c.Volume})
+	}
+	return t, nil
+}
+
+func (g *GrafanaDatasource) series(ctx context.Context, target string, from, to time.Time) ([]telemetry.SeriesPoint, error) {
+	series, symbol, _ := strings.Cut(target, ".")
+	switch series {
+	case seriesLatency:
+		return telemetry.Latencies.Range(symbol, from, to), nil
+	case seriesSpread:
+		return g.spread(ctx, symbol, from, to)
+	case seriesOpen, seriesHigh, seriesLow, seriesClose, seriesVolume:
+		candles, err := g.candles(ctx, symbol, from, to)
+		if err != nil {
+			return nil, errUnknownTarget(target)
+		}
+		res := make([]telemetry.SeriesPoint, len(candles))
+		for i, c := range candles {
+			v := map[string]float64{seriesOpen: c.Open, seriesHigh: c.High, seriesLow: c.Low, seriesClose: c.Close, seriesVolume: c.Volume}[series]
+			res[i] = telemetry.SeriesPoint{Time: c.Time, Value: v}
+		}
+		return res, nil
+	}
+	return nil, errUnknownTarget(target)
+}
+
+// spread reads the quoted spread in bps stored by the features engine.
+func (g *GrafanaDatasource) spread(ctx context.Context, symbol string, from, to time.Time) ([]telemetry.SeriesPoint, error) {
+	res := make([]telemetry.SeriesPoint, 0)
+	if g.db == nil {
+		return res, nil
+	}
+	rows, err := g.db.QueryContext(ctx, "SELECT time, quoted_spread_bps FROM features WHERE symbol = $1 AND time >= $2 AND time <= $3 ORDER BY time",
+		strings.ToUpper(symbol), from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p telemetry.SeriesPoint
+		if err := rows.Scan(&p.Time, &p.Value); err != nil {
+			return nil, err
+		}
+		res = append(res, p)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,340 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"test.bhft.com/klines"
+	pb "test.bhft.com/marketdatapb"
+	"test.bhft.com/markets"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/storage"
+	"test.bhft.com/trades"
+)
+
+// GRPCServer implements the MarketData service on top of the registered
+// markets and the Postgres storage.
+type GRPCServer struct {
+	pb.UnimplementedMarketDataServer
+	markets *markets.Registry
+	db      *sql.DB
+}
+
+func NewGRPCServer(registry *markets.Registry, db *sql.DB) *GRPCServer {
+	return &GRPCServer{markets: registry, db: db}
+}
+
+// Run serves on addr until ctx is done.
+func (s *GRPCServer) Run(ctx context.Context, wg *sync.WaitGroup, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := grpc.NewServer()
+	pb.RegisterMarketDataServer(srv, s)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		slog.Info("grpc server listening", "addr", addr)
+		if err := srv.Serve(lis); err != nil {
+			slog.Error("grpc server", "err", err)
+		}
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		stopped := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second * 5):
+			srv.Stop()
+		}
+		slog.Info("grpc server is finished")
+	}()
+	return nil
+}
+
+func (s *GRPCServer) market(symbol string) (*markets.Market, error) {
+	m := s.markets.Get(symbol)
+	if m == nil {
+		return nil, status.Errorf(codes.NotFound, "unknown symbol %q", symbol)
+	}
+	return m, nil
+}
+
+func (s *GRPCServer) book(symbol string) (*markets.Market, error) {
+	m, err := s.market(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if m.Book == nil {
+		return nil, status.Errorf(codes.NotFound, "no order book for %s", m.Symbol)
+	}
+	return m, nil
+}
+
+func (s *GRPCServer) GetOrderBook(ctx context.Context, req *pb.GetOrderBookRequest) (*pb.OrderBookSnapshot, error) {
+	m, err := s.book(req.GetSymbol())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetDepth() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "depth must not be negative")
+	}
+	return snapshotToProto(m.Book.Snapshot(int(req.GetDepth()))), nil
+}
+
+func (s *GRPCServer) GetOrderBookAt(ctx context.Context, req *pb.GetOrderBookAtRequest) (*pb.OrderBookSnapshot, error) {
+	if s.db == nil {
+		return nil, status.Error(codes.Unavailable, "no storage configured")
+	}
+	if req.GetDepth() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "depth must not be negative")
+	}
+	orderBook, err := storage.BookAt(s.db, req.GetSymbol(), time.UnixMilli(req.GetTime()))
+	if errors.Is(err, storage.ErrNoSnapshot) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	snapshot := orderBook.Snapshot(int(req.GetDepth()))
+	snapshot.Time = req.GetTime()
+	return snapshotToProto(snapshot), nil
+}
+
+func (s *GRPCServer) GetTrades(ctx context.Context, req *pb.GetTradesRequest) (*pb.GetTradesResponse, error) {
+	m, err := s.market(req.GetSymbol())
+	if err != nil {
+		return nil, err
+	}
+	if m.Trades == nil {
+		return nil, status.Errorf(codes.NotFound, "no trades for %s", m.Symbol)
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultTradesLimit
+	}
+	if limit < 0 || limit > maxTradesLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxTradesLimit)
+	}
+	list, err := loadTrades(ctx, s.db, m, req.GetFromId(), limit)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp := &pb.GetTradesResponse{}
+	for _, t := range list {
+		resp.Trades = append(resp.Trades, tradeToProto(m.Symbol, t))
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetKlines(ctx context.Context, req *pb.GetKlinesRequest) (*pb.GetKlinesResponse, error) {
+	m, err := s.market(req.GetSymbol())
+	if err != nil {
+		return nil, err
+	}
+	interval := req.GetInterval()
+	if interval == "" && m.Klines != nil {
+		interval = m.Klines.Interval
+	}
+	if interval == "" {
+		return nil, status.Error(codes.InvalidArgument, "interval is required")
+	}
+	end := req.GetEnd()
+	if end == 0 {
+		end = math.MaxInt64
+	}
+	if req.GetStart() > end {
+		return nil, status.Error(codes.InvalidArgument, "start is after end")
+	}
+	limit := int(req.GetLimit())
+	if limit == 0 {
+		limit = defaultKlinesLimit
+	}
+	if limit < 0 || limit > maxKlinesLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", maxKlinesLimit)
+	}
+	list, next, err := loadKlines(ctx, s.db, m, interval, req.GetStart(), end, limit)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp := &pb.GetKlinesResponse{Next: next}
+	for _, k := range list {
+		resp.Klines = append(resp.Klines, klineToProto(k))
+	}
+	return resp, nil
+}
+
+// SubscribeOrderBook sends a snapshot and then the applied deltas. The book
+// feed drops deltas for a stream that cannot keep up, so a gap in the update
+// IDs, or a book replaced by a resync, is answered with a fresh snapshot.
+func (s *GRPCServer) SubscribeOrderBook(req *pb.SubscribeOrderBookRequest, stream grpc.ServerStreamingServer[pb.OrderBookEvent]) error {
+	m, err := s.book(req.GetSymbol())
+	if err != nil {
+		return err
+	}
+	ch, unsubscribe := m.Book.Subscribe(1000)
+	defer unsubscribe()
+
+	var epoch int64
+	sendSnapshot := func() (int64, error) {
+		epoch = m.Book.Epoch()
+		snapshot := m.Book.Snapshot(0)
+		err := stream.Send(&pb.OrderBookEvent{Event: &pb.OrderBookEvent_Snapshot{Snapshot: snapshotToProto(snapshot)}})
+		return snapshot.LastUpdateId, err
+	}
+	last, err := sendSnapshot()
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case u, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if m.Book.Epoch() == epoch && u.FinalUpdateID <= last {
+				continue
+			}
+			if m.Book.Epoch() != epoch || u.FirstUpdateID > last+1 {
+				if last, err = sendSnapshot(); err != nil {
+					return err
+				}
+				continue
+			}
+			delta := &pb.OrderBookDelta{
+				Symbol:        m.Symbol,
+				EventTime:     u.EventTime,
+				FirstUpdateId: u.FirstUpdateID,
+				FinalUpdateId: u.FinalUpdateID,
+				Bids:          levelsToProto(toLevels(u.Bids)),
+				Asks:          levelsToProto(toLevels(u.Asks)),
+			}
+			if err := stream.Send(&pb.OrderBookEvent{Event: &pb.OrderBookEvent_Delta{Delta: delta}}); err != nil {
+				return err
+			}
+			last = u.FinalUpdateID
+		}
+	}
+}
+
+func (s *GRPCServer) SubscribeTrades(req *pb.SubscribeTradesRequest, stream grpc.ServerStreamingServer[pb.Trade]) error {
+	m, err := s.market(req.GetSymbol())
+	if err != nil {
+		return err
+	}
+	if m.Trades == nil {
+		return status.Errorf(codes.NotFound, "no trades for %s", m.Symbol)
+	}
+	ch, unsubscribe := m.Trades.Subscribe(1000)
+	defer unsubscribe()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case t, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(tradeToProto(m.Symbol, t)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *GRPCServer) SubscribeKlines(req *pb.SubscribeKlinesRequest, stream grpc.ServerStreamingServer[pb.KlineUpdate]) error {
+	m, err := s.market(req.GetSymbol())
+	if err != nil {
+		return err
+	}
+	if m.Klines == nil {
+		return status.Errorf(codes.NotFound, "no klines for %s", m.Symbol)
+	}
+	ch, unsubscribe := m.Klines.Subscribe(100)
+	defer unsubscribe()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case k, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			err := stream.Send(&pb.KlineUpdate{
+				Symbol:   k.Symbol,
+				Interval: k.Interval,
+				Kline:    klineToProto(k.Kline),
+				Closed:   k.Closed,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func levelsToProto(levels []orderbook.Level) []*pb.Level {
+	res := make([]*pb.Level, len(levels))
+	for i, l := range levels {
+		res[i] = &pb.Level{Price: l.Price, Quantity: l.Quantity}
+	}
+	return res
+}
+
+func snapshotToProto(s orderbook.Snapshot) *pb.OrderBookSnapshot {
+	return &pb.OrderBookSnapshot{
+		Symbol:       s.Symbol,
+		Time:         s.Time,
+		LastUpdateId: s.LastUpdateId,
+		Bids:         levelsToProto(s.Bids),
+		Asks:         levelsToProto(s.Asks),
+	}
+}
+
+func tradeToProto(symbol string, t trades.Trade) *pb.Trade {
+	return &pb.Trade{
+		Symbol:        symbol,
+		Id:            t.ID,
+		Price:         t.Price,
+		Quantity:      t.Quantity,
+		QuoteQuantity: t.QuoteQuantity,
+		Time:          t.Time,
+		IsBuyerMaker:  t.IsBuyerMaker,
+	}
+}
+
+func klineToProto(k klines.Kline) *pb.Kline {
+	return &pb.Kline{
+		OpenTime:                 k.OpenTime,
+		CloseTime:                k.CloseTime,
+		Open:                     k.Open,
+		High:                     k.High,
+		Low:                      k.Low,
+		Close:                    k.Close,
+		Volume:                   k.Volume,
+		QuoteAssetVolume:         k.QuoteAssetVolume,
+		NumberOfTrades:           k.NumberOfTrades,
+		TakerBuyBaseAssetVolume:  k.TakerBuyBaseAssetVolume,
+		TakerBuyQuoteAssetVolume: k.TakerBuyQuoteAssetVolume,
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"test.bhft.com/clock"
+	"test.bhft.com/markets"
+	"test.bhft.com/telemetry"
+)
+
+// HealthConfig sets when an instance stops being ready: a required feed
+// without a message for StaleAfter or an order book out of sync.
+// StreamStaleAfter overrides StaleAfter for feeds polled less often, like
+// openInterest.
+type HealthConfig struct {
+	StaleAfter       time.Duration
+	StreamStaleAfter map[string]time.Duration
+	Required         []string
+}
+
+// eventStreams only send a message when something happens, they are only
+// required to be connected.
+var eventStreams = []string{telemetry.StreamLiquidations}
+
+// FeedStatus is the state of one stream of a symbol. LastMessage is in unix
+// ms and 0 before the first message.
+type FeedStatus struct {
+	Stream      string  `json:"stream"`
+	Connected   bool    `json:"connected"`
+	LastMessage int64   `json:"lastMessage"`
+	Age         float64 `json:"ageSeconds"`
+	Stale       bool    `json:"stale"`
+	Required    bool    `json:"required"`
+}
+
+type BookStatus struct {
+	Synced       bool  `json:"synced"`
+	LastUpdateId int64 `json:"lastUpdateId"`
+}
+
+type SymbolStatus struct {
+	Symbol   string                  `json:"symbol"`
+	Venue    string                  `json:"venue"`
+	Ready    bool                    `json:"ready"`
+	Problems []string                `json:"problems,omitempty"`
+	Feeds    []FeedStatus            `json:"feeds"`
+	Book     *BookStatus             `json:"book,omitempty"`
+	Storage  []telemetry.TableStatus `json:"storage"`
+}
+
+// Status reports the state of the feeds m runs at now. Required feeds the
+// market does not run, like futures feeds on a spot venue, are left out.
+func Status(m *markets.Market, cfg HealthConfig, now time.Time) SymbolStatus {
+	st := SymbolStatus{Symbol: m.Symbol, Venue: m.Venue, Ready: true}
+	for _, stream := range m.Feeds {
+		fs := FeedStatus{Stream: stream, Required: contains(cfg.Required, stream)}
+		f := telemetry.Health.Feed(m.Venue, stream, m.Symbol)
+		fs.Connected = f.Connected
+		if !f.LastMessage.IsZero() {
+			fs.LastMessage = f.LastMessage.UnixMilli()
+			fs.Age = now.Sub(f.LastMessage).Seconds()
+		}
+		staleAfter := cfg.StaleAfter
+		if d, ok := cfg.StreamStaleAfter[stream]; ok {
+			staleAfter = d
+		}
+		fs.Stale = fs.LastMessage == 0 || (staleAfter > 0 && now.Sub(time.UnixMilli(fs.LastMessage)) > staleAfter)
+		if fs.Required {
+			switch {
+			case !fs.Connected:
+				st.Problems = append(st.Problems, stream+" feed is not connected")
+			case fs.Stale && !contains(eventStreams, stream):
+				st.Problems = append(st.Problems, stream+" feed is stale")
+			}
+		}
+		st.Feeds = append(st.Feeds, fs)
+	}
+	st.Storage = telemetry.Health.Tables(m.Symbol)
+
+	if m.Book != nil {
+		st.Book = &BookStatus{Synced: m.Book.Synced(), LastUpdateId: m.Book.LastID()}
+		if contains(cfg.Required, telemetry.StreamBook) && !st.Book.Synced {
+			st.Problems = append(st.Problems, "order book is out of sync")
+		}
+	}
+	st.Ready = len(st.Problems) == 0
+	return st
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// HealthServer serves /healthz, /readyz and the per symbol status.
+type HealthServer struct {
+	markets *markets.Registry
+	cfg     HealthConfig
+	mux     *http.ServeMux
+}
+
+func NewHealthServer(registry *markets.Registry, cfg HealthConfig) *HealthServer {
+	s := &HealthServer{
+		markets: registry,
+		cfg:     cfg,
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /healthz", s.handleHealthz)
+	s.mux.HandleFunc("GET /readyz", s.handleReadyz)
+	s.mux.HandleFunc("GET /status", s.handleStatusAll)
+	s.mux.HandleFunc("GET /status/{symbol}", s.handleStatus)
+	return s
+}
+
+// Patterns lists the routes to register on the API server.
+func (s *HealthServer) Patterns() []string {
+	return []string{"GET /healthz", "GET /readyz", "GET /status", "GET /status/{symbol}"}
+}
+
+func (s *HealthServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleHealthz only tells the process is serving, feed problems are
+// reported by /readyz.
+func (s *HealthServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprintln(w, "ok")
+}
+
+func (s *HealthServer) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	now := clock.Now()
+	var problems []string
+	for _, symbol := range s.markets.Symbols() {
+		st := Status(s.markets.Get(symbol), s.cfg, now)
+		for _, p := range st.Problems {
+			problems = append(problems, symbol+": "+p)
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	if len(problems) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, strings.Join(problems, "\n"))
+		return
+	}
+	fmt.Fprintln(w, "ready")
+}
+
+func (s *HealthServer) handleStatusAll(w http.ResponseWriter, r *http.Request) {
+	now := clock.Now()
+	statuses := []SymbolStatus{}
+	for _, symbol := range s.markets.Symbols() {
+		statuses = append(statuses, Status(s.markets.Get(symbol), s.cfg, now))
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *HealthServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	m := s.markets.Get(r.PathValue("symbol"))
+	if m == nil {
+		writeError(w, http.StatusNotFound, "unknown symbol")
+		return
+	}
+	writeJSON(w, http.StatusOK, Status(m, s.cfg, clock.Now()))
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"test.bhft.com/markets"
+	"test.bhft.com/telemetry"
+)
+
+// TestStatusPerVenue checks the same symbol on two venues reports its own
+// feeds, and storage only lists the tables the symbol was written to.
+func TestStatusPerVenue(t *testing.T) {
+	telemetry.Health = telemetry.NewTracker()
+	telemetry.Health.Connected("binance", telemetry.StreamTrades, "BTCUSDT")
+	telemetry.Health.Message("binance", telemetry.StreamTrades, "BTCUSDT")
+	telemetry.Health.Flush("klines", "BTCUSDT", nil)
+	telemetry.Health.Flush("quotes", "ETHUSDT", errors.New("connection refused"))
+
+	cfg := HealthConfig{StaleAfter: time.Minute, Required: []string{telemetry.StreamTrades}}
+	now := time.Now()
+	feeds := []string{telemetry.StreamTrades}
+	binance := Status(&markets.Market{Symbol: "BTCUSDT", Venue: "binance", Feeds: feeds}, cfg, now)
+	other := Status(&markets.Market{Symbol: "BTCUSDT", Venue: "other", Feeds: feeds}, cfg, now)
+
+	if !binance.Ready || binance.Venue != "binance" {
+		t.Errorf("binance status %+v, want ready", binance)
+	}
+	if other.Ready || len(other.Problems) != 1 || other.Problems[0] != "trades feed is not connected" {
+		t.Errorf("other venue status %+v, want its trades feed not connected", other)
+	}
+	if len(binance.Storage) != 1 || binance.Storage[0].Table != "klines" || binance.Storage[0].LastError != "" {
+		t.Errorf("storage %+v, want only the klines flush", binance.Storage)
+	}
+}
+
+// TestStatusFeeds checks only the feeds the market runs are reported, open
+// interest is stale after its own period and liquidations only need to be
+// connected.
+func TestStatusFeeds(t *testing.T) {
+	telemetry.Health = telemetry.NewTracker()
+	for _, stream := range []string{telemetry.StreamOpenInterest, telemetry.StreamLiquidations} {
+		telemetry.Health.Connected("binance", stream, "BTCUSDT")
+	}
+	telemetry.Health.Message("binance", telemetry.StreamOpenInterest, "BTCUSDT")
+
+	cfg := HealthConfig{
+		StaleAfter:       time.Second,
+		StreamStaleAfter: map[string]time.Duration{telemetry.StreamOpenInterest: 2 * time.Minute},
+		Required:         []string{telemetry.StreamMarkPrice, telemetry.StreamOpenInterest, telemetry.StreamLiquidations, telemetry.StreamQuotes},
+	}
+	m := &markets.Market{
+		Symbol: "BTCUSDT",
+		Venue:  "binance",
+		Feeds:  []string{telemetry.StreamMarkPrice, telemetry.StreamOpenInterest, telemetry.StreamLiquidations},
+	}
+	st := Status(m, cfg, time.Now().Add(time.Minute))
+
+	if len(st.Feeds) != 3 {
+		t.Errorf("feeds %+v, want markPrice, openInterest and liquidations", st.Feeds)
+	}
+	if len(st.Problems) != 1 || st.Problems[0] != "markPrice feed is not connected" {
+		t.Errorf("problems %q, want only markPrice not connected", st.Problems)
+	}
+}
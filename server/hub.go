@@ -0,0 +1,427 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"test.bhft.com/markets"
+	"test.bhft.com/orderbook"
+	"test.bhft.com/telemetry"
+)
+
+// Topics a client can subscribe to, each followed by a symbol, for example
+// "book.BTCUSDT".
+const (
+	topicBook   = telemetry.StreamBook
+	topicTop    = "top"
+	topicTrades = telemetry.StreamTrades
+	topicKlines = telemetry.StreamKlines
+)
+
+const (
+	clientBuffer    = 256
+	clientWriteWait = time.Second * 10
+	clientPingEvery = time.Second * 30
+)
+
+// HubEvent is the normalized message sent to hub clients.
+type HubEvent struct {
+	Topic  string `json:"topic"`
+	Type   string `json:"type"`
+	Symbol string `json:"symbol"`
+	Time   int64  `json:"time"`
+	Data   any    `json:"data"`
+}
+
+// BookDelta is a depth diff with exchange specific names removed. A client
+// applies deltas whose FinalUpdateId is above the snapshot's LastUpdateId.
+// When the hub misses deltas or the book is resynced from a new snapshot it
+// broadcasts a fresh snapshot on the book topic, which replaces the book of
+// the client.
+type BookDelta struct {
+	FirstUpdateId int64             `json:"firstUpdateId"`
+	FinalUpdateId int64             `json:"finalUpdateId"`
+	Bids          []orderbook.Level `json:"bids"`
+	Asks          []orderbook.Level `json:"asks"`
+}
+
+type TopOfBook struct {
+	BidPrice float64 `json:"bidPrice"`
+	BidQty   float64 `json:"bidQty"`
+	AskPrice float64 `json:"askPrice"`
+	AskQty   float64 `json:"askQty"`
+}
+
+type hubRequest struct {
+	Op     string   `json:"op"`
+	Topics []string `json:"topics"`
+}
+
+type hubClient struct {
+	conn   *websocket.Conn
+	send   chan []byte
+	topics map[string]bool
+	once   sync.Once
+	done   chan struct{}
+}
+
+// kick disconnects the client, it is used when its buffer overflows so a
+// slow client never holds up the others.
+func (c *hubClient) kick(reason string) {
+	c.once.Do(func() {
+		close(c.done)
+		c.conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason),
+			time.Now().Add(time.Second))
+		c.conn.Close()
+	})
+}
+
+// Hub re-broadcasts the events of the registered markets to websocket
+// clients.
+type Hub struct {
+	sync.Mutex
+	markets  *markets.Registry
+	clients  map[*hubClient]bool
+	upgrader websocket.Upgrader
+}
+
+func NewHub(registry *markets.Registry) *Hub {
+	return &Hub{
+		markets: registry,
+		clients: make(map[*hubClient]bool),
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// Run forwards the pipelines of every market registered so far until ctx is
+// done.
+func (h *Hub) Run(ctx context.Context, wg *sync.WaitGroup) {
+	for _, symbol := range h.markets.Symbols() {
+		m := h.markets.Get(symbol)
+		// The top topic follows the quote feed when it runs, the book
+		// otherwise.
+		if m.Book != nil {
+			h.forwardBook(ctx, wg, m, m.Quotes == nil)
+		}
+		if m.Quotes != nil {
+			h.forwardQuotes(ctx, wg, m)
+		}
+		if m.Trades != nil {
+			h.forwardTrades(ctx, wg, m)
+		}
+		if m.Klines != nil {
+			h.forwardKlines(ctx, wg, m)
+		}
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		h.Lock()
+		defer h.Unlock()
+		for c := range h.clients {
+			c.kick("server is shutting down")
+		}
+		slog.Info("hub is finished")
+	}()
+}
+
+// forwardBook broadcasts the deltas of the book of m. It follows them on a
+// mirror of the book, so a delta that does not continue the mirror, because
+// the lossy feed dropped some or the book was replaced, makes it broadcast a
+// fresh snapshot instead. The top topic is read from the mirror too, so it
+// matches the deltas sent.
+func (h *Hub) forwardBook(ctx context.Context, wg *sync.WaitGroup, m *markets.Market, top bool) {
+	ch, unsubscribe := m.Book.Subscribe(1000)
+	topic := topicBook + "." + m.Symbol
+	var mirror *orderbook.Book
+	var epoch int64
+	resync := func() orderbook.Snapshot {
+		epoch = m.Book.Epoch()
+		s := m.Book.Snapshot(0)
+		mirror = orderbook.FromSnapshot(s)
+		return s
+	}
+	resync()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer unsubscribe()
+		var last TopOfBook
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case u, ok := <-ch:
+				if !ok {
+					return
+				}
+				current := m.Book.Epoch() == epoch
+				switch {
+				case current && mirror.Update(&u):
+					h.broadcast(HubEvent{
+						Topic:  topic,
+						Type:   "delta",
+						Symbol: m.Symbol,
+						Time:   u.EventTime,
+						Data: BookDelta{
+							FirstUpdateId: u.FirstUpdateID,
+							FinalUpdateId: u.FinalUpdateID,
+							Bids:          toLevels(u.Bids),
+							Asks:          toLevels(u.Asks),
+						},
+					})
+				case !current || u.FinalUpdateID > mirror.LastID():
+					s := resync()
+					h.broadcast(HubEvent{Topic: topic, Type: "snapshot", Symbol: m.Symbol, Time: s.Time, Data: s})
+				default:
+					// Already in the last snapshot sent.
+					continue
+				}
+				if !top {
+					continue
+				}
+				bid, ask, ok := mirror.TopLevels()
+				if !ok {
+					continue
+				}
+				t := TopOfBook{BidPrice: bid.Price, BidQty: bid.Quantity, AskPrice: ask.Price, AskQty: ask.Quantity}
+				if t != last {
+					last = t
+					h.broadcast(HubEvent{Topic: topicTop + "." + m.Symbol, Type: "top", Symbol: m.Symbol, Time: u.EventTime, Data: t})
+				}
+			}
+		}
+	}()
+}
+
+func (h *Hub) forwardQuotes(ctx context.Context, wg *sync.WaitGroup, m *markets.Market) {
+	ch, unsubscribe := m.Quotes.Subscribe(1000)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case q, ok := <-ch:
+				if !ok {
+					return
+				}
+				var top TopOfBook
+				top.BidPrice, top.BidQty, top.AskPrice, top.AskQty = q.Floats()
+				h.broadcast(HubEvent{Topic: topicTop + "." + m.Symbol, Type: "top", Symbol: m.Symbol, Time: q.Time, Data: top})
+			}
+		}
+	}()
+}
+
+func (h *Hub) forwardTrades(ctx context.Context, wg *sync.WaitGroup, m *markets.Market) {
+	ch, unsubscribe := m.Trades.Subscribe(1000)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t, ok := <-ch:
+				if !ok {
+					return
+				}
+				h.broadcast(HubEvent{Topic: topicTrades + "." + m.Symbol, Type: "trade", Symbol: m.Symbol, Time: t.Time, Data: t})
+			}
+		}
+	}()
+}
+
+func (h *Hub) forwardKlines(ctx context.Context, wg *sync.WaitGroup, m *markets.Market) {
+	ch, unsubscribe := m.Klines.Subscribe(100)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case k, ok := <-ch:
+				if !ok {
+					return
+				}
+				h.broadcast(HubEvent{Topic: topicKlines + "." + m.Symbol, Type: "kline", Symbol: m.Symbol, Time: k.Kline.OpenTime, Data: k})
+			}
+		}
+	}()
+}
+
+func toLevels(raw [][]string) []orderbook.Level {
+	levels := make([]orderbook.Level, 0, len(raw))
+	for _, l := range raw {
+		if len(l) < 2 {
+			continue
+		}
+		levels = append(levels, orderbook.Level{Price: l[0], Quantity: l[1]})
+	}
+	return levels
+}
+
+func (h *Hub) broadcast(e HubEvent) {
+	msg, err := json.Marshal(e)
+	if err != nil {
+		slog.Error("hub marshal", "err", err)
+		return
+	}
+	h.Lock()
+	defer h.Unlock()
+	for c := range h.clients {
+		if c.topics[e.Topic] {
+			h.enqueue(c, msg)
+		}
+	}
+}
+
+// enqueue must be called with the hub locked.
+func (h *Hub) enqueue(c *hubClient, msg []byte) {
+	select {
+	case c.send <- msg:
+	default:
+		delete(h.clients, c)
+		go c.kick("slow consumer")
+	}
+}
+
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		slog.Error("hub upgrade", "err", err)
+		return
+	}
+	c := &hubClient{
+		conn:   conn,
+		send:   make(chan []byte, clientBuffer),
+		topics: make(map[string]bool),
+		done:   make(chan struct{}),
+	}
+	h.Lock()
+	h.clients[c] = true
+	h.Unlock()
+
+	go h.writeLoop(c)
+	h.readLoop(c)
+}
+
+func (h *Hub) readLoop(c *hubClient) {
+	defer func() {
+		h.Lock()
+		delete(h.clients, c)
+		h.Unlock()
+		c.kick("bye")
+	}()
+	for {
+		var req hubRequest
+		if err := c.conn.ReadJSON(&req); err != nil {
+			return
+		}
+		switch req.Op {
+		case "subscribe":
+			h.subscribe(c, req.Topics)
+		case "unsubscribe":
+			h.Lock()
+			for _, t := range req.Topics {
+				delete(c.topics, normalizeTopic(t))
+			}
+			h.Unlock()
+		default:
+			h.reply(c, HubEvent{Type: "error", Data: "unknown op " + req.Op})
+		}
+	}
+}
+
+func normalizeTopic(topic string) string {
+	channel, symbol, _ := strings.Cut(topic, ".")
+	return strings.ToLower(channel) + "." + strings.ToUpper(symbol)
+}
+
+// subscribe adds the topics and sends a book snapshot for book topics. Both
+// happen under the hub lock, so no delta applied after the snapshot can be
+// missed; deltas already in the snapshot are skipped by the client.
+func (h *Hub) subscribe(c *hubClient, topics []string) {
+	h.Lock()
+	defer h.Unlock()
+	for _, t := range topics {
+		t = normalizeTopic(t)
+		channel, symbol, _ := strings.Cut(t, ".")
+		m := h.markets.Get(symbol)
+		if m == nil {
+			h.enqueueEvent(c, HubEvent{Topic: t, Type: "error", Data: "unknown symbol " + symbol})
+			continue
+		}
+		switch channel {
+		case topicBook:
+			if m.Book == nil {
+				h.enqueueEvent(c, HubEvent{Topic: t, Type: "error", Data: "no order book for " + symbol})
+				continue
+			}
+			c.topics[t] = true
+			s := m.Book.Snapshot(0)
+			h.enqueueEvent(c, HubEvent{Topic: t, Type: "snapshot", Symbol: m.Symbol, Time: s.Time, Data: s})
+		case topicTop, topicTrades, topicKlines:
+			c.topics[t] = true
+		default:
+			h.enqueueEvent(c, HubEvent{Topic: t, Type: "error", Data: "unknown channel " + channel})
+			continue
+		}
+		h.enqueueEvent(c, HubEvent{Topic: t, Type: "subscribed", Symbol: m.Symbol})
+	}
+}
+
+func (h *Hub) enqueueEvent(c *hubClient, e HubEvent) {
+	msg, err := json.Marshal(e)
+	if err != nil {
+		slog.Error("hub marshal", "err", err)
+		return
+	}
+	h.enqueue(c, msg)
+}
+
+func (h *Hub) reply(c *hubClient, e HubEvent) {
+	h.Lock()
+	defer h.Unlock()
+	h.enqueueEvent(c, e)
+}
+
+func (h *Hub) writeLoop(c *hubClient) {
+	ping := time.NewTicker(clientPingEvery)
+	defer ping.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(clientWriteWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				c.kick("write failed")
+				return
+			}
+		case <-ping.C:
+			c.conn.SetWriteDeadline(time.Now().Add(clientWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.kick("ping failed")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"test.bhft.com/markets"
+	"test.bhft.com/orderbook"
+)
+
+// TestHubBookResync checks a book client gets deltas that continue its
+// snapshot, and a new snapshot after the book was replaced.
+func TestHubBookResync(t *testing.T) {
+	book := orderbook.FromSnapshot(orderbook.Snapshot{
+		Symbol:       "BTCUSDT",
+		LastUpdateId: 10,
+		Bids:         []orderbook.Level{{Price: "100", Quantity: "1"}},
+		Asks:         []orderbook.Level{{Price: "101", Quantity: "1"}},
+	})
+	book.Update(&orderbook.Update{FirstUpdateID: 11, FinalUpdateID: 11})
+	registry := markets.NewRegistry()
+	registry.Add(&markets.Market{Symbol: "BTCUSDT", Book: book})
+	hub := NewHub(registry)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+	hub.Run(ctx, &wg)
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.WriteJSON(hubRequest{Op: "subscribe", Topics: []string{"book.BTCUSDT"}}); err != nil {
+		t.Fatal(err)
+	}
+	// next skips replies and returns the next book event.
+	next := func() (string, json.RawMessage) {
+		t.Helper()
+		for {
+			conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			var e struct {
+				Topic string          `json:"topic"`
+				Type  string          `json:"type"`
+				Data  json.RawMessage `json:"data"`
+			}
+			if err := conn.ReadJSON(&e); err != nil {
+				t.Fatal(err)
+			}
+			if e.Topic == "book.BTCUSDT" && (e.Type == "snapshot" || e.Type == "delta") {
+				return e.Type, e.Data
+			}
+		}
+	}
+
+	var s orderbook.Snapshot
+	typ, data := next()
+	if err := json.Unmarshal(data, &s); typ != "snapshot" || err != nil || s.LastUpdateId != 11 {
+		t.Fatalf("first event %s %s, want the snapshot at 11", typ, data)
+	}
+
+	book.Update(&orderbook.Update{FirstUpdateID: 12, FinalUpdateID: 12, Bids: [][]string{{"100", "2"}}})
+	var d BookDelta
+	typ, data = next()
+	if err := json.Unmarshal(data, &d); typ != "delta" || err != nil || d.FirstUpdateId != 12 {
+		t.Fatalf("got %s %s, want the delta at 12", typ, data)
+	}
+
+	// A resync loads a snapshot that publishes nothing, the next diff
+	// brings a new snapshot instead of a delta.
+	book.Reset(orderbook.Snapshot{
+		Symbol:       "BTCUSDT",
+		LastUpdateId: 3,
+		Bids:         []orderbook.Level{{Price: "99", Quantity: "4"}},
+		Asks:         []orderbook.Level{{Price: "101", Quantity: "1"}},
+	})
+	book.Update(&orderbook.Update{FirstUpdateID: 4, FinalUpdateID: 4, Asks: [][]string{{"102", "1"}}})
+	typ, data = next()
+	if err := json.Unmarshal(data, &s); typ != "snapshot" || err != nil || s.LastUpdateId != 4 || s.Bids[0].Price != "99" {
+		t.Fatalf("got %s %s, want a snapshot at 4 with the new bids", typ, data)
+	}
+
+	book.Update(&orderbook.Update{FirstUpdateID: 5, FinalUpdateID: 5})
+	typ, data = next()
+	if err := json.Unmarshal(data, &d); typ != "delta" || err != nil || d.FirstUpdateId != 5 {
+		t.Fatalf("got %s %s, want the delta at 5", typ, data)
+	}
+}
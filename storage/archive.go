@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"test.bhft.com/orderbook"
+	"test.bhft.com/telemetry"
+)
+
+// ErrNoSnapshot is returned by BookAt when no anchor snapshot precedes the
+// requested moment.
+var ErrNoSnapshot = errors.New("no anchor snapshot")
+
+// BookArchive buffers the applied depth diffs of a symbol and writes them
+// gzipped together with their update IDs, so BookAt can rebuild the book at
+// any moment from an anchor snapshot.
+type BookArchive struct {
+	sync.Mutex
+	db      *sql.DB
+	symbol  string
+	pending []orderbook.Update
+}
+
+func NewBookArchive(db *sql.DB, symbol string) *BookArchive {
+	return &BookArchive{
+		db:     db,
+		symbol: symbol,
+	}
+}
+
+// Add queues an applied diff for the next flush.
+func (a *BookArchive) Add(update orderbook.Update) {
+	a.Lock()
+	defer a.Unlock()
+	a.pending = append(a.pending, update)
+}
+
+// Flush writes the pending diffs in one transaction. On error they are kept
+// for the next flush.
+func (a *BookArchive) Flush() error {
+	a.Lock()
+	pending := a.pending
+	a.pending = nil
+	a.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+	start := time.Now()
+	err := insertOrderBookDiffs(a.db, a.symbol, pending)
+	telemetry.ObserveInsert("order_book_diffs", a.symbol, start, err)
+	if err != nil {
+		a.Lock()
+		a.pending = append(pending, a.pending...)
+		a.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Anchor stores a full depth snapshot of the book. Pending diffs are flushed
+// first so the archive never has a hole right before an anchor.
+func (a *BookArchive) Anchor(orderBook *orderbook.Book) error {
+	if err := a.Flush(); err != nil {
+		return err
+	}
+	start := time.Now()
+	_, err := InsertOrderBookSnapshot(a.db, orderBook.Snapshot(0), 0)
+	telemetry.ObserveInsert("order_book_snapshots", a.symbol, start, err)
+	return err
+}
+
+// Pending returns the number of diffs waiting for the next flush.
+func (a *BookArchive) Pending() int {
+	a.Lock()
+	defer a.Unlock()
+	return len(a.pending)
+}
+
+func insertOrderBookDiffs(db *sql.DB, symbol string, updates []orderbook.Update) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO order_book_diffs (symbol, event_time, first_update_id, final_update_id, data) VALUES ($1, $2, $3, $4, $5)")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, u := range updates {
+		data, err := compressUpdate(u)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := stmt.Exec(symbol, u.EventTime, u.FirstUpdateID, u.FinalUpdateID, data); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func compressUpdate(u orderbook.Update) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(u); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressUpdate(data []byte) (orderbook.Update, error) {
+	var u orderbook.Update
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return u, err
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return u, err
+	}
+	err = json.Unmarshal(raw, &u)
+	return u, err
+}
+
+// BookAt rebuilds the order book of symbol as it was at the given moment:
+// it loads the newest anchor snapshot taken before at and replays the
+// archived diffs that follow it up to at.
+func BookAt(db *sql.DB, symbol string, at time.Time) (*orderbook.Book, error) {
+	s := orderbook.Snapshot{Symbol: symbol}
+	var id int64
+	err := db.QueryRow("SELECT id, snapshot_time, last_update_id FROM order_book_snapshots WHERE symbol = $1 AND depth = 0 AND snapshot_time <= $2 ORDER BY snapshot_time DESC LIMIT 1",
+		symbol, at.UnixMilli()).Scan(&id, &s.Time, &s.LastUpdateId)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("book at %s: %w for %s", at, ErrNoSnapshot, symbol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := loadOrderBookLevels(db, id, &s); err != nil {
+		return nil, err
+	}
+	orderBook := orderbook.FromSnapshot(s)
+
+	rows, err := db.Query("SELECT final_update_id, data FROM order_book_diffs WHERE symbol = $1 AND final_update_id > $2 AND event_time <= $3 ORDER BY final_update_id",
+		symbol, s.LastUpdateId, at.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var finalUpdateID int64
+		var data []byte
+		if err := rows.Scan(&finalUpdateID, &data); err != nil {
+			return nil, err
+		}
+		u, err := decompressUpdate(data)
+		if err != nil {
+			return nil, fmt.Errorf("book at %s: diff %d: %w", at, finalUpdateID, err)
+		}
+		if !orderBook.Update(&u) {
+			return nil, fmt.Errorf("book at %s: gap in archive before diff %d, book is at %d", at, finalUpdateID, orderBook.LastUpdateId)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return orderBook, nil
+}
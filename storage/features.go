@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"database/sql"
+
+	"test.bhft.com/features"
+)
+
+// InsertFeatures stores one window of features.
+func InsertFeatures(db *sql.DB, f features.Features) error {
+	_, err := db.Exec("INSERT INTO features (symbol, time, mid, microprice, quoted_spread_bps, effective_spread_bps, ofi, trade_imbalance, realized_vol, trade_intensity, trades) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		f.Symbol, f.Time, f.Mid, f.Microprice, f.QuotedSpreadBps, f.EffectiveSpreadBps, f.OFI, f.TradeImbalance, f.RealizedVol, f.TradeIntensity, f.Trades)
+	return err
+}
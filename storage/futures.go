@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"database/sql"
+
+	"test.bhft.com/futures"
+)
+
+// InsertMarkPrices stores mark price updates in one transaction, updates
+// already stored are skipped.
+func InsertMarkPrices(db *sql.DB, list []futures.MarkPrice) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO mark_prices (symbol, time, mark_price, index_price, estimated_settle_price, funding_rate, next_funding_time) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, m := range list {
+		if _, err := stmt.Exec(m.Symbol, m.Time, m.MarkPrice, m.IndexPrice, m.EstimatedSettlePrice, m.FundingRate, m.NextFundingTime); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// InsertOpenInterest stores one open interest reading.
+func InsertOpenInterest(db *sql.DB, oi futures.OpenInterest) error {
+	_, err := db.Exec("INSERT INTO open_interest (symbol, time, open_interest) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		oi.Symbol, oi.Time, oi.OpenInterest)
+	return err
+}
+
+// InsertLiquidation stores one liquidation order.
+func InsertLiquidation(db *sql.DB, l futures.Liquidation) error {
+	_, err := db.Exec("INSERT INTO liquidations (symbol, time, side, order_type, time_in_force, quantity, price, average_price, status, filled_quantity) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)",
+		l.Symbol, l.Time, l.Side, l.OrderType, l.TimeInForce, l.Quantity, l.Price, l.AveragePrice, l.Status, l.FilledQuantity)
+	return err
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"database/sql"
+
+	"test.bhft.com/indicators"
+)
+
+// UpsertIndicators stores the closed indicator points of symbol and
+// interval, one row per field, in one transaction. Points stored already
+// are replaced.
+func UpsertIndicators(db *sql.DB, symbol, interval string, points []indicators.Point) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO indicator_values (symbol, kline_interval, open_time, indicator, field, value)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (symbol, kline_interval, indicator, field, open_time) DO UPDATE SET value = EXCLUDED.value`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, p := range points {
+		for field, v := range p.Value {
+			if _, err := stmt.Exec(symbol, interval, p.OpenTime, p.Indicator, field, v); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
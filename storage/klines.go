@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"test.bhft.com/klines"
+)
+
+// QueryKlines reads up to limit klines of symbol and interval opened between
+// start and end inclusive, in milliseconds.
+func QueryKlines(ctx context.Context, db *sql.DB, symbol, interval string, start, end int64, limit int) ([]klines.Kline, error) {
+	rows, err := db.QueryContext(ctx, "SELECT open_time, close_time, open, high, low, close, volume FROM klines WHERE symbol = $1 AND kline_interval = $2 AND open_time >= $3 AND open_time <= $4 ORDER BY open_time LIMIT $5",
+		symbol, interval, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []klines.Kline
+	for rows.Next() {
+		var k klines.Kline
+		var open, high, low, close, volume float64
+		if err := rows.Scan(&k.OpenTime, &k.CloseTime, &open, &high, &low, &close, &volume); err != nil {
+			return nil, err
+		}
+		k.Open = strconv.FormatFloat(open, 'f', -1, 64)
+		k.High = strconv.FormatFloat(high, 'f', -1, 64)
+		k.Low = strconv.FormatFloat(low, 'f', -1, 64)
+		k.Close = strconv.FormatFloat(close, 'f', -1, 64)
+		k.Volume = strconv.FormatFloat(volume, 'f', -1, 64)
+		res = append(res, k)
+	}
+	return res, rows.Err()
+}
+
+// InsertKlines stores klines of symbol and interval in one transaction.
+// A kline already stored with the same open time is replaced, so a candle
+// stored while still open ends with its final values, and a backfill can
+// overlap what the live collector wrote.
+func InsertKlines(db *sql.DB, symbol, interval string, klines []klines.Kline) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO klines (symbol, kline_interval, open_time, close_time, open, high, low, close, volume) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (symbol, kline_interval, open_time) DO UPDATE SET close_time = EXCLUDED.close_time, open = EXCLUDED.open, high = EXCLUDED.high, low = EXCLUDED.low, close = EXCLUDED.close, volume = EXCLUDED.volume`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, k := range klines {
+		open, err := strconv.ParseFloat(k.Open, 64)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		high, err := strconv.ParseFloat(k.High, 64)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		low, err := strconv.ParseFloat(k.Low, 64)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		close, err := strconv.ParseFloat(k.Close, 64)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		volume, err := strconv.ParseFloat(k.Volume, 64)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := stmt.Exec(symbol, interval, k.OpenTime, k.CloseTime, open, high, low, close, volume); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"test.bhft.com/orderbook"
+)
+
+const (
+	sideBid = "bid"
+	sideAsk = "ask"
+)
+
+// InsertOrderBookSnapshot stores s with its levels and returns its id.
+// Depth 0 marks a full depth snapshot.
+func InsertOrderBookSnapshot(db *sql.DB, s orderbook.Snapshot, depth int) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = tx.QueryRow("INSERT INTO order_book_snapshots (symbol, snapshot_time, last_update_id, depth) VALUES ($1, $2, $3, $4) RETURNING id",
+		s.Symbol, s.Time, s.LastUpdateId, depth).Scan(&id)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO order_book_levels (snapshot_id, side, level, price, quantity) VALUES ($1, $2, $3, $4, $5)")
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for side, levels := range map[string][]orderbook.Level{sideBid: s.Bids, sideAsk: s.Asks} {
+		for i, l := range levels {
+			if _, err := stmt.Exec(id, side, i, l.Price, l.Quantity); err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// LoadLatestOrderBookSnapshot returns the newest full depth snapshot of
+// symbol taken at or after since, or nil when there is none.
+func LoadLatestOrderBookSnapshot(db *sql.DB, symbol string, since time.Time) (*orderbook.Snapshot, error) {
+	s := orderbook.Snapshot{Symbol: symbol}
+	var id int64
+	err := db.QueryRow("SELECT id, snapshot_time, last_update_id FROM order_book_snapshots WHERE symbol = $1 AND depth = 0 AND snapshot_time >= $2 ORDER BY snapshot_time DESC LIMIT 1",
+		symbol, since.UnixMilli()).Scan(&id, &s.Time, &s.LastUpdateId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := loadOrderBookLevels(db, id, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// QueryOrderBookSnapshots reads up to limit snapshots of symbol of any
+// depth taken between start and end inclusive, in milliseconds, with their
+// levels.
+func QueryOrderBookSnapshots(ctx context.Context, db *sql.DB, symbol string, start, end int64, limit int) ([]orderbook.Snapshot, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id, snapshot_time, last_update_id FROM order_book_snapshots WHERE symbol = $1 AND snapshot_time >= $2 AND snapshot_time <= $3 ORDER BY snapshot_time LIMIT $4",
+		symbol, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	var res []orderbook.Snapshot
+	for rows.Next() {
+		s := orderbook.Snapshot{Symbol: symbol}
+		var id int64
+		if err := rows.Scan(&id, &s.Time, &s.LastUpdateId); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+		res = append(res, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, id := range ids {
+		if err := loadOrderBookLevels(db, id, &res[i]); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func loadOrderBookLevels(db *sql.DB, id int64, s *orderbook.Snapshot) error {
+	rows, err := db.Query("SELECT side, price, quantity FROM order_book_levels WHERE snapshot_id = $1 ORDER BY side, level", id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var side string
+		var l orderbook.Level
+		if err := rows.Scan(&side, &l.Price, &l.Quantity); err != nil {
+			return err
+		}
+		if side == sideBid {
+			s.Bids = append(s.Bids, l)
+		} else {
+			s.Asks = append(s.Asks, l)
+		}
+	}
+	return rows.Err()
+}
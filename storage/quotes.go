@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"database/sql"
+
+	"test.bhft.com/quotes"
+)
+
+// InsertQuotes stores best quotes in one transaction.
+func InsertQuotes(db *sql.DB, list []quotes.Quote) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO quotes (symbol, time, update_id, bid_price, bid_qty, ask_price, ask_qty) VALUES ($1, $2, $3, $4, $5, $6, $7)")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, q := range list {
+		if _, err := stmt.Exec(q.Symbol, q.Time, q.UpdateID, q.BidPrice, q.BidQty, q.AskPrice, q.AskQty); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
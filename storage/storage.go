@@ -0,0 +1,187 @@
+// Package storage persists klines, trades, order book snapshots, archived
+// depth diffs, features, quotes and futures data in Postgres.
+package storage
+
+import (
+	"database/sql"
+
+	_ "github.com/lib/pq"
+)
+
+// Open connects to Postgres and checks the connection.
+func Open(psqlInfo string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", psqlInfo)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate creates the tables and indexes the collector writes to. It is
+// safe to run on every start. db/migrations holds the same schema as
+// numbered up and down migrations, a change here needs one there too.
+func Migrate(db *sql.DB) error {
+	migration := `
+	CREATE TABLE IF NOT EXISTS klines (
+		open_time BIGINT NOT NULL,
+		close_time BIGINT NOT NULL,
+		open DOUBLE PRECISION NOT NULL,
+		high DOUBLE PRECISION NOT NULL,
+		low DOUBLE PRECISION NOT NULL,
+		close DOUBLE PRECISION NOT NULL,
+		volume DOUBLE PRECISION NOT NULL
+	);
+	ALTER TABLE klines ADD COLUMN IF NOT EXISTS symbol TEXT NOT NULL DEFAULT 'BTCUSDT';
+	ALTER TABLE klines ADD COLUMN IF NOT EXISTS kline_interval TEXT NOT NULL DEFAULT '1d';
+	DO $$
+	BEGIN
+		IF to_regclass('klines_symbol_interval_open_time_key') IS NULL THEN
+			DELETE FROM klines a USING klines b
+				WHERE a.symbol = b.symbol AND a.kline_interval = b.kline_interval AND a.open_time = b.open_time AND a.ctid < b.ctid;
+			CREATE UNIQUE INDEX klines_symbol_interval_open_time_key ON klines (symbol, kline_interval, open_time);
+			DROP INDEX IF EXISTS klines_symbol_interval_time_idx;
+		END IF;
+	END $$;
+
+	CREATE TABLE IF NOT EXISTS order_book_snapshots (
+		id BIGSERIAL PRIMARY KEY,
+		symbol TEXT NOT NULL,
+		snapshot_time BIGINT NOT NULL,
+		last_update_id BIGINT NOT NULL,
+		depth INT NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS order_book_snapshots_symbol_time_idx ON order_book_snapshots (symbol, snapshot_time);
+
+	CREATE TABLE IF NOT EXISTS order_book_levels (
+		snapshot_id BIGINT NOT NULL REFERENCES order_book_snapshots (id) ON DELETE CASCADE,
+		side TEXT NOT NULL,
+		level INT NOT NULL,
+		price NUMERIC NOT NULL,
+		quantity NUMERIC NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS order_book_levels_snapshot_idx ON order_book_levels (snapshot_id);
+
+	CREATE TABLE IF NOT EXISTS order_book_diffs (
+		symbol TEXT NOT NULL,
+		event_time BIGINT NOT NULL,
+		first_update_id BIGINT NOT NULL,
+		final_update_id BIGINT NOT NULL,
+		data BYTEA NOT NULL,
+		PRIMARY KEY (symbol, final_update_id)
+	);
+	CREATE INDEX IF NOT EXISTS order_book_diffs_symbol_time_idx ON order_book_diffs (symbol, event_time);
+
+	CREATE TABLE IF NOT EXISTS features (
+		symbol TEXT NOT NULL,
+		time BIGINT NOT NULL,
+		mid DOUBLE PRECISION NOT NULL,
+		microprice DOUBLE PRECISION NOT NULL,
+		quoted_spread_bps DOUBLE PRECISION NOT NULL,
+		effective_spread_bps DOUBLE PRECISION NOT NULL,
+		ofi DOUBLE PRECISION NOT NULL,
+		trade_imbalance DOUBLE PRECISION NOT NULL,
+		realized_vol DOUBLE PRECISION NOT NULL,
+		trade_intensity DOUBLE PRECISION NOT NULL,
+		trades INT NOT NULL,
+		PRIMARY KEY (symbol, time)
+	);
+
+	CREATE TABLE IF NOT EXISTS trades (
+		symbol TEXT NOT NULL,
+		id BIGINT NOT NULL,
+		price DOUBLE PRECISION NOT NULL,
+		quantity DOUBLE PRECISION NOT NULL,
+		quote_quantity DOUBLE PRECISION NOT NULL,
+		time BIGINT NOT NULL,
+		is_buyer_maker BOOLEAN NOT NULL,
+		PRIMARY KEY (symbol, id)
+	);
+	CREATE INDEX IF NOT EXISTS trades_symbol_time_idx ON trades (symbol, time);
+
+	CREATE TABLE IF NOT EXISTS mark_prices (
+		symbol TEXT NOT NULL,
+		time BIGINT NOT NULL,
+		mark_price NUMERIC NOT NULL,
+		index_price NUMERIC NOT NULL,
+		estimated_settle_price NUMERIC NOT NULL,
+		funding_rate NUMERIC NOT NULL,
+		next_funding_time BIGINT NOT NULL,
+		PRIMARY KEY (symbol, time)
+	);
+
+	CREATE TABLE IF NOT EXISTS open_interest (
+		symbol TEXT NOT NULL,
+		time BIGINT NOT NULL,
+		open_interest NUMERIC NOT NULL,
+		PRIMARY KEY (symbol, time)
+	);
+
+	CREATE TABLE IF NOT EXISTS liquidations (
+		symbol TEXT NOT NULL,
+		time BIGINT NOT NULL,
+		side TEXT NOT NULL,
+		order_type TEXT NOT NULL,
+		time_in_force TEXT NOT NULL,
+		quantity NUMERIC NOT NULL,
+		price NUMERIC NOT NULL,
+		average_price NUMERIC NOT NULL,
+		status TEXT NOT NULL,
+		filled_quantity NUMERIC NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS liquidations_symbol_time_idx ON liquidations (symbol, time);
+
+	CREATE TABLE IF NOT EXISTS quotes (
+		symbol TEXT NOT NULL,
+		time BIGINT NOT NULL,
+		update_id BIGINT NOT NULL,
+		bid_price NUMERIC NOT NULL,
+		bid_qty NUMERIC NOT NULL,
+		ask_price NUMERIC NOT NULL,
+		ask_qty NUMERIC NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS quotes_symbol_time_idx ON quotes (symbol, time);
+
+	CREATE TABLE IF NOT EXISTS trade_flow (
+		symbol TEXT NOT NULL,
+		flow_interval TEXT NOT NULL,
+		open_time BIGINT NOT NULL,
+		close_time BIGINT NOT NULL,
+		buy_volume DOUBLE PRECISION NOT NULL,
+		sell_volume DOUBLE PRECISION NOT NULL,
+		buy_quote_volume DOUBLE PRECISION NOT NULL,
+		sell_quote_volume DOUBLE PRECISION NOT NULL,
+		trades BIGINT NOT NULL,
+		PRIMARY KEY (symbol, flow_interval, open_time)
+	);
+
+	CREATE TABLE IF NOT EXISTS large_trades (
+		symbol TEXT NOT NULL,
+		time BIGINT NOT NULL,
+		kind TEXT NOT NULL,
+		side TEXT NOT NULL,
+		first_id BIGINT NOT NULL,
+		last_id BIGINT NOT NULL,
+		trades INT NOT NULL,
+		quantity DOUBLE PRECISION NOT NULL,
+		notional DOUBLE PRECISION NOT NULL,
+		price DOUBLE PRECISION NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS large_trades_symbol_time_idx ON large_trades (symbol, time);
+
+	CREATE TABLE IF NOT EXISTS indicator_values (
+		symbol TEXT NOT NULL,
+		kline_interval TEXT NOT NULL,
+		open_time BIGINT NOT NULL,
+		indicator TEXT NOT NULL,
+		field TEXT NOT NULL,
+		value DOUBLE PRECISION NOT NULL,
+		PRIMARY KEY (symbol, kline_interval, indicator, field, open_time)
+	);
+	`
+	_, err := db.Exec(migration)
+	return err
+}
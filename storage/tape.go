@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"database/sql"
+
+	"test.bhft.com/tape"
+)
+
+// UpsertTradeFlow stores the flow buckets of symbol, replacing the buckets
+// already stored, in one transaction.
+func UpsertTradeFlow(db *sql.DB, symbol string, list []tape.Bucket) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO trade_flow (symbol, flow_interval, open_time, close_time, buy_volume, sell_volume, buy_quote_volume, sell_quote_volume, trades)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (symbol, flow_interval, open_time) DO UPDATE SET
+		close_time = EXCLUDED.close_time, buy_volume = EXCLUDED.buy_volume, sell_volume = EXCLUDED.sell_volume,
+		buy_quote_volume = EXCLUDED.buy_quote_volume, sell_quote_volume = EXCLUDED.sell_quote_volume, trades = EXCLUDED.trades`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, b := range list {
+		if _, err := stmt.Exec(symbol, b.Interval, b.OpenTime, b.CloseTime, b.BuyVolume, b.SellVolume, b.BuyQuoteVolume, b.SellQuoteVolume, b.Trades); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// InsertLargeTrade stores one large trade event.
+func InsertLargeTrade(db *sql.DB, e tape.Event) error {
+	_, err := db.Exec("INSERT INTO large_trades (symbol, time, kind, side, first_id, last_id, trades, quantity, notional, price) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)",
+		e.Symbol, e.Time, e.Kind, e.Side, e.FirstID, e.LastID, e.Trades, e.Quantity, e.Notional, e.Price)
+	return err
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"test.bhft.com/trades"
+)
+
+// QueryTrades reads up to limit trades of symbol with an ID of at least
+// fromID, made between start and end inclusive, in milliseconds.
+func QueryTrades(ctx context.Context, db *sql.DB, symbol string, fromID, start, end int64, limit int) ([]trades.Trade, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id, price, quantity, quote_quantity, time, is_buyer_maker FROM trades WHERE symbol = $1 AND id >= $2 AND time >= $3 AND time <= $4 ORDER BY id LIMIT $5",
+		symbol, fromID, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []trades.Trade
+	for rows.Next() {
+		var t trades.Trade
+		var price, qty, quoteQty float64
+		if err := rows.Scan(&t.ID, &price, &qty, &quoteQty, &t.Time, &t.IsBuyerMaker); err != nil {
+			return nil, err
+		}
+		t.Price = strconv.FormatFloat(price, 'f', -1, 64)
+		t.Quantity = strconv.FormatFloat(qty, 'f', -1, 64)
+		t.QuoteQuantity = strconv.FormatFloat(quoteQty, 'f', -1, 64)
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+// InsertTrades stores trades of symbol in one transaction, trades already
+// stored are skipped.
+func InsertTrades(db *sql.DB, symbol string, list []trades.Trade) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO trades (symbol, id, price, quantity, quote_quantity, time, is_buyer_maker) VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT DO NOTHING")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, t := range list {
+		price, err := strconv.ParseFloat(t.Price, 64)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		qty, err := strconv.ParseFloat(t.Quantity, 64)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		quoteQty, err := strconv.ParseFloat(t.QuoteQuantity, 64)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := stmt.Exec(symbol, t.ID, price, qty, quoteQty, t.Time, t.IsBuyerMaker); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,22 @@
+package tape
+
+// CVD is the cumulative volume delta: the buy initiated volume minus the
+// sell initiated volume since the analyzer started. Time is the time of the
+// last trade in milliseconds.
+type CVD struct {
+	Value      float64 `json:"value"`
+	BuyVolume  float64 `json:"buyVolume"`
+	SellVolume float64 `json:"sellVolume"`
+	Time       int64   `json:"time"`
+}
+
+func (c *CVD) add(t trade) {
+	if t.side == SideBuy {
+		c.BuyVolume += t.qty
+		c.Value += t.qty
+	} else {
+		c.SellVolume += t.qty
+		c.Value -= t.qty
+	}
+	c.Time = t.time
+}
@@ -0,0 +1,64 @@
+package tape
+
+import "time"
+
+// Bucket is the buy and sell initiated volume of one interval, opened at
+// OpenTime like the kline of the same interval. Times are in milliseconds.
+type Bucket struct {
+	Interval        string  `json:"interval"`
+	OpenTime        int64   `json:"openTime"`
+	CloseTime       int64   `json:"closeTime"`
+	BuyVolume       float64 `json:"buyVolume"`
+	SellVolume      float64 `json:"sellVolume"`
+	BuyQuoteVolume  float64 `json:"buyQuoteVolume"`
+	SellQuoteVolume float64 `json:"sellQuoteVolume"`
+	Trades          int64   `json:"trades"`
+}
+
+// Delta is the buy minus the sell volume of the bucket.
+func (b Bucket) Delta() float64 {
+	return b.BuyVolume - b.SellVolume
+}
+
+// Flow splits the trades into buckets of one interval and keeps the last
+// history of them, the running one included.
+type Flow struct {
+	interval string
+	length   int64
+	history  int
+	buckets  []Bucket
+}
+
+func NewFlow(interval string, length time.Duration, history int) *Flow {
+	return &Flow{interval: interval, length: length.Milliseconds(), history: max(history, 1)}
+}
+
+// add adds t to its bucket and returns the buckets it closed.
+func (f *Flow) add(t trade) []Bucket {
+	open := t.time - t.time%f.length
+	var closed []Bucket
+	if n := len(f.buckets); n == 0 || f.buckets[n-1].OpenTime < open {
+		if n > 0 {
+			closed = append(closed, f.buckets[n-1])
+		}
+		f.buckets = append(f.buckets, Bucket{Interval: f.interval, OpenTime: open, CloseTime: open + f.length - 1})
+		if len(f.buckets) > f.history {
+			f.buckets = f.buckets[len(f.buckets)-f.history:]
+		}
+	}
+	b := &f.buckets[len(f.buckets)-1]
+	if t.side == SideBuy {
+		b.BuyVolume += t.qty
+		b.BuyQuoteVolume += t.price * t.qty
+	} else {
+		b.SellVolume += t.qty
+		b.SellQuoteVolume += t.price * t.qty
+	}
+	b.Trades++
+	return closed
+}
+
+// Buckets copies the buckets kept, oldest first.
+func (f *Flow) Buckets() []Bucket {
+	return append([]Bucket{}, f.buckets...)
+}
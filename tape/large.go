@@ -0,0 +1,91 @@
+package tape
+
+import "time"
+
+const (
+	// EventPrint is a single trade above the thresholds.
+	EventPrint = "print"
+	// EventBurst is a run of trades on one side within BurstWindow above the
+	// burst thresholds.
+	EventBurst = "burst"
+)
+
+// LargeConfig sets the thresholds of the large trade detector, in base
+// asset quantity and quote notional. A zero threshold is disabled, a zero
+// BurstWindow disables bursts.
+type LargeConfig struct {
+	MinQty        float64
+	MinNotional   float64
+	BurstWindow   time.Duration
+	BurstQty      float64
+	BurstNotional float64
+}
+
+// Event is a large trade or burst. Price is the volume weighted price of
+// its trades, times are in milliseconds.
+type Event struct {
+	Symbol   string  `json:"symbol"`
+	Kind     string  `json:"kind"`
+	Side     string  `json:"side"`
+	Time     int64   `json:"time"`
+	FirstID  int64   `json:"firstId"`
+	LastID   int64   `json:"lastId"`
+	Trades   int     `json:"trades"`
+	Quantity float64 `json:"qty"`
+	Notional float64 `json:"notional"`
+	Price    float64 `json:"price"`
+}
+
+// Detector raises events on large prints and bursts. Once a burst is raised
+// its trades are forgotten, so the same burst is not raised on every trade
+// that follows.
+type Detector struct {
+	symbol string
+	cfg    LargeConfig
+	runs   map[string][]trade
+}
+
+func NewDetector(symbol string, cfg LargeConfig) *Detector {
+	return &Detector{symbol: symbol, cfg: cfg, runs: make(map[string][]trade)}
+}
+
+func above(qty, notional, minQty, minNotional float64) bool {
+	return (minQty > 0 && qty >= minQty) || (minNotional > 0 && notional >= minNotional)
+}
+
+func (d *Detector) event(kind string, run []trade) Event {
+	first, last := run[0], run[len(run)-1]
+	e := Event{Symbol: d.symbol, Kind: kind, Side: last.side, Time: last.time, FirstID: first.id, LastID: last.id, Trades: len(run)}
+	for _, t := range run {
+		e.Quantity += t.qty
+		e.Notional += t.price * t.qty
+	}
+	if e.Quantity > 0 {
+		e.Price = e.Notional / e.Quantity
+	}
+	return e
+}
+
+func (d *Detector) add(t trade) []Event {
+	var events []Event
+	if above(t.qty, t.price*t.qty, d.cfg.MinQty, d.cfg.MinNotional) {
+		events = append(events, d.event(EventPrint, []trade{t}))
+	}
+	if d.cfg.BurstWindow <= 0 {
+		return events
+	}
+	run := d.runs[t.side]
+	cutoff := t.time - d.cfg.BurstWindow.Milliseconds()
+	n := 0
+	for n < len(run) && run[n].time < cutoff {
+		n++
+	}
+	run = append(run[n:], t)
+	e := d.event(EventBurst, run)
+	if len(run) > 1 && above(e.Quantity, e.Notional, d.cfg.BurstQty, d.cfg.BurstNotional) {
+		events = append(events, e)
+		run = nil
+	}
+	d.runs[t.side] = run
+	return events
+}
@@ -0,0 +1,118 @@
+package tape
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// valueAreaShare is the share of the session volume the value area holds.
+var valueAreaShare = 0.7
+
+// Level is the volume traded at one price of a profile.
+type Level struct {
+	Price      float64 `json:"price"`
+	BuyVolume  float64 `json:"buyVolume"`
+	SellVolume float64 `json:"sellVolume"`
+}
+
+func (l Level) Volume() float64 {
+	return l.BuyVolume + l.SellVolume
+}
+
+// Profile is the volume at price of one session, from Start to End
+// excluded, in milliseconds. Prices are rounded down to a multiple of Tick
+// when it is set.
+type Profile struct {
+	Start  int64
+	End    int64
+	Tick   float64
+	levels map[float64]*Level
+}
+
+// NewProfile returns the profile of the session of length session holding
+// at. Sessions are aligned on the unix epoch, so daily sessions start at
+// UTC midnight.
+func NewProfile(at int64, session time.Duration, tick float64) *Profile {
+	length := session.Milliseconds()
+	start := at - at%length
+	return &Profile{Start: start, End: start + length, Tick: tick, levels: make(map[float64]*Level)}
+}
+
+func (p *Profile) add(t trade) {
+	price := t.price
+	if p.Tick > 0 {
+		price = math.Floor(price/p.Tick) * p.Tick
+	}
+	l, ok := p.levels[price]
+	if !ok {
+		l = &Level{Price: price}
+		p.levels[price] = l
+	}
+	if t.side == SideBuy {
+		l.BuyVolume += t.qty
+	} else {
+		l.SellVolume += t.qty
+	}
+}
+
+// Levels returns the levels sorted by price.
+func (p *Profile) Levels() []Level {
+	res := make([]Level, 0, len(p.levels))
+	for _, l := range p.levels {
+		res = append(res, *l)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Price < res[j].Price })
+	return res
+}
+
+// ProfileState is a copy of a profile with its point of control, the price
+// with the most volume, and its value area, the prices around the point of
+// control holding 70% of the volume.
+type ProfileState struct {
+	Start         int64   `json:"start"`
+	End           int64   `json:"end"`
+	POC           float64 `json:"poc"`
+	ValueAreaLow  float64 `json:"valueAreaLow"`
+	ValueAreaHigh float64 `json:"valueAreaHigh"`
+	Volume        float64 `json:"volume"`
+	Levels        []Level `json:"levels"`
+}
+
+func (p *Profile) State() ProfileState {
+	s := ProfileState{Start: p.Start, End: p.End, Levels: p.Levels()}
+	if len(s.Levels) == 0 {
+		return s
+	}
+	poc := 0
+	for i, l := range s.Levels {
+		s.Volume += l.Volume()
+		if l.Volume() > s.Levels[poc].Volume() {
+			poc = i
+		}
+	}
+	// The value area grows from the point of control towards the side with
+	// the larger next level until it holds its share of the volume.
+	low, high := poc, poc
+	volume := s.Levels[poc].Volume()
+	for volume < s.Volume*valueAreaShare {
+		below, above := -1.0, -1.0
+		if low > 0 {
+			below = s.Levels[low-1].Volume()
+		}
+		if high < len(s.Levels)-1 {
+			above = s.Levels[high+1].Volume()
+		}
+		if above > below {
+			high++
+			volume += above
+		} else {
+			low--
+			volume += below
+		}
+	}
+	s.POC = s.Levels[poc].Price
+	s.ValueAreaLow = s.Levels[low].Price
+	s.ValueAreaHigh = s.Levels[high].Price
+	return s
+}
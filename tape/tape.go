@@ -0,0 +1,175 @@
+// Package tape computes analytics over the trade stream of a symbol:
+// cumulative volume delta, volume at price per session, buy and sell volume
+// per interval and large trade detection. Trades are classified by their
+// aggressor, a trade whose buyer was the maker was sell initiated.
+package tape
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"test.bhft.com/feed"
+	"test.bhft.com/trades"
+)
+
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+// recentEvents is how many events State returns.
+var recentEvents = 50
+
+// Config sets up an Analyzer. Interval is the flow bucket, written like a
+// kline interval, FlowHistory the number of buckets kept. Session is the
+// length of a volume profile session, aligned on UTC midnight for a day,
+// and Tick the price bucket of the profile, 0 keeps the trade prices.
+type Config struct {
+	Interval    string
+	FlowHistory int
+	Session     time.Duration
+	Tick        float64
+	Large       LargeConfig
+}
+
+// ParseInterval returns the length of a kline interval like 1m, 4h or 1d.
+// Weeks and months are not fixed length and not accepted.
+func ParseInterval(interval string) (time.Duration, error) {
+	if n, ok := strings.CutSuffix(interval, "d"); ok {
+		days, err := strconv.Atoi(n)
+		if err != nil || days <= 0 {
+			return 0, fmt.Errorf("invalid interval %q", interval)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid interval %q", interval)
+	}
+	return d, nil
+}
+
+// trade is a parsed trade.
+type trade struct {
+	id    int64
+	time  int64
+	price float64
+	qty   float64
+	side  string
+}
+
+func parse(t trades.Trade) (trade, bool) {
+	price, err := strconv.ParseFloat(t.Price, 64)
+	if err != nil {
+		return trade{}, false
+	}
+	qty, err := strconv.ParseFloat(t.Quantity, 64)
+	if err != nil {
+		return trade{}, false
+	}
+	side := SideBuy
+	if t.IsBuyerMaker {
+		side = SideSell
+	}
+	return trade{id: t.ID, time: t.Time, price: price, qty: qty, side: side}, true
+}
+
+// Analyzer runs every analytics of one symbol over its trades.
+type Analyzer struct {
+	sync.Mutex
+	Symbol   string
+	cfg      Config
+	cvd      CVD
+	flow     *Flow
+	profile  *Profile
+	previous *Profile
+	detector *Detector
+	events   []Event
+	feed     feed.Feed[Event]
+}
+
+func NewAnalyzer(symbol string, cfg Config) (*Analyzer, error) {
+	interval, err := ParseInterval(cfg.Interval)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Session <= 0 {
+		return nil, fmt.Errorf("session must be positive")
+	}
+	return &Analyzer{
+		Symbol:   symbol,
+		cfg:      cfg,
+		flow:     NewFlow(cfg.Interval, interval, cfg.FlowHistory),
+		detector: NewDetector(symbol, cfg.Large),
+	}, nil
+}
+
+// Add runs t through every analytics. It returns the flow buckets t closed
+// and the large trade events it raised, which are also published to the
+// subscribers.
+func (a *Analyzer) Add(t trades.Trade) ([]Bucket, []Event) {
+	p, ok := parse(t)
+	if !ok {
+		return nil, nil
+	}
+	a.Lock()
+	defer a.Unlock()
+	a.cvd.add(p)
+	closed := a.flow.add(p)
+	if a.profile == nil || p.time >= a.profile.End {
+		if a.profile != nil {
+			a.previous = a.profile
+		}
+		a.profile = NewProfile(p.time, a.cfg.Session, a.cfg.Tick)
+	}
+	a.profile.add(p)
+	events := a.detector.add(p)
+	for _, e := range events {
+		a.events = append(a.events, e)
+		a.feed.Publish(e)
+	}
+	if len(a.events) > recentEvents {
+		a.events = a.events[len(a.events)-recentEvents:]
+	}
+	return closed, events
+}
+
+// Subscribe streams every large trade event raised after the call.
+func (a *Analyzer) Subscribe(buf int) (<-chan Event, func()) {
+	return a.feed.Subscribe(buf)
+}
+
+// State is the current state of the analytics of a symbol. Profile is the
+// profile of the running session, Previous the one of the last session.
+type State struct {
+	Symbol   string        `json:"symbol"`
+	CVD      CVD           `json:"cvd"`
+	Flow     []Bucket      `json:"flow"`
+	Profile  *ProfileState `json:"profile,omitempty"`
+	Previous *ProfileState `json:"previousProfile,omitempty"`
+	Events   []Event       `json:"events"`
+}
+
+// State copies the current state.
+func (a *Analyzer) State() State {
+	a.Lock()
+	defer a.Unlock()
+	s := State{
+		Symbol: a.Symbol,
+		CVD:    a.cvd,
+		Flow:   a.flow.Buckets(),
+		Events: append([]Event{}, a.events...),
+	}
+	if a.profile != nil {
+		p := a.profile.State()
+		s.Profile = &p
+	}
+	if a.previous != nil {
+		p := a.previous.State()
+		s.Previous = &p
+	}
+	return s
+}
@@ -0,0 +1,126 @@
+package tape
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValueArea(t *testing.T) {
+	tests := []struct {
+		name           string
+		volumes        []float64
+		poc, low, high float64
+	}{
+		{name: "grows towards the larger side", volumes: []float64{1, 2, 10, 3, 1}, poc: 102, low: 102, high: 103},
+		{name: "grows down on a tie", volumes: []float64{5, 10, 5}, poc: 101, low: 100, high: 101},
+		{name: "point of control alone", volumes: []float64{10, 1, 1}, poc: 100, low: 100, high: 100},
+		{name: "point of control on top", volumes: []float64{3, 2, 10}, poc: 102, low: 101, high: 102},
+		{name: "takes both sides", volumes: []float64{2, 3, 4, 3, 2}, poc: 102, low: 101, high: 103},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProfile(0, time.Hour, 0)
+			for i, v := range tt.volumes {
+				p.add(trade{price: 100 + float64(i), qty: v, side: SideBuy})
+			}
+			s := p.State()
+			if s.POC != tt.poc || s.ValueAreaLow != tt.low || s.ValueAreaHigh != tt.high {
+				t.Errorf("poc %v value area %v-%v, want %v %v-%v", s.POC, s.ValueAreaLow, s.ValueAreaHigh, tt.poc, tt.low, tt.high)
+			}
+		})
+	}
+}
+
+func TestBurst(t *testing.T) {
+	cfg := LargeConfig{MinQty: 4, BurstWindow: time.Second, BurstQty: 3}
+	type want struct {
+		at      int // index of the trade raising the event
+		kind    string
+		trades  int
+		firstID int64
+	}
+	tests := []struct {
+		name   string
+		trades []trade
+		want   []want
+	}{
+		{
+			name:   "run within the window",
+			trades: []trade{{id: 1, time: 0, qty: 1}, {id: 2, time: 500, qty: 1}, {id: 3, time: 900, qty: 1}, {id: 4, time: 1000, qty: 1}},
+			want:   []want{{at: 2, kind: EventBurst, trades: 3, firstID: 1}},
+		},
+		{
+			name:   "older trades leave the window",
+			trades: []trade{{id: 1, time: 0, qty: 2}, {id: 2, time: 1500, qty: 2}, {id: 3, time: 1600, qty: 1}},
+			want:   []want{{at: 2, kind: EventBurst, trades: 2, firstID: 2}},
+		},
+		{
+			name:   "sides add up apart",
+			trades: []trade{{id: 1, time: 0, qty: 2}, {id: 2, time: 100, qty: 2, side: SideSell}, {id: 3, time: 200, qty: 1}},
+			want:   []want{{at: 2, kind: EventBurst, trades: 2, firstID: 1}},
+		},
+		{
+			name:   "a single print is no burst",
+			trades: []trade{{id: 1, time: 0, qty: 5}},
+			want:   []want{{at: 0, kind: EventPrint, trades: 1, firstID: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDetector("BTCUSDT", cfg)
+			var got []want
+			for i, tr := range tt.trades {
+				tr.price = 100
+				if tr.side == "" {
+					tr.side = SideBuy
+				}
+				for _, e := range d.add(tr) {
+					got = append(got, want{at: i, kind: e.Kind, trades: e.Trades, firstID: e.FirstID})
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("events %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("event %d %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFlowRollOver(t *testing.T) {
+	f := NewFlow("1m", time.Minute, 2)
+	tests := []struct {
+		trade  trade
+		closed []int64 // open times of the buckets closed
+	}{
+		{trade: trade{time: 0, price: 10, qty: 1, side: SideBuy}},
+		{trade: trade{time: 59_999, price: 10, qty: 2, side: SideSell}},
+		{trade: trade{time: 60_000, price: 10, qty: 1, side: SideBuy}, closed: []int64{0}},
+		{trade: trade{time: 150_000, price: 10, qty: 1, side: SideBuy}, closed: []int64{60_000}},
+	}
+	var first Bucket
+	for i, tt := range tests {
+		closed := f.add(tt.trade)
+		if len(closed) != len(tt.closed) {
+			t.Fatalf("trade %d closed %+v, want buckets at %v", i, closed, tt.closed)
+		}
+		for j, b := range closed {
+			if b.OpenTime != tt.closed[j] {
+				t.Errorf("trade %d closed the bucket at %d, want %d", i, b.OpenTime, tt.closed[j])
+			}
+			if b.OpenTime == 0 {
+				first = b
+			}
+		}
+	}
+	if first.CloseTime != 59_999 || first.BuyVolume != 1 || first.SellVolume != 2 || first.SellQuoteVolume != 20 || first.Trades != 2 || first.Delta() != -1 {
+		t.Errorf("first bucket %+v", first)
+	}
+	buckets := f.Buckets()
+	if len(buckets) != 2 || buckets[0].OpenTime != 60_000 || buckets[1].OpenTime != 120_000 {
+		t.Errorf("buckets %+v, want the last 2 from 60000", buckets)
+	}
+}
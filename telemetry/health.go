@@ -0,0 +1,123 @@
+package telemetry
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+)
+
+// Tracker keeps the connection state and the last message time of every
+// stream by venue and symbol, and the last flush of every table by symbol,
+// for the status endpoints.
+type Tracker struct {
+	sync.Mutex
+	feeds   map[feedKey]*FeedState
+	flushes map[tableKey]*TableStatus
+}
+
+type feedKey struct {
+	venue, stream, symbol string
+}
+
+type tableKey struct {
+	table, symbol string
+}
+
+// FeedState is the connection state and last message time of a stream.
+type FeedState struct {
+	Connected   bool
+	LastMessage time.Time
+}
+
+// TableStatus is the last successful write of a symbol to a table in unix
+// ms and the error of the last write if it failed.
+type TableStatus struct {
+	Table     string `json:"table"`
+	LastFlush int64  `json:"lastFlush"`
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Health is the tracker fed by the stream readers and the storage.
+var Health = NewTracker()
+
+func NewTracker() *Tracker {
+	return &Tracker{
+		feeds:   make(map[feedKey]*FeedState),
+		flushes: make(map[tableKey]*TableStatus),
+	}
+}
+
+func (h *Tracker) feed(venue, stream, symbol string) *FeedState {
+	key := feedKey{venue, stream, symbol}
+	f, ok := h.feeds[key]
+	if !ok {
+		f = &FeedState{}
+		h.feeds[key] = f
+	}
+	return f
+}
+
+func (h *Tracker) Connected(venue, stream, symbol string) {
+	h.Lock()
+	defer h.Unlock()
+	h.feed(venue, stream, symbol).Connected = true
+}
+
+func (h *Tracker) Disconnected(venue, stream, symbol string) {
+	h.Lock()
+	defer h.Unlock()
+	h.feed(venue, stream, symbol).Connected = false
+}
+
+func (h *Tracker) Message(venue, stream, symbol string) {
+	h.Lock()
+	defer h.Unlock()
+	h.feed(venue, stream, symbol).LastMessage = clock.Now()
+}
+
+// Flush records a write of symbol to table.
+func (h *Tracker) Flush(table, symbol string, err error) {
+	h.Lock()
+	defer h.Unlock()
+	key := tableKey{table, symbol}
+	t, ok := h.flushes[key]
+	if !ok {
+		t = &TableStatus{Table: table}
+		h.flushes[key] = t
+	}
+	if err != nil {
+		t.LastError = err.Error()
+		return
+	}
+	t.LastFlush = clock.Now().UnixMilli()
+	t.LastError = ""
+}
+
+// Feed returns the state of a stream, zero when nothing was seen on it.
+func (h *Tracker) Feed(venue, stream, symbol string) FeedState {
+	h.Lock()
+	defer h.Unlock()
+	if f, ok := h.feeds[feedKey{venue, stream, symbol}]; ok {
+		return *f
+	}
+	return FeedState{}
+}
+
+// Tables returns the flush state of every table symbol was written to so
+// far, by name.
+func (h *Tracker) Tables(symbol string) []TableStatus {
+	h.Lock()
+	tables := []TableStatus{}
+	for key, t := range h.flushes {
+		if key.symbol == symbol {
+			tables = append(tables, *t)
+		}
+	}
+	h.Unlock()
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].Table < tables[j].Table
+	})
+	return tables
+}
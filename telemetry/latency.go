@@ -0,0 +1,90 @@
+package telemetry
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"test.bhft.com/clock"
+)
+
+// SeriesPoint is one value of a time series, Time is in milliseconds.
+type SeriesPoint struct {
+	Time  int64   `json:"time"`
+	Value float64 `json:"value"`
+}
+
+type latencyBucket struct {
+	second int64
+	sum    float64
+	count  int
+}
+
+// LatencyTracker keeps a bounded history of event latencies for each stream,
+// for example "book.BTCUSDT": the average delay in milliseconds between the
+// exchange event time and the local receive time over each second.
+type LatencyTracker struct {
+	sync.Mutex
+	keep    int
+	streams map[string][]latencyBucket
+}
+
+func NewLatencyTracker(keep int) *LatencyTracker {
+	return &LatencyTracker{
+		keep:    keep,
+		streams: make(map[string][]latencyBucket),
+	}
+}
+
+// Latencies keeps a day of per second latencies of every stream.
+var Latencies = NewLatencyTracker(24 * 3600)
+
+// Observe adds the latency of an event with the exchange eventTime in
+// milliseconds to stream.
+func (lt *LatencyTracker) Observe(stream string, eventTime int64) {
+	now := clock.Now()
+	ms := float64(now.UnixMilli() - eventTime)
+	second := now.Unix()
+
+	lt.Lock()
+	defer lt.Unlock()
+	buckets := lt.streams[stream]
+	if n := len(buckets); n > 0 && buckets[n-1].second == second {
+		buckets[n-1].sum += ms
+		buckets[n-1].count++
+		return
+	}
+	buckets = append(buckets, latencyBucket{second: second, sum: ms, count: 1})
+	if len(buckets) > lt.keep {
+		buckets = buckets[len(buckets)-lt.keep:]
+	}
+	lt.streams[stream] = buckets
+}
+
+// Range returns the points of stream between from and to inclusive.
+func (lt *LatencyTracker) Range(stream string, from, to time.Time) []SeriesPoint {
+	lt.Lock()
+	defer lt.Unlock()
+	buckets := lt.streams[stream]
+	start := sort.Search(len(buckets), func(i int) bool { return buckets[i].second >= from.Unix() })
+	res := make([]SeriesPoint, 0)
+	for _, b := range buckets[start:] {
+		if b.second > to.Unix() {
+			break
+		}
+		res = append(res, SeriesPoint{Time: b.second * 1000, Value: b.sum / float64(b.count)})
+	}
+	return res
+}
+
+// Streams lists the streams with latencies, sorted.
+func (lt *LatencyTracker) Streams() []string {
+	lt.Lock()
+	defer lt.Unlock()
+	streams := make([]string, 0, len(lt.streams))
+	for s := range lt.streams {
+		streams = append(streams, s)
+	}
+	sort.Strings(streams)
+	return streams
+}
@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+)
+
+// LogConfig selects the level and format of the logs. Every SampleEvery-th
+// raw event of each stream is logged at debug level, 0 logs none.
+type LogConfig struct {
+	Level       string
+	Format      string
+	SampleEvery int
+}
+
+// SetupLogging installs the default slog logger for cfg.
+func SetupLogging(cfg LogConfig) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return fmt.Errorf("log level: %w", err)
+	}
+	opts := &slog.HandlerOptions{Level: level}
+	var h slog.Handler
+	switch cfg.Format {
+	case "text":
+		h = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		h = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("log format: unknown format %q", cfg.Format)
+	}
+	slog.SetDefault(slog.New(h))
+	events.setEvery(cfg.SampleEvery)
+	return nil
+}
+
+// eventSampler counts the raw events per stream and lets one of every n
+// through.
+type eventSampler struct {
+	sync.Mutex
+	every  int
+	counts map[string]int
+}
+
+var events = &eventSampler{counts: make(map[string]int)}
+
+func (s *eventSampler) setEvery(n int) {
+	s.Lock()
+	defer s.Unlock()
+	s.every = n
+}
+
+func (s *eventSampler) sample(stream, symbol string) bool {
+	s.Lock()
+	defer s.Unlock()
+	if s.every <= 0 {
+		return false
+	}
+	key := stream + "." + symbol
+	s.counts[key]++
+	return s.counts[key]%s.every == 1 || s.every == 1
+}
+
+// LogRawEvent logs a sampled raw stream message at debug level.
+func LogRawEvent(logger *slog.Logger, stream, symbol string, message []byte) {
+	if !logger.Enabled(context.Background(), slog.LevelDebug) || !events.sample(stream, symbol) {
+		return
+	}
+	logger.Debug("raw event", "data", string(message))
+}
@@ -0,0 +1,169 @@
+// Package telemetry holds the Prometheus metrics, the feed health tracker,
+// the latency history and the logging setup of the collector.
+package telemetry
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"test.bhft.com/clock"
+)
+
+// Stream names used in metric labels, health reports and hub topics.
+const (
+	StreamBook         = "book"
+	StreamTrades       = "trades"
+	StreamKlines       = "klines"
+	StreamQuotes       = "quotes"
+	StreamMarkPrice    = "markPrice"
+	StreamLiquidations = "liquidations"
+	// StreamOpenInterest is polled over REST, it is connected while the
+	// polls succeed.
+	StreamOpenInterest = "openInterest"
+)
+
+// Prometheus metrics of the feeds and the storage.
+var (
+	messagesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bhft",
+		Name:      "messages_received_total",
+		Help:      "Websocket messages received per stream.",
+	}, []string{"stream", "symbol"})
+	lastMessage = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "bhft",
+		Name:      "last_message_timestamp_seconds",
+		Help:      "Unix time of the last websocket message per stream.",
+	}, []string{"stream", "symbol"})
+	DecodeErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bhft",
+		Name:      "decode_errors_total",
+		Help:      "Websocket messages that could not be decoded.",
+	}, []string{"stream", "symbol"})
+	reconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bhft",
+		Name:      "reconnects_total",
+		Help:      "Websocket connections opened again after the first one.",
+	}, []string{"stream", "symbol"})
+	eventLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "bhft",
+		Name:      "event_latency_seconds",
+		Help:      "Delay between the exchange event time and the local receive time.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"stream", "symbol"})
+	QueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "bhft",
+		Name:      "queue_depth",
+		Help:      "Events waiting in the channel between a stream reader and its consumer.",
+	}, []string{"stream", "symbol"})
+	Dropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bhft",
+		Name:      "dropped_total",
+		Help:      "Values a consumer missed because it fell behind the pipeline it follows.",
+	}, []string{"consumer", "symbol"})
+	BookSyncs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bhft",
+		Name:      "book_resyncs_total",
+		Help:      "Times the order book got back in sync after falling out of sync.",
+	}, []string{"symbol"})
+	SequenceGaps = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bhft",
+		Name:      "sequence_gaps_total",
+		Help:      "Depth diffs rejected because they do not follow the last applied update ID.",
+	}, []string{"symbol"})
+	ChecksumErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bhft",
+		Name:      "checksum_errors_total",
+		Help:      "Order book checksums sent by the venue that the local book did not match.",
+	}, []string{"symbol"})
+	dbInsertDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "bhft",
+		Name:      "db_insert_duration_seconds",
+		Help:      "Duration of the inserts per table.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"table"})
+	dbInsertErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bhft",
+		Name:      "db_insert_errors_total",
+		Help:      "Failed inserts per table.",
+	}, []string{"table"})
+	restWeightUsed = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "bhft",
+		Name:      "rest_weight_used",
+		Help:      "Binance request weight used in the current minute per API host, from X-MBX-USED-WEIGHT-1M.",
+	}, []string{"host"})
+	AlertsFired = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bhft",
+		Name:      "alerts_fired_total",
+		Help:      "Alerts raised per rule and symbol.",
+	}, []string{"rule", "symbol"})
+	AlertDeliveryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "bhft",
+		Name:      "alert_delivery_errors_total",
+		Help:      "Alerts a sink failed to deliver.",
+	}, []string{"sink"})
+)
+
+// ObserveMessage records a message received on a stream of venue.
+func ObserveMessage(venue, stream, symbol string) {
+	messagesReceived.WithLabelValues(stream, symbol).Inc()
+	lastMessage.WithLabelValues(stream, symbol).Set(float64(clock.Now().UnixMilli()) / 1000)
+	Health.Message(venue, stream, symbol)
+}
+
+// ObserveEvent records the latency of an event with the exchange eventTime
+// in milliseconds.
+func ObserveEvent(stream, symbol string, eventTime int64) {
+	Latencies.Observe(stream+"."+symbol, eventTime)
+	eventLatency.WithLabelValues(stream, symbol).Observe(float64(clock.Now().UnixMilli()-eventTime) / 1000)
+}
+
+// ObserveInsert records an insert of symbol into table that started at
+// start.
+func ObserveInsert(table, symbol string, start time.Time, err error) {
+	dbInsertDuration.WithLabelValues(table).Observe(time.Since(start).Seconds())
+	Health.Flush(table, symbol, err)
+	if err != nil {
+		dbInsertErrors.WithLabelValues(table).Inc()
+	}
+}
+
+var (
+	dialedMu sync.Mutex
+	dialed   = make(map[string]bool)
+)
+
+// ObserveDial counts a reconnect when the stream was opened before.
+func ObserveDial(stream, symbol string) {
+	key := stream + "." + symbol
+	dialedMu.Lock()
+	defer dialedMu.Unlock()
+	if dialed[key] {
+		reconnects.WithLabelValues(stream, symbol).Inc()
+	}
+	dialed[key] = true
+}
+
+// WeightTransport records the request weight Binance reports on every
+// response. Spot and futures count their weight apart, so it is kept per
+// host.
+type WeightTransport struct {
+	Next http.RoundTripper
+}
+
+func (t *WeightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.Next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if w := resp.Header.Get("X-Mbx-Used-Weight-1m"); w != "" {
+		if v, err := strconv.ParseFloat(w, 64); err == nil {
+			restWeightUsed.WithLabelValues(req.URL.Host).Set(v)
+		}
+	}
+	return resp, nil
+}
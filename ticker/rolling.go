@@ -0,0 +1,125 @@
+package ticker
+
+import (
+	"strconv"
+	"time"
+
+	"test.bhft.com/trades"
+)
+
+type point struct {
+	id    int64
+	time  int64
+	price float64
+	qty   float64
+}
+
+// Rolling computes the stats of the trades of the last Window. Trades are
+// added in time order; high and low are kept in monotonic queues, so
+// adding and evicting a trade is amortized constant time.
+type Rolling struct {
+	Symbol string
+	Window time.Duration
+	// Since is the time of the first trade added. Before Since+Window the
+	// stats only cover part of the window.
+	Since       int64
+	points      []point
+	maxq, minq  []point
+	volume      float64
+	quoteVolume float64
+}
+
+func NewRolling(symbol string, window time.Duration) *Rolling {
+	return &Rolling{Symbol: symbol, Window: window}
+}
+
+// Add adds a trade to the window.
+func (r *Rolling) Add(t trades.Trade) {
+	price, err := strconv.ParseFloat(t.Price, 64)
+	if err != nil {
+		return
+	}
+	qty, err := strconv.ParseFloat(t.Quantity, 64)
+	if err != nil {
+		return
+	}
+	p := point{id: t.ID, time: t.Time, price: price, qty: qty}
+	if r.Since == 0 {
+		r.Since = p.time
+	}
+	r.points = append(r.points, p)
+	r.volume += qty
+	r.quoteVolume += price * qty
+	for len(r.maxq) > 0 && r.maxq[len(r.maxq)-1].price <= price {
+		r.maxq = r.maxq[:len(r.maxq)-1]
+	}
+	r.maxq = append(r.maxq, p)
+	for len(r.minq) > 0 && r.minq[len(r.minq)-1].price >= price {
+		r.minq = r.minq[:len(r.minq)-1]
+	}
+	r.minq = append(r.minq, p)
+}
+
+// evict drops the trades made before cutoff.
+func (r *Rolling) evict(cutoff int64) {
+	n := 0
+	for n < len(r.points) && r.points[n].time < cutoff {
+		p := r.points[n]
+		r.volume -= p.qty
+		r.quoteVolume -= p.price * p.qty
+		if len(r.maxq) > 0 && r.maxq[0].id == p.id {
+			r.maxq = r.maxq[1:]
+		}
+		if len(r.minq) > 0 && r.minq[0].id == p.id {
+			r.minq = r.minq[1:]
+		}
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	r.points = append(r.points[:0], r.points[n:]...)
+	if len(r.points) == 0 {
+		// Start the sums afresh so float errors do not add up.
+		r.volume, r.quoteVolume = 0, 0
+	}
+}
+
+// Full reports whether trades were added for a whole window up to now, in
+// milliseconds.
+func (r *Rolling) Full(now int64) bool {
+	return r.Since != 0 && now-r.Since >= r.Window.Milliseconds()
+}
+
+// Stats drops the trades that left the window ending at now, in
+// milliseconds, and returns the stats of the others.
+func (r *Rolling) Stats(now int64) Stats {
+	openTime := now - r.Window.Milliseconds()
+	r.evict(openTime)
+	s := Stats{
+		Symbol:    r.Symbol,
+		Source:    SourceLocal,
+		Window:    WindowName(r.Window),
+		OpenTime:  openTime,
+		CloseTime: now,
+		Count:     int64(len(r.points)),
+	}
+	if len(r.points) == 0 {
+		return s
+	}
+	first, last := r.points[0], r.points[len(r.points)-1]
+	s.Open = first.price
+	s.Last = last.price
+	s.High = r.maxq[0].price
+	s.Low = r.minq[0].price
+	s.PriceChange = s.Last - s.Open
+	s.PriceChangePercent = s.PriceChange / s.Open * 100
+	s.Volume = r.volume
+	s.QuoteVolume = r.quoteVolume
+	if r.volume > 0 {
+		s.VWAP = r.quoteVolume / r.volume
+	}
+	s.FirstID = first.id
+	s.LastID = last.id
+	return s
+}
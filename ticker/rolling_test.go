@@ -0,0 +1,73 @@
+package ticker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"test.bhft.com/trades"
+)
+
+// TestRolling adds trades and reads the stats as the window slides over
+// them, checking the high and low queues keep the extremes of the trades
+// left after each eviction.
+func TestRolling(t *testing.T) {
+	const base = int64(1_700_000_000_000)
+	r := NewRolling("BTCUSDT", 10*time.Second)
+	steps := []struct {
+		name   string
+		add    [][2]float64 // offset in ms and price, for 1 of quantity
+		now    int64
+		count  int64
+		high   float64
+		low    float64
+		volume float64
+		full   bool
+	}{
+		{
+			name:  "an equal price replaces the older high",
+			add:   [][2]float64{{0, 100}, {1000, 105}, {2000, 103}, {3000, 105}, {4000, 99}},
+			now:   9000,
+			count: 5, high: 105, low: 99, volume: 5,
+		},
+		{name: "the first trade is on the window edge", now: 10_000, count: 5, high: 105, low: 99, volume: 5, full: true},
+		{name: "the oldest trade leaves", now: 10_500, count: 4, high: 105, low: 99, volume: 4, full: true},
+		{name: "the high is kept by the later equal price", now: 11_500, count: 3, high: 105, low: 99, volume: 3, full: true},
+		{name: "the high leaves", now: 13_500, count: 1, high: 99, low: 99, volume: 1, full: true},
+		{name: "a rising low", add: [][2]float64{{13_600, 101}, {13_700, 102}}, now: 13_800, count: 3, high: 102, low: 99, volume: 3, full: true},
+		{name: "the low leaves", now: 14_500, count: 2, high: 102, low: 101, volume: 2, full: true},
+		{name: "every trade leaves", now: 30_000, full: true},
+		{name: "a fresh trade", add: [][2]float64{{30_000, 50}}, now: 30_000, count: 1, high: 50, low: 50, volume: 1, full: true},
+	}
+	var id int64
+	for _, s := range steps {
+		for _, a := range s.add {
+			id++
+			r.Add(trades.Trade{ID: id, Time: base + int64(a[0]), Price: fmt.Sprint(a[1]), Quantity: "1"})
+		}
+		got := r.Stats(base + s.now)
+		if got.Count != s.count || got.High != s.high || got.Low != s.low || got.Volume != s.volume {
+			t.Errorf("%s: count %d high %v low %v volume %v, want %d %v %v %v", s.name, got.Count, got.High, got.Low, got.Volume, s.count, s.high, s.low, s.volume)
+		}
+		if r.Full(base+s.now) != s.full {
+			t.Errorf("%s: full %v, want %v", s.name, !s.full, s.full)
+		}
+		if got.OpenTime != base+s.now-10_000 || got.CloseTime != base+s.now || got.Window != "10s" {
+			t.Errorf("%s: window %s from %d to %d", s.name, got.Window, got.OpenTime, got.CloseTime)
+		}
+	}
+}
+
+func TestRollingStats(t *testing.T) {
+	r := NewRolling("BTCUSDT", time.Minute)
+	r.Add(trades.Trade{ID: 7, Time: 1000, Price: "100", Quantity: "1"})
+	r.Add(trades.Trade{ID: 8, Time: 2000, Price: "bad", Quantity: "1"})
+	r.Add(trades.Trade{ID: 9, Time: 3000, Price: "110", Quantity: "3"})
+	s := r.Stats(4000)
+	if s.Open != 100 || s.Last != 110 || s.PriceChange != 10 || s.PriceChangePercent != 10 {
+		t.Errorf("prices %+v", s)
+	}
+	if s.QuoteVolume != 430 || s.VWAP != 107.5 || s.FirstID != 7 || s.LastID != 9 || s.Source != SourceLocal {
+		t.Errorf("volumes and ids %+v", s)
+	}
+}
@@ -0,0 +1,112 @@
+// Package ticker holds rolling window statistics of a symbol, as sent by a
+// venue ticker stream or computed locally from trades, so both can be
+// compared.
+package ticker
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"test.bhft.com/feed"
+)
+
+// SourceLocal is the source of the stats computed from the trade feed.
+const SourceLocal = "local"
+
+// Stats are the statistics of the trades of a symbol in a rolling window
+// ending at CloseTime. Source is the venue stream they come from, like
+// ticker or ticker_1h, or SourceLocal. Window is written like the Binance
+// windows: 1h, 4h, 1d. Fields a source does not send are 0, times are in
+// milliseconds.
+type Stats struct {
+	Symbol             string  `json:"symbol"`
+	Source             string  `json:"source"`
+	Window             string  `json:"window"`
+	OpenTime           int64   `json:"openTime"`
+	CloseTime          int64   `json:"closeTime"`
+	Open               float64 `json:"open"`
+	High               float64 `json:"high"`
+	Low                float64 `json:"low"`
+	Last               float64 `json:"last"`
+	PriceChange        float64 `json:"priceChange"`
+	PriceChangePercent float64 `json:"priceChangePercent"`
+	Volume             float64 `json:"volume"`
+	QuoteVolume        float64 `json:"quoteVolume"`
+	VWAP               float64 `json:"vwap"`
+	Count              int64   `json:"count"`
+	FirstID            int64   `json:"firstId"`
+	LastID             int64   `json:"lastId"`
+}
+
+// WindowName writes d the way Binance names ticker windows, like 15m, 4h
+// or 1d.
+func WindowName(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return d.String()
+	}
+}
+
+// Board holds the latest stats of a symbol per source and window.
+type Board struct {
+	sync.Mutex
+	Symbol string
+	stats  map[string]Stats
+	feed   feed.Feed[Stats]
+}
+
+func NewBoard(symbol string) *Board {
+	return &Board{Symbol: symbol, stats: make(map[string]Stats)}
+}
+
+func key(source, window string) string {
+	return source + "/" + window
+}
+
+// Update replaces the stats of their source and window and publishes them
+// to the subscribers.
+func (b *Board) Update(s Stats) {
+	b.Lock()
+	defer b.Unlock()
+	b.stats[key(s.Source, s.Window)] = s
+	b.feed.Publish(s)
+}
+
+// Get returns the stats of source and window, ok is false when there are
+// none yet.
+func (b *Board) Get(source, window string) (Stats, bool) {
+	b.Lock()
+	defer b.Unlock()
+	s, ok := b.stats[key(source, window)]
+	return s, ok
+}
+
+// All returns every stats held, sorted by window and source.
+func (b *Board) All() []Stats {
+	b.Lock()
+	defer b.Unlock()
+	res := make([]Stats, 0, len(b.stats))
+	for _, s := range b.stats {
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Window != res[j].Window {
+			return res[i].Window < res[j].Window
+		}
+		return res[i].Source < res[j].Source
+	})
+	return res
+}
+
+// Subscribe streams every stats update received after the call.
+func (b *Board) Subscribe(buf int) (<-chan Stats, func()) {
+	return b.feed.Subscribe(buf)
+}
@@ -0,0 +1,105 @@
+// Package trades keeps the recent trades of a symbol and streams new ones.
+package trades
+
+import (
+	"sync"
+
+	"test.bhft.com/feed"
+)
+
+// Trade is one public trade. IsBuyerMaker is set when the buyer was the
+// resting side, so the trade was sell initiated.
+type Trade struct {
+	ID            int64  `json:"id"`
+	Price         string `json:"price"`
+	Quantity      string `json:"qty"`
+	QuoteQuantity string `json:"quoteQty"`
+	Time          int64  `json:"time"`
+	IsBuyerMaker  bool   `json:"isBuyerMaker"`
+	IsBestMatch   bool   `json:"isBestMatch"`
+}
+
+// List holds the latest trades of a symbol.
+type List struct {
+	sync.Mutex
+	Symbol string  `json:"symbol"`
+	Trades []Trade `json:"trades"`
+	feed   feed.Feed[Trade]
+}
+
+func New(symbol string, trades []Trade) *List {
+	return &List{Symbol: symbol, Trades: trades}
+}
+
+func (t *List) Len() int {
+	return len(t.Trades)
+}
+
+// FirstID returns the ID of the oldest trade kept, 0 when there is none.
+func (t *List) FirstID() int64 {
+	t.Mutex.Lock()
+	defer t.Mutex.Unlock()
+	if len(t.Trades) == 0 {
+		return 0
+	}
+	return t.Trades[0].ID
+}
+
+func (t *List) Pop() Trade {
+	t.Mutex.Lock()
+	defer t.Mutex.Unlock()
+	if len(t.Trades) == 0 {
+		return Trade{}
+	}
+
+	trade := t.Trades[0]
+	t.Trades = t.Trades[1:]
+	return trade
+}
+
+func (t *List) Push(trade Trade) {
+	t.Mutex.Lock()
+	defer t.Mutex.Unlock()
+	t.Trades = append(t.Trades, trade)
+}
+
+// Update appends a trade, keeping the latest 100, and publishes it to the
+// subscribers.
+func (t *List) Update(trade Trade) {
+	t.Mutex.Lock()
+	defer t.Mutex.Unlock()
+	if len(t.Trades) >= 100 {
+		t.Trades = t.Trades[1:]
+	}
+	t.Trades = append(t.Trades, trade)
+	t.feed.Publish(trade)
+}
+
+// Page returns up to limit trades. With fromID 0 they are the latest trades,
+// otherwise the trades starting at fromID.
+func (t *List) Page(fromID int64, limit int) []Trade {
+	t.Mutex.Lock()
+	defer t.Mutex.Unlock()
+	res := make([]Trade, 0, limit)
+	if fromID == 0 {
+		start := max(len(t.Trades)-limit, 0)
+		return append(res, t.Trades[start:]...)
+	}
+	for _, trade := range t.Trades {
+		if trade.ID >= fromID && len(res) < limit {
+			res = append(res, trade)
+		}
+	}
+	return res
+}
+
+// Subscribe streams every trade received after the call.
+func (t *List) Subscribe(buf int) (<-chan Trade, func()) {
+	return t.feed.Subscribe(buf)
+}
+
+// Dropped returns the trades the subscriber reading ch missed because its
+// buffer was full.
+func (t *List) Dropped(ch <-chan Trade) int64 {
+	return t.feed.Dropped(ch)
+}